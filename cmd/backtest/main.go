@@ -0,0 +1,161 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/spf13/viper"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/backtest"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/paper"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/strategy"
+)
+
+type Config struct {
+	DB struct {
+		Host     string
+		User     string
+		Password string
+		Name     string
+	}
+	Paper paper.Config
+}
+
+func main() {
+	strategyName := flag.String("strategy", "sma_cross", "strategy to run ("+strings.Join(strategy.Names(), ", ")+")")
+	symbol := flag.String("symbol", "BTCUSDT", "symbol the strategy trades")
+	params := flag.String("params", "", "strategy parameters, e.g. fast=10,slow=30,quantity=0.01")
+	fromFlag := flag.String("from", "", "start of the range (RFC3339), defaults to 24 hours ago")
+	toFlag := flag.String("to", "", "end of the range (RFC3339), defaults to now")
+	file := flag.String("file", "", "read newline-delimited JSON ticks from a file instead of ticker_data")
+	quote := flag.String("quote", "USDT", "asset equity is measured in")
+	sample := flag.Duration("sample", time.Hour, "equity sample interval for the Sharpe ratio, at least 1ms")
+	format := flag.String("format", "json", "output format: json (full report) or csv (trade list)")
+	out := flag.String("out", "", "output file, defaults to stdout")
+	flag.Parse()
+
+	var config Config
+	if err := loadConfig(&config); err != nil {
+		log.Fatalf("Error loading configuration: %v", err)
+	}
+
+	to := time.Now()
+	from := to.Add(-24 * time.Hour)
+	var err error
+	if *fromFlag != "" {
+		if from, err = time.Parse(time.RFC3339, *fromFlag); err != nil {
+			log.Fatalf("Invalid -from: %v", err)
+		}
+	}
+	if *toFlag != "" {
+		if to, err = time.Parse(time.RFC3339, *toFlag); err != nil {
+			log.Fatalf("Invalid -to: %v", err)
+		}
+	}
+
+	strategyParams, err := parseParams(*params)
+	if err != nil {
+		log.Fatalf("Invalid -params: %v", err)
+	}
+
+	ticks, err := loadTicks(config, *file, *symbol, from.UnixMilli(), to.UnixMilli())
+	if err != nil {
+		log.Fatalf("Error loading ticks: %v", err)
+	}
+	log.Printf("Backtesting %s on %d %s ticks from %s to %s", *strategyName, len(ticks), *symbol, from.Format(time.RFC3339), to.Format(time.RFC3339))
+
+	report, err := backtest.Run(backtest.Config{
+		Paper:          config.Paper,
+		Strategy:       strategy.Config{Name: *strategyName, Symbol: *symbol, Params: strategyParams},
+		Quote:          *quote,
+		SampleInterval: *sample,
+	}, ticks)
+	if err != nil {
+		log.Fatalf("Error running backtest: %v", err)
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			log.Fatalf("Error creating output file: %v", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	switch *format {
+	case "json":
+		err = report.WriteJSON(w)
+	case "csv":
+		err = report.WriteCSV(w)
+	default:
+		err = fmt.Errorf("unknown format %q", *format)
+	}
+	if err != nil {
+		log.Fatalf("Error writing report: %v", err)
+	}
+
+	m := report.Metrics
+	log.Printf("Return %.4f%%, Sharpe %.2f, max drawdown %.4f%%, %d trades, fees %.4f",
+		m.TotalReturn*100, m.SharpeRatio, m.MaxDrawdown*100, m.Trades, m.Fees)
+}
+
+func loadTicks(config Config, file, symbol string, from, to int64) ([]models.FormattedData, error) {
+	if file != "" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return backtest.ReadTicks(f, []string{symbol}, from, to)
+	}
+
+	connStr := fmt.Sprintf("postgres://%s:%s@%s/%s?sslmode=disable", config.DB.User, config.DB.Password, config.DB.Host, config.DB.Name)
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	return backtest.LoadTicks(db, []string{symbol}, from, to)
+}
+
+func parseParams(raw string) (map[string]float64, error) {
+	params := make(map[string]float64)
+	if raw == "" {
+		return params, nil
+	}
+	for _, pair := range strings.Split(raw, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("expected key=value, got %q", pair)
+		}
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", key, err)
+		}
+		params[strings.TrimSpace(key)] = v
+	}
+	return params, nil
+}
+
+func loadConfig(config *Config) error {
+	viper.SetConfigName("config")
+	viper.SetConfigType("yaml")
+	viper.AddConfigPath("configs")
+
+	if err := viper.ReadInConfig(); err != nil {
+		return err
+	}
+
+	return viper.Unmarshal(&config)
+}
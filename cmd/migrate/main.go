@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/spf13/viper"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/migrations"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/partition"
+)
+
+type Config struct {
+	DB struct {
+		Host     string
+		User     string
+		Password string
+		Name     string
+	}
+	Partitions partition.Config
+}
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "print the SQL that would run without changing the database")
+	steps := flag.Int("steps", 1, "number of migrations to revert with down")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] up|down|status|partition\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var config Config
+	if err := loadConfig(&config); err != nil {
+		log.Fatalf("Error loading configuration: %v", err)
+	}
+
+	connStr := fmt.Sprintf("postgres://%s:%s@%s/%s?sslmode=disable", config.DB.User, config.DB.Password, config.DB.Host, config.DB.Name)
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		log.Fatalf("Error connecting to the database: %v", err)
+	}
+	defer db.Close()
+
+	migrationList, err := migrations.Embedded()
+	if err != nil {
+		log.Fatalf("Error loading migrations: %v", err)
+	}
+	migrator := migrations.NewMigrator(db, migrationList)
+	if *dryRun {
+		migrator.SetDryRun(os.Stdout)
+	}
+
+	ctx := context.Background()
+	switch flag.Arg(0) {
+	case "up":
+		count, err := migrator.Up(ctx)
+		if err != nil {
+			log.Fatalf("Error migrating database: %v", err)
+		}
+		log.Printf("Applied %d migrations", count)
+	case "down":
+		count, err := migrator.Down(ctx, *steps)
+		if err != nil {
+			log.Fatalf("Error reverting migrations: %v", err)
+		}
+		log.Printf("Reverted %d migrations", count)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatalf("Error reading migration status: %v", err)
+		}
+		for _, status := range statuses {
+			applied := "pending"
+			if status.Applied {
+				applied = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%s\t%s\n", status.Version, status.Name, applied)
+		}
+	case "partition":
+		// Converting rewrites ticker_data, so it is never part of up
+		if *dryRun {
+			log.Fatalf("partition does not support -dry-run")
+		}
+		if !config.Partitions.Enabled {
+			log.Fatalf("partitions.enabled is false in configs/config.yaml")
+		}
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatalf("Error reading migration status: %v", err)
+		}
+		for _, status := range statuses {
+			if !status.Applied {
+				log.Fatalf("Migration %04d_%s is pending, run up first", status.Version, status.Name)
+			}
+		}
+		manager, err := partition.NewManager(db, config.Partitions)
+		if err != nil {
+			log.Fatalf("Error creating partition manager: %v", err)
+		}
+		if err := manager.Convert(ctx, time.Now()); err != nil {
+			log.Fatalf("Error partitioning ticker_data: %v", err)
+		}
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+func loadConfig(config *Config) error {
+	viper.SetConfigName("config")
+	viper.SetConfigType("yaml")
+	viper.AddConfigPath("configs")
+
+	if err := viper.ReadInConfig(); err != nil {
+		return err
+	}
+
+	return viper.Unmarshal(&config)
+}
@@ -1,9 +1,12 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"flag"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"sync"
@@ -11,9 +14,28 @@ import (
 	"time"
 
 	_ "github.com/lib/pq"
+	"github.com/nats-io/nats.go"
+	"github.com/redis/go-redis/v9"
 	"github.com/spf13/viper"
 
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/alerts"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/anomaly"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/api"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/arbitrage"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/broadcast"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/correlation"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/indicators"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/latest"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/migrations"
 	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/monitor"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/paper"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/partition"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/portfolio"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/processor"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/recorder"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/rollup"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/rpc"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/strategy"
 )
 
 type Config struct {
@@ -22,11 +44,38 @@ type Config struct {
 		User     string
 		Password string
 		Name     string
+		// Migrate applies pending schema migrations at startup
+		Migrate bool
 	}
-	Symbols []string
+	Storage     processor.StorageConfig
+	Partitions  partition.Config
+	Rollups     rollup.Config
+	Symbols     []string
+	StreamURL   string `mapstructure:"stream_url"`
+	NATS        processor.NATSConfig
+	Redis       processor.RedisConfig
+	Influx      processor.InfluxConfig
+	Graphite    processor.GraphiteConfig
+	Candles     processor.CandleConfig
+	Indicators  indicators.Config
+	Alerts      alerts.Config
+	Anomalies   anomaly.Config
+	Arbitrage   arbitrage.Config
+	Correlation correlation.Config
+	Portfolio   portfolio.Config
+	Paper       paper.Config
+	Strategies  []strategy.Config
+	Recorder    recorder.Config
+	HTTP        api.Config
+	Broadcast   broadcast.Config
+	GRPC        rpc.Config
 }
 
 func main() {
+	replayDir := flag.String("replay", "", "replay frames recorded in this directory instead of connecting to Binance")
+	replaySpeed := flag.Float64("speed", 1, "replay speed: 1 for original timing, >1 to accelerate, 0 for as fast as possible")
+	flag.Parse()
+
 	log.Println("Starting RealTimeCryptoMonitor...")
 
 	// Load configuration
@@ -38,30 +87,356 @@ func main() {
 	// Print loaded configuration
 	fmt.Printf("DB_HOST: %s, DB_USER: %s, DB_PASSWORD: %s, DB_NAME: %s\n", config.DB.Host, config.DB.User, config.DB.Password, config.DB.Name)
 
-	// Database connection setup
-	connStr := fmt.Sprintf("postgres://%s:%s@%s/%s?sslmode=disable", config.DB.User, config.DB.Password, config.DB.Host, config.DB.Name)
+	if config.StreamURL != "" {
+		monitor.StreamURL = config.StreamURL
+	}
+	// Postgres holds the candles, analytics and other tables next to the
+	// ticks, so it is used whenever db is configured; backends storing ticks
+	// in it need it
 	var db *sql.DB
 	var err error
+	if config.DB.Host != "" || config.Storage.SharesDB() {
+		db = connectDB(config)
+		defer func() {
+			if err := db.Close(); err != nil {
+				log.Printf("Error closing the database: %v", err)
+			}
+		}()
+	} else {
+		log.Printf("Storing ticks in %s without a db; migrations, history and persisted analytics are disabled", config.Storage.Backend)
+	}
 
-	// Retry connecting to the database until successful
-	for {
-		db, err = sql.Open("postgres", connStr)
+	// One store for every symbol, closed once they have all stopped. A
+	// TimescaleDB hypertable sharing db is created before the migrations, so
+	// that they leave its ticker_data alone.
+	tickStore, err := processor.NewTickStore(config.Storage, db)
+	if err != nil {
+		log.Fatalf("Error creating tick store: %v", err)
+	}
+	defer func() {
+		if err := tickStore.Close(); err != nil {
+			log.Printf("Error closing tick store: %v", err)
+		}
+	}()
+
+	if config.DB.Migrate && db != nil {
+		migrationList, err := migrations.Embedded()
 		if err != nil {
-			log.Printf("Error connecting to the database: %v", err)
-		} else if err = db.Ping(); err == nil {
-			break
+			log.Fatalf("Error loading migrations: %v", err)
+		}
+		count, err := migrations.NewMigrator(db, migrationList).Up(context.Background())
+		if err != nil {
+			log.Fatalf("Error migrating database: %v", err)
+		}
+		log.Printf("Applied %d database migrations", count)
+	}
+
+	// Rollups read and partitions split the ticker_data in db
+	var rollupScheduler *rollup.Scheduler
+	if config.Rollups.Enabled && db != nil && config.Storage.SharesDB() {
+		rollupScheduler = rollup.NewScheduler(db, config.Rollups)
+	}
+
+	// A partitioned ticker_data always needs partitions for new ticks, or they
+	// pile up in the default partition; partitions.enabled only asks for one.
+	// A TimescaleDB hypertable manages its own chunks.
+	var partitioned bool
+	if db != nil && (config.Storage.Backend == "" || config.Storage.Backend == processor.BackendPostgres) {
+		partitioned, err = partition.IsPartitioned(context.Background(), db)
+		if err != nil {
+			log.Printf("Error checking ticker_data partitioning: %v", err)
+		} else if config.Partitions.Enabled && !partitioned {
+			log.Printf("ticker_data is not partitioned yet; stop the monitor and run `go run cmd/migrate/main.go partition` to partition it")
+		}
+	}
+
+	var partitionManager *partition.Manager
+	if partitioned {
+		partitionManager, err = partition.NewManager(db, config.Partitions)
+		if err != nil {
+			log.Fatalf("Error creating partition manager: %v", err)
+		}
+		if rollupScheduler != nil {
+			// Keep raw ticks until they have been rolled up
+			partitionManager.SetRetentionGuard(rollupScheduler.RawWatermark)
+		}
+		if err := partitionManager.Maintain(context.Background(), time.Now()); err != nil {
+			log.Printf("Error maintaining ticker_data partitions: %v", err)
+		}
+	}
+
+	// Processors shared by every monitored symbol
+	var processors []processor.DataProcessor
+	apiServer := api.NewServer(config.HTTP)
+	if db != nil {
+		apiServer.RegisterHistory(db, config.HTTP.History)
+	}
+
+	latestStore := latest.NewStore()
+	apiServer.RegisterLatest(latestStore)
+	processors = append(processors, latestStore)
+
+	var broadcastHub *broadcast.Hub
+	if config.Broadcast.Enabled {
+		broadcastHub = broadcast.NewHub(config.Broadcast, latestStore)
+		apiServer.RegisterBroadcast(broadcastHub)
+		processors = append(processors, broadcastHub)
+	}
+
+	var grpcServer *rpc.Server
+	if config.GRPC.Enabled {
+		grpcServer = rpc.NewServer(config.GRPC, latestStore, db, config.HTTP.History)
+		processors = append(processors, grpcServer)
+	}
+
+	if config.NATS.URL != "" {
+		natsConn, err := nats.Connect(config.NATS.URL)
+		if err != nil {
+			log.Fatalf("Error connecting to NATS: %v", err)
+		}
+		natsPublisher, err := processor.NewNATSPublisher(natsConn, config.NATS)
+		if err != nil {
+			log.Fatalf("Error creating NATS publisher: %v", err)
+		}
+		defer func() {
+			if err := natsPublisher.Close(); err != nil {
+				log.Printf("Error closing NATS publisher: %v", err)
+			}
+		}()
+		processors = append(processors, natsPublisher)
+	}
+
+	if config.Redis.Addr != "" {
+		redisClient := redis.NewClient(&redis.Options{
+			Addr:     config.Redis.Addr,
+			Password: config.Redis.Password,
+			DB:       config.Redis.DB,
+		})
+		redisCache, err := processor.NewRedisCache(redisClient, config.Redis)
+		if err != nil {
+			log.Fatalf("Error creating Redis cache: %v", err)
+		}
+		defer func() {
+			if err := redisCache.Close(); err != nil {
+				log.Printf("Error closing Redis cache: %v", err)
+			}
+		}()
+		processors = append(processors, redisCache)
+	}
+
+	if config.Influx.URL != "" || config.Influx.UDPAddr != "" {
+		influxWriter, err := processor.NewInfluxWriter(&http.Client{Timeout: 10 * time.Second}, config.Influx)
+		if err != nil {
+			log.Fatalf("Error creating InfluxDB writer: %v", err)
+		}
+		defer func() {
+			if err := influxWriter.Close(); err != nil {
+				log.Printf("Error closing InfluxDB writer: %v", err)
+			}
+		}()
+		processors = append(processors, influxWriter)
+	}
+
+	if config.Graphite.Addr != "" {
+		graphiteWriter, err := processor.NewGraphiteWriter(config.Graphite)
+		if err != nil {
+			log.Fatalf("Error creating Graphite writer: %v", err)
+		}
+		defer func() {
+			if err := graphiteWriter.Close(); err != nil {
+				log.Printf("Error closing Graphite writer: %v", err)
+			}
+		}()
+		processors = append(processors, graphiteWriter)
+	}
+
+	var candleAggregator *processor.CandleAggregator
+	if config.Candles.Enabled {
+		candleAggregator, err = processor.NewCandleAggregator(config.Candles.Intervals, config.Candles.AllowedLateness)
+		if err != nil {
+			log.Fatalf("Error creating candle aggregator: %v", err)
+		}
+		if db != nil {
+			candleWriter, err := processor.NewCandleWriter(db)
+			if err != nil {
+				log.Fatalf("Error creating candle writer: %v", err)
+			}
+			candleAggregator.AddProcessor(candleWriter)
+		}
+		// Emit the candles still open once the symbols have stopped
+		defer func() {
+			if err := candleAggregator.Close(); err != nil {
+				log.Printf("Error closing candle aggregator: %v", err)
+			}
+		}()
+		processors = append(processors, candleAggregator)
+
+		if config.Indicators.Enabled {
+			indicatorEngine := indicators.NewEngine(config.Indicators)
+			if config.Indicators.Persist && db != nil {
+				indicatorWriter, err := processor.NewIndicatorWriter(db)
+				if err != nil {
+					log.Fatalf("Error creating indicator writer: %v", err)
+				}
+				indicatorEngine.AddProcessor(indicatorWriter)
+			}
+			candleAggregator.AddProcessor(indicatorEngine)
+			apiServer.RegisterIndicators(indicatorEngine)
+		}
+	}
+
+	if config.Alerts.Enabled {
+		// Validate before NewEngine starts its dispatch goroutine
+		if err := config.Alerts.Validate(); err != nil {
+			log.Fatalf("Error in alert configuration: %v", err)
+		}
+		alertEngine, err := alerts.NewEngine(config.Alerts.Rules)
+		if err != nil {
+			log.Fatalf("Error creating alert engine: %v", err)
+		}
+		if len(config.Alerts.Notifiers) == 0 {
+			alertEngine.AddNotifier("log", alerts.LogNotifier{})
+		}
+		for _, notifierConfig := range config.Alerts.Notifiers {
+			notifier, err := alerts.NewNotifier(notifierConfig)
+			if err != nil {
+				log.Fatalf("Error creating alert notifier: %v", err)
+			}
+			alertEngine.AddNotifier(notifierConfig.Name, notifier)
+		}
+		defer func() {
+			if err := alertEngine.Close(); err != nil {
+				log.Printf("Error closing alert engine: %v", err)
+			}
+		}()
+		processors = append(processors, alertEngine)
+	}
+
+	if config.Anomalies.Enabled {
+		anomalyDetector := anomaly.NewDetector(config.Anomalies)
+		if config.Anomalies.Persist && db != nil {
+			anomalyWriter, err := processor.NewAnomalyWriter(db)
+			if err != nil {
+				log.Fatalf("Error creating anomaly writer: %v", err)
+			}
+			anomalyDetector.AddProcessor(anomalyWriter)
+		}
+		processors = append(processors, anomalyDetector)
+	}
+
+	if config.Arbitrage.Enabled {
+		arbitrageDetector := arbitrage.NewDetector(config.Arbitrage)
+		if config.Arbitrage.Persist && db != nil {
+			arbitrageWriter, err := processor.NewArbitrageWriter(db)
+			if err != nil {
+				log.Fatalf("Error creating arbitrage writer: %v", err)
+			}
+			arbitrageDetector.AddProcessor(arbitrageWriter)
+		}
+		processors = append(processors, arbitrageDetector)
+	}
+
+	var correlationTracker *correlation.Tracker
+	if config.Correlation.Enabled {
+		correlationTracker, err = correlation.NewTracker(config.Correlation)
+		if err != nil {
+			log.Fatalf("Error creating correlation tracker: %v", err)
+		}
+		if config.Correlation.Persist && db != nil {
+			correlationWriter, err := processor.NewCorrelationWriter(db)
+			if err != nil {
+				log.Fatalf("Error creating correlation writer: %v", err)
+			}
+			correlationTracker.AddProcessor(correlationWriter)
+		}
+		apiServer.RegisterCorrelations(correlationTracker)
+		processors = append(processors, correlationTracker)
+	}
+
+	if config.Portfolio.Enabled {
+		valuer := portfolio.NewValuer(config.Portfolio)
+		if config.Portfolio.Persist && db != nil {
+			portfolioWriter, err := processor.NewPortfolioWriter(db)
+			if err != nil {
+				log.Fatalf("Error creating portfolio writer: %v", err)
+			}
+			valuer.AddProcessor(portfolioWriter)
+		}
+		apiServer.RegisterPortfolio(valuer)
+		processors = append(processors, valuer)
+	}
+
+	if config.Paper.Enabled {
+		paperExchange := paper.NewExchange(config.Paper)
+		if db != nil {
+			filters, err := paper.LoadFilters(db)
+			if err != nil {
+				log.Printf("Error loading exchange filters, paper orders will not be checked: %v", err)
+			} else {
+				paperExchange.SetFilters(filters)
+			}
+		}
+		if config.Paper.Persist && db != nil {
+			paperWriter, err := processor.NewPaperTradeWriter(db, time.Now().UTC().Format(time.RFC3339))
+			if err != nil {
+				log.Fatalf("Error creating paper trade writer: %v", err)
+			}
+			paperExchange.AddProcessor(paperWriter)
+		}
+		apiServer.RegisterPaperTrading(paperExchange)
+		processors = append(processors, paperExchange)
+
+		// Strategies run after the exchange so they see fills for the current tick
+		for _, strategyConfig := range config.Strategies {
+			strat, err := strategy.New(strategyConfig, paperExchange)
+			if err != nil {
+				log.Fatalf("Error creating strategy: %v", err)
+			}
+			processors = append(processors, strategy.NewRunner(strat))
 		}
-		log.Println("Waiting for the database to be ready...")
-		time.Sleep(2 * time.Second)
+	}
+
+	if err := apiServer.Start(); err != nil {
+		log.Fatalf("Error starting HTTP API: %v", err)
 	}
 	defer func() {
-		if err := db.Close(); err != nil {
-			log.Printf("Error closing the database: %v", err)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := apiServer.Shutdown(ctx); err != nil {
+			log.Printf("Error shutting down HTTP API: %v", err)
 		}
 	}()
 
+	if grpcServer != nil {
+		if err := grpcServer.Start(); err != nil {
+			log.Fatalf("Error starting gRPC API: %v", err)
+		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := grpcServer.Shutdown(ctx); err != nil {
+				log.Printf("Error shutting down gRPC API: %v", err)
+			}
+		}()
+	}
+
+	var frameRecorder *recorder.Recorder
+	if config.Recorder.Enabled && *replayDir == "" {
+		frameRecorder, err = recorder.NewRecorder(config.Recorder)
+		if err != nil {
+			log.Fatalf("Error creating frame recorder: %v", err)
+		}
+		defer func() {
+			if err := frameRecorder.Close(); err != nil {
+				log.Printf("Error closing frame recorder: %v", err)
+			}
+		}()
+	}
+
 	// Channel to handle graceful shutdown
 	stop := make(chan struct{})
+	var stopOnce sync.Once
+	shutdown := func() { stopOnce.Do(func() { close(stop) }) }
 	// Channel to listen for OS signals
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
@@ -69,19 +444,91 @@ func main() {
 	// WaitGroup to manage goroutines
 	var wg sync.WaitGroup
 
-	for _, symbol := range config.Symbols {
+	if rollupScheduler != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rollupScheduler.Run(stop)
+		}()
+	}
+
+	if partitionManager != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			partitionManager.Run(stop)
+		}()
+	}
+
+	if broadcastHub != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			broadcastHub.Run(stop)
+		}()
+	}
+
+	if frameRecorder != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			frameRecorder.Run(stop)
+		}()
+	}
+
+	if candleAggregator != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			candleAggregator.Run(stop)
+		}()
+	}
+
+	if correlationTracker != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			correlationTracker.Run(stop)
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		monitor.LogSummary(tickStore, stop)
+	}()
+
+	if *replayDir != "" {
 		wg.Add(1)
-		go func(symbol string) {
+		go func() {
 			defer wg.Done()
-			monitor.MonitorSymbol(symbol, db, stop)
-		}(symbol)
+			if err := monitor.Replay(*replayDir, *replaySpeed, tickStore, stop, processors...); err != nil {
+				log.Printf("Error replaying recorded frames: %v", err)
+			}
+			shutdown()
+		}()
+	} else {
+		for _, symbol := range config.Symbols {
+			wg.Add(1)
+			go func(symbol string) {
+				defer wg.Done()
+				if frameRecorder != nil {
+					monitor.RecordSymbol(symbol, tickStore, stop, frameRecorder, processors...)
+					return
+				}
+				monitor.MonitorSymbol(symbol, tickStore, stop, processors...)
+			}(symbol)
+		}
 	}
 
 	// Wait for an interrupt signal
 	go func() {
-		sig := <-sigs
-		log.Printf("Received signal: %s. Shutting down gracefully...", sig)
-		close(stop)
+		select {
+		case sig := <-sigs:
+			log.Printf("Received signal: %s. Shutting down gracefully...", sig)
+			shutdown()
+		case <-stop:
+		}
 	}()
 
 	// Wait for all goroutines to finish
@@ -89,10 +536,28 @@ func main() {
 	log.Println("All symbol monitoring stopped. Exiting program.")
 }
 
+// connectDB opens the Postgres database, retrying until it is reachable
+func connectDB(config Config) *sql.DB {
+	connStr := fmt.Sprintf("postgres://%s:%s@%s/%s?sslmode=disable", config.DB.User, config.DB.Password, config.DB.Host, config.DB.Name)
+	for {
+		db, err := sql.Open("postgres", connStr)
+		if err != nil {
+			log.Printf("Error connecting to the database: %v", err)
+		} else if err = db.Ping(); err == nil {
+			return db
+		} else {
+			db.Close()
+		}
+		log.Println("Waiting for the database to be ready...")
+		time.Sleep(2 * time.Second)
+	}
+}
+
 func loadConfig(config *Config) error {
 	viper.SetConfigName("config")
 	viper.SetConfigType("yaml")
 	viper.AddConfigPath("configs")
+	viper.SetDefault("db.migrate", true)
 
 	if err := viper.ReadInConfig(); err != nil {
 		return err
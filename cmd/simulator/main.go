@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/viper"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/simulator"
+)
+
+type Config struct {
+	Simulator simulator.Config
+}
+
+func main() {
+	addr := flag.String("addr", "", "listen address, overrides simulator.addr")
+	seed := flag.Int64("seed", 0, "random walk seed, overrides simulator.seed")
+	flag.Parse()
+
+	var config Config
+	if err := loadConfig(&config); err != nil {
+		log.Fatalf("Error loading configuration: %v", err)
+	}
+	if *addr != "" {
+		config.Simulator.Addr = *addr
+	}
+	if *seed != 0 {
+		config.Simulator.Seed = *seed
+	}
+
+	sim, err := simulator.NewSimulator(config.Simulator)
+	if err != nil {
+		log.Fatalf("Error creating simulator: %v", err)
+	}
+
+	stop := make(chan struct{})
+	go sim.Run(stop)
+
+	go func() {
+		if err := sim.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Error serving simulator: %v", err)
+		}
+	}()
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-sigs
+	log.Printf("Received signal: %s. Shutting down simulator...", sig)
+
+	close(stop)
+	if err := sim.Close(); err != nil {
+		log.Printf("Error closing simulator: %v", err)
+	}
+}
+
+func loadConfig(config *Config) error {
+	viper.SetConfigName("config")
+	viper.SetConfigType("yaml")
+	viper.AddConfigPath("configs")
+
+	if err := viper.ReadInConfig(); err != nil {
+		return err
+	}
+
+	return viper.Unmarshal(&config)
+}
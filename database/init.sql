@@ -42,33 +42,5 @@ GRANT ALL PRIVILEGES ON DATABASE postgres TO postgres;
 -- Connect to the created database to run further SQL commands
 \c postgres
 
--- Create the exchange_info table
-CREATE TABLE IF NOT EXISTS exchange_info
-(
-    symbol       VARCHAR(50),
-    status       VARCHAR(50),
-    base_asset   VARCHAR(50),
-    quote_asset  VARCHAR(50),
-    filter_type  VARCHAR(50),
-    filter_key   VARCHAR(50),
-    filter_value VARCHAR(50),
-    CONSTRAINT exchange_info_unique UNIQUE (symbol, filter_type, filter_key)
-);
-
-CREATE TABLE IF NOT EXISTS ticker_data
-(
-    id           SERIAL PRIMARY KEY,
-    event_time   BIGINT,
-    symbol       TEXT,
-    last_price   DOUBLE PRECISION,
-    price_change DOUBLE PRECISION,
-    high_price   DOUBLE PRECISION,
-    low_price    DOUBLE PRECISION,
-    volume       DOUBLE PRECISION,
-    quote_volume DOUBLE PRECISION,
-    open_time    BIGINT,
-    close_time   BIGINT,
-    trade_count  INT,
-    latency      BIGINT
-);
-
+-- Tables are created by the versioned migrations in internal/migrations/sql,
+-- applied by the monitor at startup or with `go run cmd/migrate/main.go up`
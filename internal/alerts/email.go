@@ -0,0 +1,112 @@
+package alerts
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// defaultSMTPTimeout bounds a whole SMTP session, so that an unresponsive
+// server cannot hold up the delivery of other alerts
+const defaultSMTPTimeout = 30 * time.Second
+
+// EmailNotifier sends alert events by SMTP
+type EmailNotifier struct {
+	addr    string
+	auth    smtp.Auth
+	from    string
+	to      []string
+	timeout time.Duration
+}
+
+// NewEmailNotifier creates a new EmailNotifier. PLAIN authentication is used
+// when a username is configured.
+func NewEmailNotifier(cfg NotifierConfig) *EmailNotifier {
+	notifier := &EmailNotifier{
+		addr:    cfg.SMTPAddr,
+		from:    cfg.From,
+		to:      cfg.To,
+		timeout: defaultSMTPTimeout,
+	}
+
+	if cfg.Username != "" {
+		host, _, _ := net.SplitHostPort(cfg.SMTPAddr)
+		notifier.auth = smtp.PlainAuth("", cfg.Username, cfg.Password, host)
+	}
+
+	return notifier
+}
+
+// Notify implements the Notifier interface
+func (n *EmailNotifier) Notify(event Event) error {
+	if len(n.to) == 0 {
+		return fmt.Errorf("email notifier has no recipients")
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", n.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(n.to, ", "))
+	fmt.Fprintf(&msg, "Subject: [alert] %s %s\r\n", event.Rule, event.Symbol)
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().UTC().Format(time.RFC1123Z))
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	fmt.Fprintf(&msg, "%s\r\n\r\nRule: %s\r\nSymbol: %s\r\nValue: %g\r\nThreshold: %g\r\nEvent time: %s\r\n",
+		event.Message, event.Rule, event.Symbol, event.Value, event.Threshold,
+		time.UnixMilli(event.EventTime).UTC().Format(time.RFC3339))
+
+	return n.send([]byte(msg.String()))
+}
+
+// send delivers a message like smtp.SendMail, within the notifier's timeout
+func (n *EmailNotifier) send(msg []byte) error {
+	conn, err := net.DialTimeout("tcp", n.addr, n.timeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(n.timeout)); err != nil {
+		return err
+	}
+
+	host, _, _ := net.SplitHostPort(n.addr)
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if n.auth != nil {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return fmt.Errorf("smtp server %s does not support authentication", n.addr)
+		}
+		if err := client.Auth(n.auth); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(n.from); err != nil {
+		return err
+	}
+	for _, to := range n.to {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(msg); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
@@ -0,0 +1,129 @@
+package alerts
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type smtpMessage struct {
+	auth string
+	from string
+	to   []string
+	data string
+}
+
+// runSMTPServer accepts a single SMTP session and reports the message it received
+func runSMTPServer(t *testing.T) (string, chan smtpMessage) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	messages := make(chan smtpMessage, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+		var msg smtpMessage
+
+		reply("220 localhost ESMTP")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			switch {
+			case strings.HasPrefix(line, "EHLO"):
+				reply("250-localhost")
+				reply("250 AUTH PLAIN")
+			case strings.HasPrefix(line, "AUTH"):
+				msg.auth = line
+				reply("235 Authentication successful")
+			case strings.HasPrefix(line, "MAIL FROM:"):
+				msg.from = strings.Trim(strings.TrimPrefix(line, "MAIL FROM:"), "<>")
+				reply("250 OK")
+			case strings.HasPrefix(line, "RCPT TO:"):
+				msg.to = append(msg.to, strings.Trim(strings.TrimPrefix(line, "RCPT TO:"), "<>"))
+				reply("250 OK")
+			case line == "DATA":
+				reply("354 End data with <CR><LF>.<CR><LF>")
+				var data strings.Builder
+				for {
+					dataLine, err := reader.ReadString('\n')
+					if err != nil || dataLine == ".\r\n" {
+						break
+					}
+					data.WriteString(dataLine)
+				}
+				msg.data = data.String()
+				reply("250 OK")
+			case line == "QUIT":
+				reply("221 Bye")
+				messages <- msg
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	return listener.Addr().String(), messages
+}
+
+func TestEmailNotifier_Notify(t *testing.T) {
+	addr, messages := runSMTPServer(t)
+
+	notifier := NewEmailNotifier(NotifierConfig{
+		SMTPAddr: addr,
+		Username: "alerts",
+		Password: "secret",
+		From:     "monitor@example.com",
+		To:       []string{"ops@example.com", "risk@example.com"},
+	})
+	require.NoError(t, notifier.Notify(Event{Rule: "btc-70k", Symbol: "BTCUSDT", Message: "BTCUSDT crossed 70000"}))
+
+	msg := <-messages
+	assert.True(t, strings.HasPrefix(msg.auth, "AUTH PLAIN"))
+	assert.Equal(t, "monitor@example.com", msg.from)
+	assert.Equal(t, []string{"ops@example.com", "risk@example.com"}, msg.to)
+	assert.Contains(t, msg.data, "Subject: [alert] btc-70k BTCUSDT")
+	assert.Contains(t, msg.data, "BTCUSDT crossed 70000")
+}
+
+func TestEmailNotifier_NoRecipients(t *testing.T) {
+	notifier := NewEmailNotifier(NotifierConfig{SMTPAddr: "127.0.0.1:1"})
+	assert.Error(t, notifier.Notify(Event{}))
+}
+
+func TestEmailNotifier_Timeout(t *testing.T) {
+	// A server that accepts connections but never greets the client
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			defer conn.Close()
+			time.Sleep(time.Second)
+		}
+	}()
+
+	notifier := NewEmailNotifier(NotifierConfig{SMTPAddr: listener.Addr().String(), To: []string{"ops@example.com"}})
+	notifier.timeout = 50 * time.Millisecond
+
+	started := time.Now()
+	assert.Error(t, notifier.Notify(Event{}))
+	assert.Less(t, time.Since(started), time.Second)
+}
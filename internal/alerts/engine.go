@@ -0,0 +1,182 @@
+package alerts
+
+import (
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+const eventBufferSize = 256
+
+// Config holds the alert rules and notifiers loaded from the configuration file
+type Config struct {
+	Enabled   bool
+	Rules     []Rule
+	Notifiers []NotifierConfig
+}
+
+// Event is fired when a rule's condition is met
+type Event struct {
+	Rule      string   `json:"rule"`
+	Type      RuleType `json:"type"`
+	Symbol    string   `json:"symbol"`
+	Value     float64  `json:"value"`
+	Threshold float64  `json:"threshold"`
+	EventTime int64    `json:"event_time"`
+	Message   string   `json:"message"`
+}
+
+type namedNotifier struct {
+	name     string
+	notifier Notifier
+}
+
+type stateKey struct {
+	rule   int
+	symbol string
+}
+
+// Engine implements DataProcessor interface and evaluates alert rules against
+// every tick. Events are handed to the notifiers on a background goroutine so
+// slow notifiers do not hold up the websocket reader.
+type Engine struct {
+	rules          []Rule
+	states         map[stateKey]*ruleState
+	routes         map[string][]string
+	notifiers      []namedNotifier
+	events         chan Event
+	done           chan struct{}
+	mutex          sync.Mutex
+	processedCount int
+	firedCount     int
+	closed         bool
+	closeOnce      sync.Once
+}
+
+// NewEngine validates the rules and creates a new Engine
+func NewEngine(rules []Rule) (*Engine, error) {
+	for _, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	engine := &Engine{
+		rules:  rules,
+		routes: make(map[string][]string),
+		states: make(map[stateKey]*ruleState),
+		events: make(chan Event, eventBufferSize),
+		done:   make(chan struct{}),
+	}
+	for _, rule := range rules {
+		engine.routes[rule.Name] = rule.Notifiers
+	}
+	go engine.dispatch()
+
+	return engine, nil
+}
+
+// AddNotifier adds a named notifier. Rules without an explicit notifier list
+// are delivered to every notifier; others only to the notifiers they name.
+func (e *Engine) AddNotifier(name string, notifier Notifier) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.notifiers = append(e.notifiers, namedNotifier{name: name, notifier: notifier})
+}
+
+// Process implements the DataProcessor interface
+func (e *Engine) Process(data models.FormattedData) {
+	e.mutex.Lock()
+	var fired []Event
+	symbol := strings.ToUpper(data.Symbol)
+	for i, rule := range e.rules {
+		if !rule.Matches(symbol) {
+			continue
+		}
+		key := stateKey{rule: i, symbol: symbol}
+		state, ok := e.states[key]
+		if !ok {
+			state = newRuleState()
+			e.states[key] = state
+		}
+		if event, ok := rule.evaluate(state, data); ok {
+			fired = append(fired, event)
+		}
+	}
+	e.processedCount++
+	e.firedCount += len(fired)
+	defer e.mutex.Unlock()
+
+	// Sending under the mutex keeps Close from closing the queue in between
+	if e.closed {
+		return
+	}
+	for _, event := range fired {
+		select {
+		case e.events <- event:
+		default:
+			log.Printf("Alert queue full, dropping alert %s for %s", event.Rule, event.Symbol)
+		}
+	}
+}
+
+// GetProcessedCount returns the number of processed messages
+func (e *Engine) GetProcessedCount() int {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.processedCount
+}
+
+// GetFiredCount returns the number of alerts fired
+func (e *Engine) GetFiredCount() int {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.firedCount
+}
+
+// GetBufferSize returns the number of alerts waiting to be delivered
+func (e *Engine) GetBufferSize() int {
+	return len(e.events)
+}
+
+// Close stops accepting alerts and waits for queued ones to be delivered
+func (e *Engine) Close() error {
+	e.closeOnce.Do(func() {
+		e.mutex.Lock()
+		e.closed = true
+		close(e.events)
+		e.mutex.Unlock()
+	})
+	<-e.done
+	return nil
+}
+
+func (e *Engine) dispatch() {
+	defer close(e.done)
+	for event := range e.events {
+		e.mutex.Lock()
+		notifiers := e.notifiers
+		route := e.routes[event.Rule]
+		e.mutex.Unlock()
+
+		for _, n := range notifiers {
+			if len(route) > 0 && !contains(route, n.name) {
+				continue
+			}
+			if err := n.notifier.Notify(event); err != nil {
+				log.Printf("Error delivering alert %s to %s: %v", event.Rule, n.name, err)
+			}
+		}
+	}
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
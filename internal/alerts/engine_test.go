@@ -0,0 +1,102 @@
+package alerts
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+type mockNotifier struct {
+	mutex  sync.Mutex
+	events []Event
+}
+
+func (m *mockNotifier) Notify(event Event) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.events = append(m.events, event)
+	return nil
+}
+
+func TestNewEngine_InvalidRule(t *testing.T) {
+	_, err := NewEngine([]Rule{{Name: "bad", Type: "unknown", Threshold: 1}})
+	assert.Error(t, err)
+}
+
+func TestEngine_Process(t *testing.T) {
+	engine, err := NewEngine([]Rule{
+		{Name: "btc-70k", Symbol: "BTCUSDT", Type: PriceCross, Threshold: 70000},
+		{Name: "any-10", Symbol: "*", Type: PriceCross, Threshold: 10},
+	})
+	require.NoError(t, err)
+	notifier := &mockNotifier{}
+	engine.AddNotifier("mock", notifier)
+
+	for _, tick := range []models.FormattedData{
+		{EventTime: 1, Symbol: "BTCUSDT", LastPrice: 69000},
+		{EventTime: 1, Symbol: "LTCUSDT", LastPrice: 9},
+		{EventTime: 2, Symbol: "BTCUSDT", LastPrice: 70001},
+		{EventTime: 2, Symbol: "LTCUSDT", LastPrice: 11},
+	} {
+		engine.Process(tick)
+	}
+
+	require.NoError(t, engine.Close())
+
+	assert.Equal(t, 4, engine.GetProcessedCount())
+	assert.Equal(t, 2, engine.GetFiredCount())
+	assert.Equal(t, 0, engine.GetBufferSize())
+	require.Len(t, notifier.events, 2)
+	assert.Equal(t, "btc-70k", notifier.events[0].Rule)
+	assert.Equal(t, "any-10", notifier.events[1].Rule)
+	assert.Equal(t, "LTCUSDT", notifier.events[1].Symbol)
+}
+
+func TestEngine_Routing(t *testing.T) {
+	engine, err := NewEngine([]Rule{
+		{Name: "to-slack", Type: PriceCross, Threshold: 10, Notifiers: []string{"slack"}},
+		{Name: "to-all", Type: PriceCross, Threshold: 20},
+	})
+	require.NoError(t, err)
+	slack := &mockNotifier{}
+	email := &mockNotifier{}
+	engine.AddNotifier("slack", slack)
+	engine.AddNotifier("email", email)
+
+	engine.Process(models.FormattedData{EventTime: 1, Symbol: "BTCUSDT", LastPrice: 5})
+	engine.Process(models.FormattedData{EventTime: 2, Symbol: "BTCUSDT", LastPrice: 25})
+	require.NoError(t, engine.Close())
+
+	assert.Len(t, slack.events, 2)
+	require.Len(t, email.events, 1)
+	assert.Equal(t, "to-all", email.events[0].Rule)
+}
+
+func TestEngine_ProcessAfterClose(t *testing.T) {
+	engine, err := NewEngine([]Rule{{Name: "any-10", Type: PriceCross, Threshold: 10}})
+	require.NoError(t, err)
+	notifier := &mockNotifier{}
+	engine.AddNotifier("mock", notifier)
+
+	// Ticks still being processed during shutdown are dropped, not sent on
+	// the closed queue
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			engine.Process(models.FormattedData{EventTime: int64(i), Symbol: "BTCUSDT", LastPrice: float64(5 + 10*(i%2))})
+		}
+	}()
+	require.NoError(t, engine.Close())
+	wg.Wait()
+
+	assert.NotPanics(t, func() {
+		engine.Process(models.FormattedData{EventTime: 2000, Symbol: "BTCUSDT", LastPrice: 5})
+		engine.Process(models.FormattedData{EventTime: 2001, Symbol: "BTCUSDT", LastPrice: 15})
+	})
+}
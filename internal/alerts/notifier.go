@@ -0,0 +1,208 @@
+package alerts
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	defaultRetryBackoff = 500 * time.Millisecond
+	defaultHTTPTimeout  = 10 * time.Second
+)
+
+// ErrRateLimited is returned when a notifier drops an event over its rate limit
+var ErrRateLimited = errors.New("alert rate limit exceeded")
+
+// Notifier delivers alert events
+type Notifier interface {
+	Notify(event Event) error
+}
+
+// NotifierConfig holds the settings of a single notifier. Only the fields
+// relevant to its type are used.
+type NotifierConfig struct {
+	Name string
+	// Type is one of log, webhook, slack, telegram or email
+	Type string
+	// URL is the webhook, Slack incoming webhook or Telegram API base URL
+	URL string
+	// Template is a text/template for the webhook JSON body
+	Template string
+	// Secret signs webhook bodies with HMAC-SHA256
+	Secret       string
+	Retries      int
+	RetryBackoff time.Duration `mapstructure:"retry_backoff"`
+	// RateLimit is the maximum number of events per minute for each rule
+	RateLimit float64 `mapstructure:"rate_limit"`
+	BotToken  string  `mapstructure:"bot_token"`
+	ChatID    string  `mapstructure:"chat_id"`
+	SMTPAddr  string  `mapstructure:"smtp_addr"`
+	Username  string
+	Password  string
+	From      string
+	To        []string
+}
+
+// NewNotifier creates the notifier described by cfg
+func NewNotifier(cfg NotifierConfig) (Notifier, error) {
+	var notifier Notifier
+	switch cfg.Type {
+	case "log":
+		notifier = LogNotifier{}
+	case "webhook":
+		n, err := NewWebhookNotifier(cfg)
+		if err != nil {
+			return nil, err
+		}
+		notifier = n
+	case "slack":
+		notifier = NewSlackNotifier(cfg)
+	case "telegram":
+		notifier = NewTelegramNotifier(cfg)
+	case "email":
+		notifier = NewEmailNotifier(cfg)
+	default:
+		return nil, fmt.Errorf("notifier %s: unknown type %q", cfg.Name, cfg.Type)
+	}
+
+	if cfg.RateLimit > 0 {
+		notifier = NewRateLimitedNotifier(notifier, cfg.RateLimit)
+	}
+	return notifier, nil
+}
+
+// Validate checks that every notifier a rule routes to is configured
+func (c Config) Validate() error {
+	names := make(map[string]bool)
+	for _, n := range c.Notifiers {
+		if n.Name == "" {
+			return fmt.Errorf("alert notifier is missing a name")
+		}
+		names[n.Name] = true
+	}
+	for _, rule := range c.Rules {
+		for _, name := range rule.Notifiers {
+			if !names[name] {
+				return fmt.Errorf("alert rule %s: unknown notifier %q", rule.Name, name)
+			}
+		}
+	}
+	return nil
+}
+
+// LogNotifier writes alert events to the standard logger
+type LogNotifier struct{}
+
+// Notify implements the Notifier interface
+func (LogNotifier) Notify(event Event) error {
+	log.Printf("ALERT [%s] %s", event.Rule, event.Message)
+	return nil
+}
+
+// RateLimitedNotifier wraps a Notifier and limits the events of each rule to a
+// number per minute, dropping the excess
+type RateLimitedNotifier struct {
+	next     Notifier
+	perMin   float64
+	mutex    sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+// NewRateLimitedNotifier creates a new RateLimitedNotifier
+func NewRateLimitedNotifier(next Notifier, perMinute float64) *RateLimitedNotifier {
+	return &RateLimitedNotifier{
+		next:     next,
+		perMin:   perMinute,
+		limiters: make(map[string]*rate.Limiter),
+	}
+}
+
+// Notify implements the Notifier interface
+func (n *RateLimitedNotifier) Notify(event Event) error {
+	n.mutex.Lock()
+	limiter, ok := n.limiters[event.Rule]
+	if !ok {
+		burst := int(n.perMin)
+		if burst < 1 {
+			burst = 1
+		}
+		limiter = rate.NewLimiter(rate.Limit(n.perMin/60), burst)
+		n.limiters[event.Rule] = limiter
+	}
+	n.mutex.Unlock()
+
+	if !limiter.Allow() {
+		return ErrRateLimited
+	}
+	return n.next.Notify(event)
+}
+
+// httpSender posts request bodies with retries and exponential backoff
+type httpSender struct {
+	client  *http.Client
+	retries int
+	backoff time.Duration
+}
+
+func newHTTPSender(cfg NotifierConfig) httpSender {
+	backoff := cfg.RetryBackoff
+	if backoff <= 0 {
+		backoff = defaultRetryBackoff
+	}
+	return httpSender{
+		client:  &http.Client{Timeout: defaultHTTPTimeout},
+		retries: cfg.Retries,
+		backoff: backoff,
+	}
+}
+
+// post sends body to url, retrying network errors, 429 and 5xx responses
+func (s httpSender) post(url string, body []byte, headers map[string]string) error {
+	var err error
+	backoff := s.backoff
+	for attempt := 0; attempt <= s.retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+
+		var retry bool
+		retry, err = s.postOnce(url, body, headers)
+		if err == nil || !retry {
+			return err
+		}
+	}
+	return err
+}
+
+func (s httpSender) postOnce(url string, body []byte, headers map[string]string) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("unexpected status %s", resp.Status)
+}
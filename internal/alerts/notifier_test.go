@@ -0,0 +1,83 @@
+package alerts
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewNotifier(t *testing.T) {
+	for _, typ := range []string{"log", "webhook", "slack", "telegram", "email"} {
+		notifier, err := NewNotifier(NotifierConfig{Name: typ, Type: typ})
+		assert.NoError(t, err, typ)
+		assert.NotNil(t, notifier, typ)
+	}
+
+	_, err := NewNotifier(NotifierConfig{Name: "pager", Type: "pager"})
+	assert.Error(t, err)
+
+	notifier, err := NewNotifier(NotifierConfig{Name: "limited", Type: "log", RateLimit: 1})
+	require.NoError(t, err)
+	assert.IsType(t, &RateLimitedNotifier{}, notifier)
+}
+
+func TestConfig_Validate(t *testing.T) {
+	cfg := Config{
+		Rules:     []Rule{{Name: "rule", Notifiers: []string{"ops"}}},
+		Notifiers: []NotifierConfig{{Name: "ops", Type: "log"}},
+	}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Rules[0].Notifiers = []string{"missing"}
+	assert.Error(t, cfg.Validate())
+}
+
+func TestLogNotifier(t *testing.T) {
+	assert.NoError(t, LogNotifier{}.Notify(Event{Rule: "test", Message: "message"}))
+}
+
+func TestRateLimitedNotifier(t *testing.T) {
+	sink := &mockNotifier{}
+	notifier := NewRateLimitedNotifier(sink, 2)
+
+	assert.NoError(t, notifier.Notify(Event{Rule: "a"}))
+	assert.NoError(t, notifier.Notify(Event{Rule: "a"}))
+	assert.ErrorIs(t, notifier.Notify(Event{Rule: "a"}), ErrRateLimited)
+	assert.NoError(t, notifier.Notify(Event{Rule: "b"}), "each rule has its own limit")
+
+	assert.Len(t, sink.events, 3)
+}
+
+func TestHTTPSender_Retries(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sender := newHTTPSender(NotifierConfig{Retries: 2, RetryBackoff: time.Millisecond})
+	assert.NoError(t, sender.post(server.URL, []byte(`{}`), nil))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+}
+
+func TestHTTPSender_NoRetryOnClientError(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	sender := newHTTPSender(NotifierConfig{Retries: 3, RetryBackoff: time.Millisecond})
+	assert.Error(t, sender.post(server.URL, []byte(`{}`), nil))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&attempts))
+}
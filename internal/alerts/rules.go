@@ -0,0 +1,237 @@
+package alerts
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+// RuleType identifies how a rule is evaluated
+type RuleType string
+
+const (
+	// PriceCross fires when the last price crosses Threshold
+	PriceCross RuleType = "price_cross"
+	// PercentMove fires when the price moves more than Threshold percent within Window
+	PercentMove RuleType = "percent_move"
+	// Latency fires when latency stays above Threshold milliseconds for Duration
+	Latency RuleType = "latency"
+)
+
+// Direction restricts the direction a rule fires in
+type Direction string
+
+const (
+	Any  Direction = ""
+	Up   Direction = "up"
+	Down Direction = "down"
+)
+
+// Rule is a user-declared alert condition. Symbol may be empty or "*" to
+// match every symbol. Hysteresis is expressed in the rule's unit (price,
+// percent or milliseconds) and must be cleared before the rule re-arms.
+type Rule struct {
+	Name       string
+	Symbol     string
+	Type       RuleType
+	Direction  Direction
+	Threshold  float64
+	Hysteresis float64
+	Window     time.Duration
+	Duration   time.Duration
+	Cooldown   time.Duration
+	// Notifiers routes the rule's events to the named notifiers; empty means all
+	Notifiers []string
+}
+
+// Validate checks the rule for missing or inconsistent settings
+func (r Rule) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("alert rule is missing a name")
+	}
+	switch r.Direction {
+	case Any, Up, Down:
+	default:
+		return fmt.Errorf("alert rule %s: unknown direction %q", r.Name, r.Direction)
+	}
+	switch r.Type {
+	case PriceCross:
+	case PercentMove:
+		if r.Window <= 0 {
+			return fmt.Errorf("alert rule %s: percent_move requires a window", r.Name)
+		}
+	case Latency:
+	default:
+		return fmt.Errorf("alert rule %s: unknown type %q", r.Name, r.Type)
+	}
+	if r.Threshold <= 0 {
+		return fmt.Errorf("alert rule %s: threshold must be positive", r.Name)
+	}
+	if r.Hysteresis < 0 {
+		return fmt.Errorf("alert rule %s: hysteresis must not be negative", r.Name)
+	}
+	return nil
+}
+
+// Matches reports whether the rule applies to a symbol
+func (r Rule) Matches(symbol string) bool {
+	return r.Symbol == "" || r.Symbol == "*" || strings.EqualFold(r.Symbol, symbol)
+}
+
+type pricePoint struct {
+	time  int64
+	price float64
+}
+
+// ruleState is the per-symbol evaluation state of a rule
+type ruleState struct {
+	armed     bool
+	lastFired int64
+	hasPrev   bool
+	prevPrice float64
+	history   []pricePoint
+	breaching bool
+	since     int64
+}
+
+func newRuleState() *ruleState {
+	return &ruleState{armed: true, lastFired: math.MinInt64}
+}
+
+// evaluate folds a tick into the state and returns an event when the rule fires
+func (r Rule) evaluate(state *ruleState, data models.FormattedData) (Event, bool) {
+	var value float64
+	var triggered bool
+
+	switch r.Type {
+	case PriceCross:
+		value = data.LastPrice
+		triggered = r.evaluateCross(state, data.LastPrice)
+	case PercentMove:
+		value, triggered = r.evaluateMove(state, data)
+	case Latency:
+		value = float64(data.Latency)
+		triggered = r.evaluateLatency(state, data)
+	}
+
+	if !triggered {
+		return Event{}, false
+	}
+
+	state.armed = false
+	if state.lastFired != math.MinInt64 && data.EventTime-state.lastFired < r.Cooldown.Milliseconds() {
+		return Event{}, false
+	}
+	state.lastFired = data.EventTime
+
+	return Event{
+		Rule:      r.Name,
+		Type:      r.Type,
+		Symbol:    data.Symbol,
+		Value:     value,
+		Threshold: r.Threshold,
+		EventTime: data.EventTime,
+		Message:   r.message(data.Symbol, value),
+	}, true
+}
+
+func (r Rule) evaluateCross(state *ruleState, price float64) bool {
+	prev, hasPrev := state.prevPrice, state.hasPrev
+	state.prevPrice, state.hasPrev = price, true
+
+	if !state.armed {
+		state.armed = math.Abs(price-r.Threshold) >= r.Hysteresis &&
+			(r.Direction != Up || price < r.Threshold) &&
+			(r.Direction != Down || price > r.Threshold)
+		return false
+	}
+	if !hasPrev {
+		return false
+	}
+
+	crossedUp := prev < r.Threshold && price >= r.Threshold
+	crossedDown := prev > r.Threshold && price <= r.Threshold
+	switch r.Direction {
+	case Up:
+		return crossedUp
+	case Down:
+		return crossedDown
+	default:
+		return crossedUp || crossedDown
+	}
+}
+
+func (r Rule) evaluateMove(state *ruleState, data models.FormattedData) (float64, bool) {
+	cutoff := data.EventTime - r.Window.Milliseconds()
+	history := state.history[:0]
+	for _, p := range state.history {
+		if p.time >= cutoff {
+			history = append(history, p)
+		}
+	}
+	state.history = append(history, pricePoint{time: data.EventTime, price: data.LastPrice})
+
+	low, high := data.LastPrice, data.LastPrice
+	for _, p := range state.history {
+		low = math.Min(low, p.price)
+		high = math.Max(high, p.price)
+	}
+
+	var rise, fall float64
+	if low > 0 {
+		rise = (data.LastPrice - low) / low * 100
+	}
+	if high > 0 {
+		fall = (high - data.LastPrice) / high * 100
+	}
+
+	var move float64
+	switch r.Direction {
+	case Up:
+		move = rise
+	case Down:
+		move = -fall
+	default:
+		move = rise
+		if fall > rise {
+			move = -fall
+		}
+	}
+
+	if !state.armed {
+		state.armed = math.Abs(move) < r.Threshold-r.Hysteresis
+		return move, false
+	}
+	return move, math.Abs(move) >= r.Threshold
+}
+
+func (r Rule) evaluateLatency(state *ruleState, data models.FormattedData) bool {
+	latency := float64(data.Latency)
+
+	if latency <= r.Threshold {
+		if latency <= r.Threshold-r.Hysteresis {
+			state.breaching = false
+			state.armed = true
+		}
+		return false
+	}
+	if !state.breaching {
+		state.breaching = true
+		state.since = data.EventTime
+	}
+	return state.armed && data.EventTime-state.since >= r.Duration.Milliseconds()
+}
+
+func (r Rule) message(symbol string, value float64) string {
+	switch r.Type {
+	case PriceCross:
+		return fmt.Sprintf("%s last price %.8g crossed %.8g", symbol, value, r.Threshold)
+	case PercentMove:
+		return fmt.Sprintf("%s moved %.2f%% within %s", symbol, value, r.Window)
+	default:
+		return fmt.Sprintf("%s latency %.0fms above %.0fms for %s", symbol, value, r.Threshold, r.Duration)
+	}
+}
@@ -0,0 +1,122 @@
+package alerts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+func feed(rule Rule, ticks []models.FormattedData) []Event {
+	state := newRuleState()
+	var events []Event
+	for _, tick := range ticks {
+		if event, ok := rule.evaluate(state, tick); ok {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+func priceTicks(prices ...float64) []models.FormattedData {
+	ticks := make([]models.FormattedData, len(prices))
+	for i, price := range prices {
+		ticks[i] = models.FormattedData{EventTime: int64(i) * 1000, Symbol: "BTCUSDT", LastPrice: price}
+	}
+	return ticks
+}
+
+func TestRule_Validate(t *testing.T) {
+	assert.NoError(t, Rule{Name: "ok", Type: PriceCross, Threshold: 1}.Validate())
+	assert.Error(t, Rule{Type: PriceCross, Threshold: 1}.Validate())
+	assert.Error(t, Rule{Name: "type", Type: "unknown", Threshold: 1}.Validate())
+	assert.Error(t, Rule{Name: "threshold", Type: PriceCross}.Validate())
+	assert.Error(t, Rule{Name: "window", Type: PercentMove, Threshold: 1}.Validate())
+	assert.Error(t, Rule{Name: "direction", Type: PriceCross, Threshold: 1, Direction: "sideways"}.Validate())
+}
+
+func TestRule_Matches(t *testing.T) {
+	assert.True(t, Rule{}.Matches("BTCUSDT"))
+	assert.True(t, Rule{Symbol: "*"}.Matches("BTCUSDT"))
+	assert.True(t, Rule{Symbol: "btcusdt"}.Matches("BTCUSDT"))
+	assert.False(t, Rule{Symbol: "ETHUSDT"}.Matches("BTCUSDT"))
+}
+
+func TestRule_PriceCross(t *testing.T) {
+	rule := Rule{Name: "btc-70k", Type: PriceCross, Direction: Up, Threshold: 70000}
+
+	events := feed(rule, priceTicks(69000, 69900, 70100, 70200, 69800, 70050))
+	assert.Len(t, events, 2)
+	assert.Equal(t, 70100.0, events[0].Value)
+	assert.Equal(t, 70050.0, events[1].Value)
+}
+
+func TestRule_PriceCrossHysteresis(t *testing.T) {
+	rule := Rule{Name: "btc-70k", Type: PriceCross, Direction: Up, Threshold: 70000, Hysteresis: 500}
+
+	// Dipping to 69800 is not far enough below the threshold to re-arm
+	events := feed(rule, priceTicks(69000, 70100, 69800, 70100, 69400, 69600, 70100))
+	assert.Len(t, events, 2)
+}
+
+func TestRule_PriceCrossCooldown(t *testing.T) {
+	rule := Rule{Name: "btc-70k", Type: PriceCross, Threshold: 70000, Cooldown: 10 * time.Second}
+
+	events := feed(rule, priceTicks(69000, 70100, 69900, 70100, 69900))
+	assert.Len(t, events, 1, "crossings within the cooldown should be suppressed")
+}
+
+func TestRule_PercentMove(t *testing.T) {
+	rule := Rule{Name: "eth-3pct", Type: PercentMove, Threshold: 3, Window: 5 * time.Minute}
+
+	ticks := []models.FormattedData{
+		{EventTime: 0, Symbol: "ETHUSDT", LastPrice: 100},
+		{EventTime: 60_000, Symbol: "ETHUSDT", LastPrice: 101},
+		{EventTime: 120_000, Symbol: "ETHUSDT", LastPrice: 103.5},
+		{EventTime: 130_000, Symbol: "ETHUSDT", LastPrice: 104},
+	}
+	events := feed(rule, ticks)
+	assert.Len(t, events, 1)
+	assert.InDelta(t, 3.5, events[0].Value, 1e-9)
+
+	// The same move spread over more than the window does not fire
+	slow := []models.FormattedData{
+		{EventTime: 0, Symbol: "ETHUSDT", LastPrice: 100},
+		{EventTime: 200_000, Symbol: "ETHUSDT", LastPrice: 102},
+		{EventTime: 400_000, Symbol: "ETHUSDT", LastPrice: 104},
+	}
+	assert.Empty(t, feed(rule, slow))
+}
+
+func TestRule_PercentMoveDown(t *testing.T) {
+	rule := Rule{Name: "drop", Type: PercentMove, Direction: Down, Threshold: 5, Window: time.Minute}
+
+	events := feed(rule, priceTicks(100, 110, 104))
+	assert.Len(t, events, 1)
+	assert.Less(t, events[0].Value, 0.0)
+
+	assert.Empty(t, feed(rule, priceTicks(100, 110)), "rises should not fire a down rule")
+}
+
+func TestRule_Latency(t *testing.T) {
+	rule := Rule{Name: "slow", Type: Latency, Threshold: 2000, Duration: 30 * time.Second}
+
+	var ticks []models.FormattedData
+	for i := int64(0); i <= 40; i++ {
+		ticks = append(ticks, models.FormattedData{EventTime: i * 1000, Symbol: "BTCUSDT", Latency: 2500})
+	}
+	events := feed(rule, ticks)
+	assert.Len(t, events, 1)
+	assert.Equal(t, int64(30_000), events[0].EventTime)
+
+	// A single recovered tick resets the timer
+	spiky := []models.FormattedData{
+		{EventTime: 0, Latency: 2500},
+		{EventTime: 20_000, Latency: 100},
+		{EventTime: 40_000, Latency: 2500},
+		{EventTime: 60_000, Latency: 2500},
+	}
+	assert.Empty(t, feed(rule, spiky))
+}
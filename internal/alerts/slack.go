@@ -0,0 +1,31 @@
+package alerts
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// SlackNotifier posts alert events to a Slack incoming webhook
+type SlackNotifier struct {
+	url    string
+	sender httpSender
+}
+
+// NewSlackNotifier creates a new SlackNotifier
+func NewSlackNotifier(cfg NotifierConfig) *SlackNotifier {
+	return &SlackNotifier{
+		url:    cfg.URL,
+		sender: newHTTPSender(cfg),
+	}
+}
+
+// Notify implements the Notifier interface
+func (n *SlackNotifier) Notify(event Event) error {
+	body, err := json.Marshal(map[string]string{
+		"text": fmt.Sprintf(":rotating_light: *%s* %s", event.Rule, event.Message),
+	})
+	if err != nil {
+		return err
+	}
+	return n.sender.post(n.url, body, nil)
+}
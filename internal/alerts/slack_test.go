@@ -0,0 +1,24 @@
+package alerts
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlackNotifier_Notify(t *testing.T) {
+	server, requests := captureServer(t)
+
+	notifier := NewSlackNotifier(NotifierConfig{URL: server.URL + "/services/T000/B000"})
+	require.NoError(t, notifier.Notify(Event{Rule: "btc-70k", Message: "BTCUSDT crossed 70000"}))
+
+	req := <-requests
+	assert.Equal(t, "/services/T000/B000", req.path)
+
+	var payload map[string]string
+	require.NoError(t, json.Unmarshal(req.body, &payload))
+	assert.Contains(t, payload["text"], "btc-70k")
+	assert.Contains(t, payload["text"], "BTCUSDT crossed 70000")
+}
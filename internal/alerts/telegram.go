@@ -0,0 +1,43 @@
+package alerts
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const defaultTelegramURL = "https://api.telegram.org"
+
+// TelegramNotifier sends alert events through the Telegram bot API
+type TelegramNotifier struct {
+	url    string
+	chatID string
+	sender httpSender
+}
+
+// NewTelegramNotifier creates a new TelegramNotifier. URL overrides the bot
+// API base URL.
+func NewTelegramNotifier(cfg NotifierConfig) *TelegramNotifier {
+	base := cfg.URL
+	if base == "" {
+		base = defaultTelegramURL
+	}
+
+	return &TelegramNotifier{
+		url:    fmt.Sprintf("%s/bot%s/sendMessage", strings.TrimRight(base, "/"), cfg.BotToken),
+		chatID: cfg.ChatID,
+		sender: newHTTPSender(cfg),
+	}
+}
+
+// Notify implements the Notifier interface
+func (n *TelegramNotifier) Notify(event Event) error {
+	body, err := json.Marshal(map[string]string{
+		"chat_id": n.chatID,
+		"text":    fmt.Sprintf("[%s] %s", event.Rule, event.Message),
+	})
+	if err != nil {
+		return err
+	}
+	return n.sender.post(n.url, body, nil)
+}
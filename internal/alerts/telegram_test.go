@@ -0,0 +1,29 @@
+package alerts
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTelegramNotifier_Notify(t *testing.T) {
+	server, requests := captureServer(t)
+
+	notifier := NewTelegramNotifier(NotifierConfig{URL: server.URL + "/", BotToken: "123:abc", ChatID: "-10042"})
+	require.NoError(t, notifier.Notify(Event{Rule: "eth-3pct", Message: "ETHUSDT moved 3.10%"}))
+
+	req := <-requests
+	assert.Equal(t, "/bot123:abc/sendMessage", req.path)
+
+	var payload map[string]string
+	require.NoError(t, json.Unmarshal(req.body, &payload))
+	assert.Equal(t, "-10042", payload["chat_id"])
+	assert.Equal(t, "[eth-3pct] ETHUSDT moved 3.10%", payload["text"])
+}
+
+func TestNewTelegramNotifier_DefaultURL(t *testing.T) {
+	notifier := NewTelegramNotifier(NotifierConfig{BotToken: "token"})
+	assert.Equal(t, "https://api.telegram.org/bottoken/sendMessage", notifier.url)
+}
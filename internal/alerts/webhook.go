@@ -0,0 +1,85 @@
+package alerts
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"text/template"
+)
+
+// SignatureHeader carries the HMAC-SHA256 signature of a webhook body
+const SignatureHeader = "X-Signature-256"
+
+// WebhookNotifier posts alert events to a generic HTTP endpoint
+type WebhookNotifier struct {
+	url      string
+	secret   []byte
+	template *template.Template
+	sender   httpSender
+}
+
+// NewWebhookNotifier creates a new WebhookNotifier. Without a template the
+// event is sent as JSON; a template can reference the Event fields and the
+// json function for escaping, e.g. {"text": {{json .Message}}}.
+func NewWebhookNotifier(cfg NotifierConfig) (*WebhookNotifier, error) {
+	notifier := &WebhookNotifier{
+		url:    cfg.URL,
+		secret: []byte(cfg.Secret),
+		sender: newHTTPSender(cfg),
+	}
+
+	if cfg.Template != "" {
+		tmpl, err := template.New(cfg.Name).Funcs(template.FuncMap{"json": jsonValue}).Parse(cfg.Template)
+		if err != nil {
+			return nil, fmt.Errorf("notifier %s: invalid template: %w", cfg.Name, err)
+		}
+		notifier.template = tmpl
+	}
+
+	return notifier, nil
+}
+
+// Notify implements the Notifier interface
+func (n *WebhookNotifier) Notify(event Event) error {
+	body, err := n.body(event)
+	if err != nil {
+		return err
+	}
+
+	headers := make(map[string]string)
+	if len(n.secret) > 0 {
+		headers[SignatureHeader] = "sha256=" + Sign(n.secret, body)
+	}
+
+	return n.sender.post(n.url, body, headers)
+}
+
+func (n *WebhookNotifier) body(event Event) ([]byte, error) {
+	if n.template == nil {
+		return json.Marshal(event)
+	}
+
+	var buf bytes.Buffer
+	if err := n.template.Execute(&buf, event); err != nil {
+		return nil, fmt.Errorf("rendering webhook template: %w", err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		return nil, fmt.Errorf("webhook template did not render valid JSON")
+	}
+	return buf.Bytes(), nil
+}
+
+// Sign returns the hex-encoded HMAC-SHA256 of body
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func jsonValue(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
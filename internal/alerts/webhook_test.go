@@ -0,0 +1,71 @@
+package alerts
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type capturedRequest struct {
+	path    string
+	headers http.Header
+	body    []byte
+}
+
+func captureServer(t *testing.T) (*httptest.Server, chan capturedRequest) {
+	t.Helper()
+	requests := make(chan capturedRequest, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- capturedRequest{path: r.URL.Path, headers: r.Header, body: body}
+	}))
+	t.Cleanup(server.Close)
+	return server, requests
+}
+
+func TestWebhookNotifier_SignedJSON(t *testing.T) {
+	server, requests := captureServer(t)
+
+	notifier, err := NewWebhookNotifier(NotifierConfig{Name: "hook", URL: server.URL, Secret: "s3cret"})
+	require.NoError(t, err)
+
+	event := Event{Rule: "btc-70k", Symbol: "BTCUSDT", Value: 70100, Threshold: 70000, Message: "crossed"}
+	require.NoError(t, notifier.Notify(event))
+
+	req := <-requests
+	var received Event
+	require.NoError(t, json.Unmarshal(req.body, &received))
+	assert.Equal(t, event, received)
+	assert.Equal(t, "sha256="+Sign([]byte("s3cret"), req.body), req.headers.Get(SignatureHeader))
+}
+
+func TestWebhookNotifier_Template(t *testing.T) {
+	server, requests := captureServer(t)
+
+	notifier, err := NewWebhookNotifier(NotifierConfig{
+		Name:     "hook",
+		URL:      server.URL,
+		Template: `{"alert": {{json .Rule}}, "text": {{json .Message}}, "price": {{.Value}}}`,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, notifier.Notify(Event{Rule: "r", Message: `quote " inside`, Value: 1.5}))
+
+	req := <-requests
+	assert.JSONEq(t, `{"alert": "r", "text": "quote \" inside", "price": 1.5}`, string(req.body))
+	assert.Empty(t, req.headers.Get(SignatureHeader))
+}
+
+func TestWebhookNotifier_InvalidTemplate(t *testing.T) {
+	_, err := NewWebhookNotifier(NotifierConfig{Name: "hook", Template: "{{"})
+	assert.Error(t, err)
+
+	notifier, err := NewWebhookNotifier(NotifierConfig{Name: "hook", Template: "not json"})
+	require.NoError(t, err)
+	assert.Error(t, notifier.Notify(Event{}))
+}
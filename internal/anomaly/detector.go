@@ -0,0 +1,266 @@
+package anomaly
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+// Params tunes the detector for a symbol. Zero values fall back to the
+// detector-wide defaults.
+type Params struct {
+	// Alpha is the EWMA smoothing factor for price returns
+	Alpha float64
+	// Window is the number of ticks in the rolling median windows
+	Window int
+	// Warmup is the number of ticks observed before anything is flagged
+	Warmup int
+	// ReturnThreshold is the EWMA z-score that flags a price return
+	ReturnThreshold float64 `mapstructure:"return_threshold"`
+	// SpikeThreshold is the robust z-score that flags volume and trade spikes
+	SpikeThreshold float64 `mapstructure:"spike_threshold"`
+	// QuietFraction and QuietDuration flag a quiet period when trade deltas stay
+	// below this fraction of their median for the duration
+	QuietFraction float64       `mapstructure:"quiet_fraction"`
+	QuietDuration time.Duration `mapstructure:"quiet_duration"`
+}
+
+// DefaultParams returns the default detector parameters
+func DefaultParams() Params {
+	return Params{
+		Alpha:           0.05,
+		Window:          120,
+		Warmup:          30,
+		ReturnThreshold: 4,
+		SpikeThreshold:  6,
+		QuietFraction:   0.1,
+		QuietDuration:   30 * time.Second,
+	}
+}
+
+func (p Params) merge(defaults Params) Params {
+	if p.Alpha <= 0 {
+		p.Alpha = defaults.Alpha
+	}
+	if p.Window <= 0 {
+		p.Window = defaults.Window
+	}
+	if p.Warmup <= 0 {
+		p.Warmup = defaults.Warmup
+	}
+	if p.ReturnThreshold <= 0 {
+		p.ReturnThreshold = defaults.ReturnThreshold
+	}
+	if p.SpikeThreshold <= 0 {
+		p.SpikeThreshold = defaults.SpikeThreshold
+	}
+	if p.QuietFraction <= 0 {
+		p.QuietFraction = defaults.QuietFraction
+	}
+	if p.QuietDuration <= 0 {
+		p.QuietDuration = defaults.QuietDuration
+	}
+	return p
+}
+
+// Config holds the detector settings with optional per-symbol overrides
+type Config struct {
+	Enabled bool
+	Persist bool
+	Params  `mapstructure:",squash"`
+	Symbols map[string]Params
+}
+
+// AnomalyProcessor receives anomalies from a Detector
+type AnomalyProcessor interface {
+	ProcessAnomaly(anomaly models.Anomaly)
+}
+
+type symbolState struct {
+	params     Params
+	ticks      int
+	prev       models.FormattedData
+	returns    *EWMA
+	volumes    *RollingMAD
+	trades     *RollingMAD
+	quiet      bool
+	quietSince int64
+	quietFired bool
+}
+
+// Detector implements DataProcessor interface and flags statistical outliers
+// in price returns, volume deltas and trade count deltas per symbol. It only
+// uses event times, so replaying recorded ticks gives the same results.
+type Detector struct {
+	defaults       Params
+	overrides      map[string]Params
+	states         map[string]*symbolState
+	downstream     []AnomalyProcessor
+	mutex          sync.Mutex
+	processedCount int
+	anomalyCount   int
+}
+
+// NewDetector creates a new Detector
+func NewDetector(cfg Config) *Detector {
+	overrides := make(map[string]Params)
+	for symbol, params := range cfg.Symbols {
+		overrides[strings.ToUpper(symbol)] = params
+	}
+
+	return &Detector{
+		defaults:  cfg.Params.merge(DefaultParams()),
+		overrides: overrides,
+		states:    make(map[string]*symbolState),
+	}
+}
+
+// AddProcessor adds a downstream processor for detected anomalies
+func (d *Detector) AddProcessor(proc AnomalyProcessor) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.downstream = append(d.downstream, proc)
+}
+
+// Process implements the DataProcessor interface
+func (d *Detector) Process(data models.FormattedData) {
+	d.mutex.Lock()
+	anomalies := d.detect(data)
+	d.processedCount++
+	d.anomalyCount += len(anomalies)
+	downstream := d.downstream
+	d.mutex.Unlock()
+
+	for _, anomaly := range anomalies {
+		for _, proc := range downstream {
+			proc.ProcessAnomaly(anomaly)
+		}
+	}
+}
+
+// detect updates the symbol's statistics and returns any anomalies. The
+// caller must hold the mutex.
+func (d *Detector) detect(data models.FormattedData) []models.Anomaly {
+	symbol := strings.ToUpper(data.Symbol)
+	state, ok := d.states[symbol]
+	if !ok {
+		params := d.overrides[symbol].merge(d.defaults)
+		state = &symbolState{
+			params:  params,
+			returns: NewEWMA(params.Alpha),
+			volumes: NewRollingMAD(params.Window),
+			trades:  NewRollingMAD(params.Window),
+		}
+		d.states[symbol] = state
+	}
+
+	prev := state.prev
+	if ok && data.EventTime <= prev.EventTime {
+		return nil
+	}
+	state.prev = data
+	if !ok || prev.LastPrice <= 0 || data.LastPrice <= 0 {
+		return nil
+	}
+
+	ret := math.Log(data.LastPrice / prev.LastPrice)
+	volume := math.Max(data.Volume-prev.Volume, 0)
+	trades := math.Max(float64(data.TradeCount-prev.TradeCount), 0)
+	params := state.params
+
+	var anomalies []models.Anomaly
+	if state.ticks >= params.Warmup {
+		if z := state.returns.ZScore(ret); math.Abs(z) >= params.ReturnThreshold {
+			anomalies = append(anomalies, newAnomaly(data, models.PriceOutlier, ret*100, z,
+				fmt.Sprintf("%s price return %.4f%% is %.1f standard deviations from normal", data.Symbol, ret*100, z)))
+		}
+		if z := state.volumes.ZScore(volume); z >= params.SpikeThreshold {
+			anomalies = append(anomalies, newAnomaly(data, models.VolumeSpike, volume, z,
+				fmt.Sprintf("%s volume delta %.4f is a spike (robust z %.1f)", data.Symbol, volume, z)))
+		}
+		if z := state.trades.ZScore(trades); z >= params.SpikeThreshold {
+			anomalies = append(anomalies, newAnomaly(data, models.TradeSpike, trades, z,
+				fmt.Sprintf("%s trade count delta %.0f is a spike (robust z %.1f)", data.Symbol, trades, z)))
+		}
+		if anomaly, ok := d.checkQuiet(state, data, trades); ok {
+			anomalies = append(anomalies, anomaly)
+		}
+	}
+
+	state.returns.Update(ret)
+	state.volumes.Update(volume)
+	state.trades.Update(trades)
+	state.ticks++
+
+	return anomalies
+}
+
+// checkQuiet flags a symbol once when its trading activity stays well below
+// normal for the quiet duration
+func (d *Detector) checkQuiet(state *symbolState, data models.FormattedData, trades float64) (models.Anomaly, bool) {
+	typical := state.trades.Median()
+	if typical <= 0 || trades > typical*state.params.QuietFraction {
+		state.quiet = false
+		state.quietFired = false
+		return models.Anomaly{}, false
+	}
+
+	if !state.quiet {
+		state.quiet = true
+		state.quietSince = data.EventTime
+	}
+	quietFor := data.EventTime - state.quietSince
+	if state.quietFired || quietFor < state.params.QuietDuration.Milliseconds() {
+		return models.Anomaly{}, false
+	}
+
+	state.quietFired = true
+	return newAnomaly(data, models.QuietPeriod, float64(quietFor), trades/typical,
+		fmt.Sprintf("%s has been quiet for %s (typical trade delta %.1f)", data.Symbol,
+			time.Duration(quietFor)*time.Millisecond, typical)), true
+}
+
+// GetProcessedCount returns the number of processed messages
+func (d *Detector) GetProcessedCount() int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.processedCount
+}
+
+// GetAnomalyCount returns the number of anomalies detected
+func (d *Detector) GetAnomalyCount() int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.anomalyCount
+}
+
+// GetBufferSize returns the current size of the buffer (always 0, anomalies are emitted immediately)
+func (d *Detector) GetBufferSize() int {
+	return 0
+}
+
+// Backtest runs a fresh detector over recorded ticks and returns the
+// anomalies it would have flagged
+func Backtest(cfg Config, ticks []models.FormattedData) []models.Anomaly {
+	detector := NewDetector(cfg)
+	var anomalies []models.Anomaly
+	for _, tick := range ticks {
+		anomalies = append(anomalies, detector.detect(tick)...)
+	}
+	return anomalies
+}
+
+func newAnomaly(data models.FormattedData, kind models.AnomalyKind, value, score float64, message string) models.Anomaly {
+	return models.Anomaly{
+		Symbol:    data.Symbol,
+		Kind:      kind,
+		EventTime: data.EventTime,
+		Value:     value,
+		Score:     score,
+		Message:   message,
+	}
+}
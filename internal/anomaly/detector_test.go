@@ -0,0 +1,124 @@
+package anomaly
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+type mockAnomalyProcessor struct {
+	anomalies []models.Anomaly
+}
+
+func (m *mockAnomalyProcessor) ProcessAnomaly(anomaly models.Anomaly) {
+	m.anomalies = append(m.anomalies, anomaly)
+}
+
+// normalTicks generates a noisy but unremarkable tick series
+func normalTicks(symbol string, n int) []models.FormattedData {
+	rng := rand.New(rand.NewSource(42))
+	ticks := make([]models.FormattedData, n)
+	price, volume, trades := 100.0, 1000.0, 5000
+	for i := range ticks {
+		price *= 1 + (rng.Float64()-0.5)*0.001
+		volume += 1 + rng.Float64()
+		trades += 10 + rng.Intn(5)
+		ticks[i] = models.FormattedData{
+			EventTime:  int64(i) * 1000,
+			Symbol:     symbol,
+			LastPrice:  price,
+			Volume:     volume,
+			TradeCount: trades,
+		}
+	}
+	return ticks
+}
+
+func kinds(anomalies []models.Anomaly) []models.AnomalyKind {
+	var result []models.AnomalyKind
+	for _, a := range anomalies {
+		result = append(result, a.Kind)
+	}
+	return result
+}
+
+func TestDetector_NormalDataIsQuiet(t *testing.T) {
+	anomalies := Backtest(Config{}, normalTicks("BTCUSDT", 500))
+	assert.Empty(t, anomalies)
+}
+
+func TestDetector_PriceOutlier(t *testing.T) {
+	ticks := normalTicks("BTCUSDT", 200)
+	ticks[150].LastPrice *= 1.05
+	for i := 151; i < len(ticks); i++ {
+		ticks[i].LastPrice *= 1.05
+	}
+
+	anomalies := Backtest(Config{}, ticks)
+	require.NotEmpty(t, anomalies)
+	assert.Equal(t, models.PriceOutlier, anomalies[0].Kind)
+	assert.Equal(t, int64(150_000), anomalies[0].EventTime)
+	assert.Greater(t, anomalies[0].Score, 4.0)
+}
+
+func TestDetector_VolumeAndTradeSpike(t *testing.T) {
+	ticks := normalTicks("ETHUSDT", 200)
+	for i := 150; i < len(ticks); i++ {
+		ticks[i].Volume += 500
+		ticks[i].TradeCount += 2000
+	}
+
+	anomalies := Backtest(Config{}, ticks)
+	assert.ElementsMatch(t, []models.AnomalyKind{models.VolumeSpike, models.TradeSpike}, kinds(anomalies))
+}
+
+func TestDetector_QuietPeriod(t *testing.T) {
+	ticks := normalTicks("LTCUSDT", 200)
+	for i := 150; i < len(ticks); i++ {
+		ticks[i].TradeCount = ticks[149].TradeCount
+		ticks[i].Volume = ticks[149].Volume
+	}
+
+	anomalies := Backtest(Config{Params: Params{QuietDuration: 20 * time.Second}}, ticks)
+	require.Len(t, anomalies, 1, "a quiet period should be flagged once")
+	assert.Equal(t, models.QuietPeriod, anomalies[0].Kind)
+	assert.Equal(t, int64(170_000), anomalies[0].EventTime)
+}
+
+func TestDetector_PerSymbolParams(t *testing.T) {
+	ticks := normalTicks("BTCUSDT", 200)
+	for i := 150; i < len(ticks); i++ {
+		ticks[i].Volume += 500
+	}
+
+	cfg := Config{Symbols: map[string]Params{"btcusdt": {SpikeThreshold: 1e9}}}
+	assert.Empty(t, Backtest(cfg, ticks), "per-symbol threshold should suppress the spike")
+}
+
+func TestDetector_Process(t *testing.T) {
+	detector := NewDetector(Config{})
+	sink := &mockAnomalyProcessor{}
+	detector.AddProcessor(sink)
+
+	ticks := normalTicks("BTCUSDT", 200)
+	ticks[150].Volume += 500
+	for i := 151; i < len(ticks); i++ {
+		ticks[i].Volume += 500
+	}
+	for _, tick := range ticks {
+		detector.Process(tick)
+	}
+	// Out-of-order ticks are ignored
+	detector.Process(ticks[10])
+
+	assert.Equal(t, 201, detector.GetProcessedCount())
+	assert.Equal(t, 1, detector.GetAnomalyCount())
+	require.Len(t, sink.anomalies, 1)
+	assert.Equal(t, models.VolumeSpike, sink.anomalies[0].Kind)
+	assert.Equal(t, 0, detector.GetBufferSize())
+}
@@ -0,0 +1,139 @@
+package anomaly
+
+import (
+	"math"
+	"sort"
+)
+
+// EWMA tracks an exponentially weighted mean and variance
+type EWMA struct {
+	alpha    float64
+	count    int
+	mean     float64
+	variance float64
+}
+
+// NewEWMA creates a new EWMA with the given smoothing factor
+func NewEWMA(alpha float64) *EWMA {
+	return &EWMA{alpha: alpha}
+}
+
+// Update adds an observation
+func (e *EWMA) Update(x float64) {
+	e.count++
+	if e.count == 1 {
+		e.mean = x
+		return
+	}
+	diff := x - e.mean
+	incr := e.alpha * diff
+	e.mean += incr
+	e.variance = (1 - e.alpha) * (e.variance + diff*incr)
+}
+
+// Mean returns the current mean
+func (e *EWMA) Mean() float64 {
+	return e.mean
+}
+
+// StdDev returns the current standard deviation
+func (e *EWMA) StdDev() float64 {
+	return math.Sqrt(e.variance)
+}
+
+// ZScore returns how many standard deviations x is from the mean, or 0 when
+// there is no variance yet
+func (e *EWMA) ZScore(x float64) float64 {
+	sd := e.StdDev()
+	if sd == 0 {
+		return 0
+	}
+	return (x - e.mean) / sd
+}
+
+// Count returns the number of observations
+func (e *EWMA) Count() int {
+	return e.count
+}
+
+// madScale makes the median absolute deviation a consistent estimator of the
+// standard deviation for normally distributed data
+const madScale = 1.4826
+
+// RollingMAD tracks the median and median absolute deviation over a window
+type RollingMAD struct {
+	values []float64
+	next   int
+	count  int
+	sorted []float64
+}
+
+// NewRollingMAD creates a new RollingMAD over the given window size
+func NewRollingMAD(window int) *RollingMAD {
+	return &RollingMAD{values: make([]float64, window), sorted: make([]float64, 0, window)}
+}
+
+// Update adds an observation, evicting the oldest once the window is full
+func (r *RollingMAD) Update(x float64) {
+	if r.count == len(r.values) {
+		r.remove(r.values[r.next])
+	} else {
+		r.count++
+	}
+	r.values[r.next] = x
+	r.next = (r.next + 1) % len(r.values)
+
+	i := sort.SearchFloat64s(r.sorted, x)
+	r.sorted = append(r.sorted, 0)
+	copy(r.sorted[i+1:], r.sorted[i:])
+	r.sorted[i] = x
+}
+
+func (r *RollingMAD) remove(x float64) {
+	i := sort.SearchFloat64s(r.sorted, x)
+	r.sorted = append(r.sorted[:i], r.sorted[i+1:]...)
+}
+
+// Median returns the median of the window
+func (r *RollingMAD) Median() float64 {
+	return median(r.sorted)
+}
+
+// MAD returns the median absolute deviation of the window
+func (r *RollingMAD) MAD() float64 {
+	if len(r.sorted) == 0 {
+		return 0
+	}
+	m := r.Median()
+	deviations := make([]float64, len(r.sorted))
+	for i, v := range r.sorted {
+		deviations[i] = math.Abs(v - m)
+	}
+	sort.Float64s(deviations)
+	return median(deviations)
+}
+
+// ZScore returns the robust z-score of x, or 0 when the window has no spread
+func (r *RollingMAD) ZScore(x float64) float64 {
+	mad := r.MAD()
+	if mad == 0 {
+		return 0
+	}
+	return (x - r.Median()) / (madScale * mad)
+}
+
+// Count returns the number of observations in the window
+func (r *RollingMAD) Count() int {
+	return r.count
+}
+
+func median(sorted []float64) float64 {
+	n := len(sorted)
+	if n == 0 {
+		return 0
+	}
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
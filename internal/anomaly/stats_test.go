@@ -0,0 +1,48 @@
+package anomaly
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEWMA(t *testing.T) {
+	ewma := NewEWMA(0.5)
+	assert.Equal(t, 0.0, ewma.ZScore(1), "no variance before two observations")
+
+	ewma.Update(1)
+	assert.Equal(t, 1.0, ewma.Mean())
+
+	ewma.Update(3)
+	assert.Equal(t, 2.0, ewma.Mean())
+	// variance = (1 - 0.5) * (0 + 2 * 1)
+	assert.Equal(t, 1.0, ewma.StdDev())
+	assert.Equal(t, 3.0, ewma.ZScore(5))
+	assert.Equal(t, 2, ewma.Count())
+}
+
+func TestRollingMAD(t *testing.T) {
+	mad := NewRollingMAD(5)
+	for _, v := range []float64{1, 2, 3, 4, 100} {
+		mad.Update(v)
+	}
+	assert.Equal(t, 3.0, mad.Median())
+	// deviations: 2, 1, 0, 1, 97
+	assert.Equal(t, 1.0, mad.MAD())
+	assert.InDelta(t, 97/1.4826, mad.ZScore(100), 1e-9)
+
+	// The window evicts the oldest values
+	for _, v := range []float64{5, 6, 7, 8} {
+		mad.Update(v)
+	}
+	assert.Equal(t, 5, mad.Count())
+	assert.Equal(t, 7.0, mad.Median())
+}
+
+func TestRollingMAD_NoSpread(t *testing.T) {
+	mad := NewRollingMAD(3)
+	for i := 0; i < 3; i++ {
+		mad.Update(2)
+	}
+	assert.Equal(t, 0.0, mad.ZScore(10))
+}
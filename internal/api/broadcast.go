@@ -0,0 +1,20 @@
+package api
+
+import (
+	"log"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/broadcast"
+)
+
+// RegisterBroadcast exposes the tick stream over WebSocket and server-sent
+// events. The hub is closed when the server shuts down so that streaming
+// clients do not hold up Shutdown.
+func (s *Server) RegisterBroadcast(hub *broadcast.Hub) {
+	s.mux.HandleFunc("GET /api/v1/stream/ws", hub.ServeWS)
+	s.mux.HandleFunc("GET /api/v1/stream/sse", hub.ServeSSE)
+	s.httpServer.RegisterOnShutdown(func() {
+		if err := hub.Close(); err != nil {
+			log.Printf("Error closing broadcast hub: %v", err)
+		}
+	})
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/broadcast"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/latest"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+func TestRegisterBroadcast(t *testing.T) {
+	store := latest.NewStore()
+	store.Process(models.FormattedData{Symbol: "BTCUSDT", EventTime: 1000, LastPrice: 60000})
+
+	server := NewServer(Config{})
+	server.RegisterBroadcast(broadcast.NewHub(broadcast.Config{}, store))
+	httpServer := httptest.NewServer(server.Handler())
+	defer httpServer.Close()
+
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http")+"/api/v1/stream/ws", nil)
+	require.NoError(t, err)
+	defer ws.Close()
+	_, message, err := ws.ReadMessage()
+	require.NoError(t, err)
+	assert.Contains(t, string(message), `"type":"snapshot"`)
+
+	resp, err := http.Get(httpServer.URL + "/api/v1/stream/sse")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "event: snapshot\n", line)
+}
+
+func TestRegisterBroadcast_ShutdownClosesStreams(t *testing.T) {
+	server := NewServer(Config{})
+	server.RegisterBroadcast(broadcast.NewHub(broadcast.Config{}, latest.NewStore()))
+	httpServer := httptest.NewUnstartedServer(nil)
+	httpServer.Config = server.httpServer
+	httpServer.Start()
+	defer httpServer.Close()
+
+	resp, err := http.Get(httpServer.URL + "/api/v1/stream/sse")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	_, err = bufio.NewReader(resp.Body).ReadString('\n')
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	assert.NoError(t, server.Shutdown(ctx))
+}
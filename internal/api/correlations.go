@@ -0,0 +1,26 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/correlation"
+)
+
+// RegisterCorrelations exposes the rolling correlation matrix and betas. The
+// optional window query parameter selects the window, defaulting to the first
+// configured one.
+func (s *Server) RegisterCorrelations(tracker *correlation.Tracker) {
+	s.mux.HandleFunc("GET /api/v1/correlations", func(w http.ResponseWriter, r *http.Request) {
+		window := r.URL.Query().Get("window")
+		if window == "" {
+			window = tracker.Windows()[0]
+		}
+
+		matrix, err := tracker.Snapshot(window)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, matrix)
+	})
+}
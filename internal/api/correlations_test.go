@@ -0,0 +1,43 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/correlation"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+func TestRegisterCorrelations(t *testing.T) {
+	tracker, err := correlation.NewTracker(correlation.Config{SampleInterval: time.Second, Windows: []string{"10s"}})
+	require.NoError(t, err)
+	prices := []float64{100, 101, 99, 102, 103, 101}
+	for i, price := range prices {
+		eventTime := int64(i+1) * 1000
+		tracker.Process(models.FormattedData{EventTime: eventTime, Symbol: "BTCUSDT", LastPrice: price})
+		tracker.Process(models.FormattedData{EventTime: eventTime, Symbol: "ETHUSDT", LastPrice: price * 2})
+	}
+
+	server := NewServer(Config{})
+	server.RegisterCorrelations(tracker)
+
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/correlations", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var matrix models.CorrelationMatrix
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &matrix))
+	assert.Equal(t, "10s", matrix.Window)
+	assert.InDelta(t, 1.0, matrix.Correlations["BTCUSDT"]["ETHUSDT"], 1e-9)
+	assert.InDelta(t, 1.0, matrix.Betas["ETHUSDT"], 1e-9)
+
+	rec = httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/correlations?window=1d", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,423 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	// Time zones resolve even where the system has no zoneinfo
+	_ "time/tzdata"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+const (
+	defaultHistoryLimit    = 500
+	defaultHistoryMaxLimit = 5000
+	defaultQueryTimeout    = 10 * time.Second
+	defaultCandleInterval  = "1m"
+)
+
+// HistoryConfig holds the limits of the tick and candle history endpoints
+type HistoryConfig struct {
+	// DefaultLimit is the page size when the limit parameter is missing
+	DefaultLimit int `mapstructure:"default_limit"`
+	// MaxLimit is the largest page size a client may ask for
+	MaxLimit int `mapstructure:"max_limit"`
+	// MaxRange is the longest time range a query may span; 0 allows any
+	MaxRange time.Duration `mapstructure:"max_range"`
+	// QueryTimeout bounds each database query
+	QueryTimeout time.Duration `mapstructure:"query_timeout"`
+}
+
+// WithDefaults returns the config with unset limits replaced by their
+// defaults
+func (cfg HistoryConfig) WithDefaults() HistoryConfig {
+	if cfg.DefaultLimit <= 0 {
+		cfg.DefaultLimit = defaultHistoryLimit
+	}
+	if cfg.MaxLimit <= 0 {
+		cfg.MaxLimit = defaultHistoryMaxLimit
+	}
+	if cfg.QueryTimeout <= 0 {
+		cfg.QueryTimeout = defaultQueryTimeout
+	}
+	return cfg
+}
+
+// historyTick is a ticker_data row with its event time formatted in the
+// requested time zone
+type historyTick struct {
+	EventTime   int64   `json:"event_time"`
+	Time        string  `json:"time"`
+	LastPrice   float64 `json:"last_price"`
+	PriceChange float64 `json:"price_change"`
+	HighPrice   float64 `json:"high_price"`
+	LowPrice    float64 `json:"low_price"`
+	Volume      float64 `json:"volume"`
+	QuoteVolume float64 `json:"quote_volume"`
+	OpenTime    int64   `json:"open_time"`
+	CloseTime   int64   `json:"close_time"`
+	TradeCount  int     `json:"trade_count"`
+	Latency     int64   `json:"latency"`
+}
+
+var historyTickColumns = []string{
+	"event_time", "time", "last_price", "price_change", "high_price", "low_price",
+	"volume", "quote_volume", "open_time", "close_time", "trade_count", "latency",
+}
+
+func (t historyTick) record() []string {
+	return []string{
+		strconv.FormatInt(t.EventTime, 10), t.Time, formatFloat(t.LastPrice), formatFloat(t.PriceChange),
+		formatFloat(t.HighPrice), formatFloat(t.LowPrice), formatFloat(t.Volume), formatFloat(t.QuoteVolume),
+		strconv.FormatInt(t.OpenTime, 10), strconv.FormatInt(t.CloseTime, 10),
+		strconv.Itoa(t.TradeCount), strconv.FormatInt(t.Latency, 10),
+	}
+}
+
+// historyCandle is a stored candle with its open time formatted in the
+// requested time zone
+type historyCandle struct {
+	models.Candle
+	Time string `json:"time"`
+}
+
+var historyCandleColumns = []string{
+	"open_time", "time", "close_time", "open", "high", "low", "close", "volume", "quote_volume", "trade_count",
+}
+
+func (c historyCandle) record() []string {
+	return []string{
+		strconv.FormatInt(c.OpenTime, 10), c.Time, strconv.FormatInt(c.CloseTime, 10),
+		formatFloat(c.Open), formatFloat(c.High), formatFloat(c.Low), formatFloat(c.Close),
+		formatFloat(c.Volume), formatFloat(c.QuoteVolume), strconv.Itoa(c.TradeCount),
+	}
+}
+
+// historyPage is the JSON response of the history endpoints
+type historyPage struct {
+	Symbol     string      `json:"symbol"`
+	Interval   string      `json:"interval,omitempty"`
+	Data       interface{} `json:"data"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+// historyQuery is a validated page request over [from, to) in milliseconds
+type historyQuery struct {
+	symbol   string
+	from     int64
+	to       int64
+	limit    int
+	location *time.Location
+	csv      bool
+}
+
+// RegisterHistory exposes the stored ticks and candles of a symbol:
+//
+//	GET /api/v1/symbols/{symbol}/ticks?from=&to=&limit=&cursor=&tz=&format=
+//	GET /api/v1/symbols/{symbol}/candles?interval=&from=&to=&limit=&cursor=&tz=&format=
+//
+// from and to are epoch milliseconds, RFC 3339 times or local times and
+// dates in tz (UTC by default). Pages are in ascending time order; the
+// cursor of the next page is returned as next_cursor and in a Link header.
+// format=csv (or Accept: text/csv) returns CSV instead of JSON.
+func (s *Server) RegisterHistory(db *sql.DB, cfg HistoryConfig) {
+	cfg = cfg.WithDefaults()
+
+	s.mux.HandleFunc("GET /api/v1/symbols/{symbol}/ticks", func(w http.ResponseWriter, r *http.Request) {
+		query, err := parseHistoryQuery(r, cfg)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), cfg.QueryTimeout)
+		defer cancel()
+
+		stored, err := QueryTicks(ctx, db, query.symbol, query.from, query.to, query.limit+1)
+		if err != nil {
+			writeQueryError(w, err)
+			return
+		}
+
+		var ticks []historyTick
+		for _, data := range stored {
+			ticks = append(ticks, historyTick{
+				EventTime:   data.EventTime,
+				Time:        formatTime(data.EventTime, query.location),
+				LastPrice:   data.LastPrice,
+				PriceChange: data.PriceChange,
+				HighPrice:   data.HighPrice,
+				LowPrice:    data.LowPrice,
+				Volume:      data.Volume,
+				QuoteVolume: data.QuoteVolume,
+				OpenTime:    data.OpenTime,
+				CloseTime:   data.CloseTime,
+				TradeCount:  data.TradeCount,
+				Latency:     data.Latency,
+			})
+		}
+
+		var next string
+		if len(ticks) > query.limit {
+			ticks = ticks[:query.limit]
+			next = encodeCursor(ticks[len(ticks)-1].EventTime)
+		}
+
+		records := make([][]string, len(ticks))
+		for i, tick := range ticks {
+			records[i] = tick.record()
+		}
+		if ticks == nil {
+			ticks = []historyTick{}
+		}
+		writeHistory(w, r, query, historyPage{Symbol: query.symbol, Data: ticks, NextCursor: next}, historyTickColumns, records)
+	})
+
+	s.mux.HandleFunc("GET /api/v1/symbols/{symbol}/candles", func(w http.ResponseWriter, r *http.Request) {
+		query, err := parseHistoryQuery(r, cfg)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		interval := r.URL.Query().Get("interval")
+		if interval == "" {
+			interval = defaultCandleInterval
+		}
+		if _, err := time.ParseDuration(interval); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid interval "+strconv.Quote(interval))
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), cfg.QueryTimeout)
+		defer cancel()
+
+		rows, err := db.QueryContext(ctx, `SELECT open_time, close_time, open, high, low, close, volume, quote_volume, trade_count
+        FROM candles
+        WHERE symbol = $1 AND interval = $2 AND open_time >= $3 AND open_time < $4
+        ORDER BY open_time
+        LIMIT $5`, query.symbol, interval, query.from, query.to, query.limit+1)
+		if err != nil {
+			writeQueryError(w, err)
+			return
+		}
+		defer rows.Close()
+
+		var candles []historyCandle
+		for rows.Next() {
+			candle := historyCandle{Candle: models.Candle{Symbol: query.symbol, Interval: interval}}
+			if err := rows.Scan(&candle.OpenTime, &candle.CloseTime, &candle.Open, &candle.High, &candle.Low,
+				&candle.Close, &candle.Volume, &candle.QuoteVolume, &candle.TradeCount); err != nil {
+				writeQueryError(w, err)
+				return
+			}
+			candle.Time = formatTime(candle.OpenTime, query.location)
+			candles = append(candles, candle)
+		}
+		if err := rows.Err(); err != nil {
+			writeQueryError(w, err)
+			return
+		}
+
+		var next string
+		if len(candles) > query.limit {
+			candles = candles[:query.limit]
+			next = encodeCursor(candles[len(candles)-1].OpenTime)
+		}
+
+		records := make([][]string, len(candles))
+		for i, candle := range candles {
+			records[i] = candle.record()
+		}
+		if candles == nil {
+			candles = []historyCandle{}
+		}
+		page := historyPage{Symbol: query.symbol, Interval: interval, Data: candles, NextCursor: next}
+		writeHistory(w, r, query, page, historyCandleColumns, records)
+	})
+}
+
+// QueryTicks returns up to limit ticks of symbol stored in ticker_data with
+// event times in [from, to), in ascending time order
+func QueryTicks(ctx context.Context, db *sql.DB, symbol string, from, to int64, limit int) ([]models.FormattedData, error) {
+	rows, err := db.QueryContext(ctx, `SELECT event_time, last_price, price_change, high_price, low_price,
+            volume, quote_volume, open_time, close_time, trade_count, latency
+        FROM ticker_data
+        WHERE symbol = $1 AND event_time >= $2 AND event_time < $3
+        ORDER BY event_time
+        LIMIT $4`, symbol, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ticks []models.FormattedData
+	for rows.Next() {
+		tick := models.FormattedData{Symbol: symbol}
+		if err := rows.Scan(&tick.EventTime, &tick.LastPrice, &tick.PriceChange, &tick.HighPrice, &tick.LowPrice,
+			&tick.Volume, &tick.QuoteVolume, &tick.OpenTime, &tick.CloseTime, &tick.TradeCount, &tick.Latency); err != nil {
+			return nil, err
+		}
+		ticks = append(ticks, tick)
+	}
+	return ticks, rows.Err()
+}
+
+// parseHistoryQuery validates the common parameters of the history
+// endpoints. A cursor moves from past the last row of the previous page.
+func parseHistoryQuery(r *http.Request, cfg HistoryConfig) (historyQuery, error) {
+	params := r.URL.Query()
+	query := historyQuery{
+		symbol: strings.ToUpper(r.PathValue("symbol")),
+		limit:  cfg.DefaultLimit,
+		csv:    params.Get("format") == "csv" || (params.Get("format") == "" && strings.Contains(r.Header.Get("Accept"), "text/csv")),
+	}
+	if format := params.Get("format"); format != "" && format != "csv" && format != "json" {
+		return query, fmt.Errorf("unknown format %q, use json or csv", format)
+	}
+
+	query.location = time.UTC
+	if tz := params.Get("tz"); tz != "" {
+		location, err := time.LoadLocation(tz)
+		if err != nil {
+			return query, fmt.Errorf("unknown time zone %q", tz)
+		}
+		query.location = location
+	}
+
+	if limit := params.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			return query, fmt.Errorf("invalid limit %q", limit)
+		}
+		if n > cfg.MaxLimit {
+			return query, fmt.Errorf("limit %d exceeds the maximum of %d", n, cfg.MaxLimit)
+		}
+		query.limit = n
+	}
+
+	query.to = time.Now().UnixMilli()
+	if to := params.Get("to"); to != "" {
+		t, err := parseHistoryTime(to, query.location)
+		if err != nil {
+			return query, fmt.Errorf("invalid to: %w", err)
+		}
+		query.to = t
+	}
+	if cfg.MaxRange > 0 {
+		query.from = query.to - cfg.MaxRange.Milliseconds()
+	}
+	if from := params.Get("from"); from != "" {
+		t, err := parseHistoryTime(from, query.location)
+		if err != nil {
+			return query, fmt.Errorf("invalid from: %w", err)
+		}
+		query.from = t
+	}
+	if query.from >= query.to {
+		return query, errors.New("from must be before to")
+	}
+	if cfg.MaxRange > 0 && query.to-query.from > cfg.MaxRange.Milliseconds() {
+		return query, fmt.Errorf("time range exceeds the maximum of %s", cfg.MaxRange)
+	}
+
+	if cursor := params.Get("cursor"); cursor != "" {
+		after, err := decodeCursor(cursor)
+		if err != nil {
+			return query, errors.New("invalid cursor")
+		}
+		query.from = max(query.from, after+1)
+	}
+
+	return query, nil
+}
+
+// historyTimeLayouts are the accepted forms of from and to besides epoch
+// milliseconds. Layouts without an offset are read in the query's time zone.
+var historyTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// parseHistoryTime parses a from or to parameter into epoch milliseconds
+func parseHistoryTime(value string, location *time.Location) (int64, error) {
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return ms, nil
+	}
+	for _, layout := range historyTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, location); err == nil {
+			return t.UnixMilli(), nil
+		}
+	}
+	return 0, fmt.Errorf("%q is not epoch milliseconds, RFC 3339 or a date", value)
+}
+
+func formatTime(ms int64, location *time.Location) string {
+	return time.UnixMilli(ms).In(location).Format(time.RFC3339Nano)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// encodeCursor returns an opaque cursor resuming after the given time
+func encodeCursor(after int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(after, 10)))
+}
+
+func decodeCursor(cursor string) (int64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(string(raw), 10, 64)
+}
+
+// writeHistory writes a page as JSON or CSV, linking to the next page
+func writeHistory(w http.ResponseWriter, r *http.Request, query historyQuery, page historyPage, columns []string, records [][]string) {
+	if page.NextCursor != "" {
+		next := url.URL{Path: r.URL.Path, RawQuery: r.URL.RawQuery}
+		params := next.Query()
+		params.Set("cursor", page.NextCursor)
+		next.RawQuery = params.Encode()
+		w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, next.String()))
+		w.Header().Set("X-Next-Cursor", page.NextCursor)
+	}
+
+	if !query.csv {
+		writeJSON(w, http.StatusOK, page)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.WriteHeader(http.StatusOK)
+	writer := csv.NewWriter(w)
+	if err := writer.Write(columns); err != nil {
+		log.Printf("Error writing CSV response: %v", err)
+		return
+	}
+	if err := writer.WriteAll(records); err != nil {
+		log.Printf("Error writing CSV response: %v", err)
+	}
+}
+
+// writeQueryError reports a failed query, distinguishing timeouts
+func writeQueryError(w http.ResponseWriter, err error) {
+	if errors.Is(err, context.DeadlineExceeded) {
+		writeError(w, http.StatusGatewayTimeout, "query timed out")
+		return
+	}
+	log.Printf("Error querying history: %v", err)
+	writeError(w, http.StatusInternalServerError, "query failed")
+}
@@ -0,0 +1,231 @@
+package api
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+var tickColumns = []string{
+	"event_time", "last_price", "price_change", "high_price", "low_price",
+	"volume", "quote_volume", "open_time", "close_time", "trade_count", "latency",
+}
+
+func tickRows(eventTimes ...int64) *sqlmock.Rows {
+	rows := sqlmock.NewRows(tickColumns)
+	for _, eventTime := range eventTimes {
+		rows.AddRow(eventTime, 34000.5, 100, 34500, 33500, 10, 340005, eventTime-86400000, eventTime, 1000, 50)
+	}
+	return rows
+}
+
+func newHistoryServer(t *testing.T, cfg HistoryConfig) (*Server, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	server := NewServer(Config{})
+	server.RegisterHistory(db, cfg)
+	return server, mock
+}
+
+func get(server *Server, target string, header ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRegisterHistory_TicksPaginates(t *testing.T) {
+	server, mock := newHistoryServer(t, HistoryConfig{})
+
+	// One extra row tells there is a next page
+	mock.ExpectQuery(`SELECT event_time, .* FROM ticker_data`).
+		WithArgs("BTCUSDT", int64(1000), int64(9000), 3).
+		WillReturnRows(tickRows(1000, 2000, 3000))
+	rec := get(server, "/api/v1/symbols/btcusdt/ticks?from=1000&to=9000&limit=2")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var page struct {
+		Symbol     string        `json:"symbol"`
+		Data       []historyTick `json:"data"`
+		NextCursor string        `json:"next_cursor"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	assert.Equal(t, "BTCUSDT", page.Symbol)
+	require.Len(t, page.Data, 2)
+	assert.Equal(t, int64(2000), page.Data[1].EventTime)
+	assert.Equal(t, "1970-01-01T00:00:02Z", page.Data[1].Time)
+	assert.Equal(t, 34000.5, page.Data[1].LastPrice)
+	require.NotEmpty(t, page.NextCursor)
+	assert.Equal(t, page.NextCursor, rec.Header().Get("X-Next-Cursor"))
+	assert.Contains(t, rec.Header().Get("Link"), "cursor="+page.NextCursor)
+	assert.Contains(t, rec.Header().Get("Link"), `rel="next"`)
+
+	// The next page starts after the last returned tick
+	mock.ExpectQuery(`SELECT event_time, .* FROM ticker_data`).
+		WithArgs("BTCUSDT", int64(2001), int64(9000), 3).
+		WillReturnRows(tickRows(3000))
+	rec = get(server, "/api/v1/symbols/btcusdt/ticks?from=1000&to=9000&limit=2&cursor="+page.NextCursor)
+	require.Equal(t, http.StatusOK, rec.Code)
+	page.NextCursor = ""
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	assert.Len(t, page.Data, 1)
+	assert.Empty(t, page.NextCursor)
+	assert.Empty(t, rec.Header().Get("Link"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRegisterHistory_TimeZones(t *testing.T) {
+	server, mock := newHistoryServer(t, HistoryConfig{})
+
+	// Local times are read in tz and returned in it
+	from := time.Date(2024, 1, 2, 0, 0, 0, 0, time.FixedZone("AEDT", 11*3600)).UnixMilli()
+	to := time.Date(2024, 1, 2, 12, 30, 0, 0, time.FixedZone("AEDT", 11*3600)).UnixMilli()
+	mock.ExpectQuery(`FROM ticker_data`).
+		WithArgs("BTCUSDT", from, to, defaultHistoryLimit+1).
+		WillReturnRows(tickRows(from))
+	rec := get(server, "/api/v1/symbols/BTCUSDT/ticks?from=2024-01-02&to=2024-01-02T12:30&tz=Australia/Sydney")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), `"time":"2024-01-02T00:00:00+11:00"`)
+
+	// RFC 3339 times carry their own offset
+	mock.ExpectQuery(`FROM ticker_data`).
+		WithArgs("BTCUSDT", from, to, defaultHistoryLimit+1).
+		WillReturnRows(tickRows())
+	rec = get(server, "/api/v1/symbols/BTCUSDT/ticks?from=2024-01-01T13:00:00Z&to=2024-01-02T12:30:00%2B11:00")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"symbol": "BTCUSDT", "data": []}`, rec.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRegisterHistory_CandlesCSV(t *testing.T) {
+	server, mock := newHistoryServer(t, HistoryConfig{})
+
+	mock.ExpectQuery(`SELECT open_time, .* FROM candles`).
+		WithArgs("ETHUSDT", "5m", int64(0), int64(900000), defaultHistoryLimit+1).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"open_time", "close_time", "open", "high", "low", "close", "volume", "quote_volume", "trade_count",
+		}).AddRow(0, 299999, 3000, 3010, 2990, 3005.5, 12, 36000, 40))
+	rec := get(server, "/api/v1/symbols/ethusdt/candles?interval=5m&from=0&to=900000", "Accept", "text/csv")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "text/csv", rec.Header().Get("Content-Type"))
+
+	records, err := csv.NewReader(strings.NewReader(rec.Body.String())).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, historyCandleColumns, records[0])
+	assert.Equal(t, []string{"0", "1970-01-01T00:00:00Z", "299999", "3000", "3010", "2990", "3005.5", "12", "36000", "40"}, records[1])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRegisterHistory_CandlesJSON(t *testing.T) {
+	server, mock := newHistoryServer(t, HistoryConfig{})
+
+	mock.ExpectQuery(`FROM candles`).
+		WithArgs("ETHUSDT", defaultCandleInterval, int64(0), int64(900000), defaultHistoryLimit+1).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"open_time", "close_time", "open", "high", "low", "close", "volume", "quote_volume", "trade_count",
+		}).AddRow(60000, 119999, 3000, 3010, 2990, 3005.5, 12, 36000, 40))
+	rec := get(server, "/api/v1/symbols/ethusdt/candles?from=0&to=900000&format=json")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), `"interval":"1m"`)
+	assert.Contains(t, rec.Body.String(), `"close":3005.5`)
+	assert.Contains(t, rec.Body.String(), `"time":"1970-01-01T00:01:00Z"`)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRegisterHistory_Limits(t *testing.T) {
+	server, _ := newHistoryServer(t, HistoryConfig{MaxLimit: 100, MaxRange: time.Hour})
+
+	for _, target := range []string{
+		"/api/v1/symbols/btcusdt/ticks?limit=101",
+		"/api/v1/symbols/btcusdt/ticks?limit=-1",
+		"/api/v1/symbols/btcusdt/ticks?from=0&to=7200000",
+		"/api/v1/symbols/btcusdt/ticks?from=2000&to=1000",
+		"/api/v1/symbols/btcusdt/ticks?from=yesterday",
+		"/api/v1/symbols/btcusdt/ticks?tz=Mars/Olympus",
+		"/api/v1/symbols/btcusdt/ticks?cursor=!!",
+		"/api/v1/symbols/btcusdt/ticks?format=xml",
+		"/api/v1/symbols/btcusdt/candles?interval=1x",
+	} {
+		rec := get(server, target)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, target)
+	}
+}
+
+func TestRegisterHistory_DefaultRange(t *testing.T) {
+	server, mock := newHistoryServer(t, HistoryConfig{MaxRange: time.Hour})
+
+	mock.ExpectQuery(`FROM ticker_data`).
+		WithArgs("BTCUSDT", int64(3600000), int64(7200000), defaultHistoryLimit+1).
+		WillReturnRows(tickRows())
+	rec := get(server, "/api/v1/symbols/btcusdt/ticks?to=7200000")
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRegisterHistory_QueryErrors(t *testing.T) {
+	server, mock := newHistoryServer(t, HistoryConfig{})
+
+	mock.ExpectQuery(`FROM ticker_data`).WillReturnError(context.DeadlineExceeded)
+	rec := get(server, "/api/v1/symbols/btcusdt/ticks")
+	assert.Equal(t, http.StatusGatewayTimeout, rec.Code)
+
+	mock.ExpectQuery(`FROM ticker_data`).WillReturnError(assert.AnError)
+	rec = get(server, "/api/v1/symbols/btcusdt/ticks")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.NotContains(t, rec.Body.String(), assert.AnError.Error())
+}
+
+func TestParseHistoryTime(t *testing.T) {
+	sydney, err := time.LoadLocation("Australia/Sydney")
+	require.NoError(t, err)
+
+	ms, err := parseHistoryTime("1704153600000", time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1704153600000), ms)
+
+	ms, err = parseHistoryTime("2024-07-01T10:00:00", sydney)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC).UnixMilli(), ms)
+
+	_, err = parseHistoryTime("01/07/2024", time.UTC)
+	assert.Error(t, err)
+}
+
+func TestQueryTicks(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT event_time, .* FROM ticker_data`).
+		WithArgs("BTCUSDT", 1000, 5000, 2).
+		WillReturnRows(tickRows(1000, 2000))
+	ticks, err := QueryTicks(context.Background(), db, "BTCUSDT", 1000, 5000, 2)
+	require.NoError(t, err)
+	require.Len(t, ticks, 2)
+	assert.Equal(t, models.FormattedData{
+		EventTime: 2000, Symbol: "BTCUSDT", LastPrice: 34000.5, PriceChange: 100, HighPrice: 34500, LowPrice: 33500,
+		Volume: 10, QuoteVolume: 340005, OpenTime: 2000 - 86400000, CloseTime: 2000, TradeCount: 1000, Latency: 50,
+	}, ticks[1])
+
+	mock.ExpectQuery(`FROM ticker_data`).WillReturnError(assert.AnError)
+	_, err = QueryTicks(context.Background(), db, "BTCUSDT", 1000, 5000, 2)
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
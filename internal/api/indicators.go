@@ -0,0 +1,32 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/indicators"
+)
+
+// RegisterIndicators exposes the latest indicator values of a symbol. The
+// optional interval query parameter selects a single interval.
+func (s *Server) RegisterIndicators(engine *indicators.Engine) {
+	s.mux.HandleFunc("GET /api/v1/indicators/{symbol}", func(w http.ResponseWriter, r *http.Request) {
+		symbol := r.PathValue("symbol")
+
+		if interval := r.URL.Query().Get("interval"); interval != "" {
+			values, ok := engine.Latest(symbol, interval)
+			if !ok {
+				writeError(w, http.StatusNotFound, "no indicators for "+symbol+" at interval "+interval)
+				return
+			}
+			writeJSON(w, http.StatusOK, values)
+			return
+		}
+
+		values := engine.LatestForSymbol(symbol)
+		if len(values) == 0 {
+			writeError(w, http.StatusNotFound, "no indicators for "+symbol)
+			return
+		}
+		writeJSON(w, http.StatusOK, values)
+	})
+}
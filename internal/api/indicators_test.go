@@ -0,0 +1,45 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/indicators"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+func TestRegisterIndicators(t *testing.T) {
+	engine := indicators.NewEngine(indicators.Config{})
+	engine.ProcessCandle(models.Candle{Symbol: "BTCUSDT", Interval: "1m", High: 11, Low: 9, Close: 10, Volume: 1})
+	engine.ProcessCandle(models.Candle{Symbol: "BTCUSDT", Interval: "5m", High: 11, Low: 9, Close: 10, Volume: 1})
+
+	server := NewServer(Config{})
+	server.RegisterIndicators(engine)
+
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/indicators/btcusdt", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var all []models.IndicatorValues
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &all))
+	assert.Len(t, all, 2)
+
+	rec = httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/indicators/btcusdt?interval=5m", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var single models.IndicatorValues
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &single))
+	assert.Equal(t, "5m", single.Interval)
+	require.NotNil(t, single.VWAP)
+	assert.Equal(t, 10.0, *single.VWAP)
+
+	rec = httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/indicators/ethusdt", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
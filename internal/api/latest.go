@@ -0,0 +1,26 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/latest"
+)
+
+// RegisterLatest exposes the latest tick of every symbol and of a single
+// symbol from memory
+func (s *Server) RegisterLatest(store *latest.Store) {
+	s.mux.HandleFunc("GET /api/v1/latest", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, store.All(time.Now()))
+	})
+
+	s.mux.HandleFunc("GET /api/v1/latest/{symbol}", func(w http.ResponseWriter, r *http.Request) {
+		symbol := r.PathValue("symbol")
+		tick, ok := store.Latest(symbol, time.Now())
+		if !ok {
+			writeError(w, http.StatusNotFound, "no ticks for "+symbol)
+			return
+		}
+		writeJSON(w, http.StatusOK, tick)
+	})
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/latest"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+func TestRegisterLatest(t *testing.T) {
+	store := latest.NewStore()
+	store.Process(models.FormattedData{Symbol: "BTCUSDT", EventTime: 1000, LastPrice: 60000})
+	store.Process(models.FormattedData{Symbol: "ETHUSDT", EventTime: 1000, LastPrice: 3000})
+
+	server := NewServer(Config{})
+	server.RegisterLatest(store)
+
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/latest", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var all []models.LatestTick
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &all))
+	assert.Len(t, all, 2)
+
+	rec = httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/latest/ethusdt", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var single models.LatestTick
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &single))
+	assert.Equal(t, "ETHUSDT", single.Symbol)
+	assert.Equal(t, 3000.0, single.LastPrice)
+	assert.Contains(t, rec.Body.String(), `"last_update_age_ms"`)
+
+	rec = httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/latest/ltcusdt", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	// The empty store is an empty list rather than null
+	server = NewServer(Config{})
+	server.RegisterLatest(latest.NewStore())
+	rec = httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/latest", nil))
+	assert.JSONEq(t, `[]`, rec.Body.String())
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/paper"
+)
+
+// RegisterPaperTrading exposes order entry, cancellation, the order list and
+// the account of a paper trading exchange
+func (s *Server) RegisterPaperTrading(exchange *paper.Exchange) {
+	s.mux.HandleFunc("POST /api/v1/paper/orders", func(w http.ResponseWriter, r *http.Request) {
+		var req models.OrderRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid order request: "+err.Error())
+			return
+		}
+
+		order, err := exchange.PlaceOrder(req)
+		if err != nil {
+			writeError(w, paperErrorStatus(err), err.Error())
+			return
+		}
+		writeJSON(w, http.StatusCreated, order)
+	})
+
+	s.mux.HandleFunc("GET /api/v1/paper/orders", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, exchange.Orders())
+	})
+
+	s.mux.HandleFunc("DELETE /api/v1/paper/orders/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid order id")
+			return
+		}
+
+		order, err := exchange.CancelOrder(id)
+		if err != nil {
+			writeError(w, paperErrorStatus(err), err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, order)
+	})
+
+	s.mux.HandleFunc("GET /api/v1/paper/account", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, exchange.Account())
+	})
+}
+
+func paperErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, paper.ErrUnknownOrder):
+		return http.StatusNotFound
+	case errors.Is(err, paper.ErrNoPrice):
+		return http.StatusConflict
+	case errors.Is(err, paper.ErrFilter), errors.Is(err, paper.ErrInsufficientBalance):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusBadRequest
+	}
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/paper"
+)
+
+func TestRegisterPaperTrading(t *testing.T) {
+	exchange := paper.NewExchange(paper.Config{Balances: map[string]float64{"usdt": 10000}})
+	exchange.Process(models.FormattedData{EventTime: 1000, Symbol: "BTCUSDT", BidPrice: 49990, AskPrice: 50000, LastPrice: 49995})
+
+	server := NewServer(Config{})
+	server.RegisterPaperTrading(exchange)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		server.Handler().ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := do(http.MethodPost, "/api/v1/paper/orders", `{"symbol":"BTCUSDT","side":"BUY","type":"MARKET","quantity":0.1}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var order models.Order
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &order))
+	assert.Equal(t, models.StatusFilled, order.Status)
+
+	rec = do(http.MethodPost, "/api/v1/paper/orders", `{"symbol":"BTCUSDT","side":"BUY","type":"LIMIT","quantity":0.1,"price":40000}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &order))
+
+	rec = do(http.MethodPost, "/api/v1/paper/orders", `{"symbol":"BTCUSDT","side":"BUY","type":"MARKET","quantity":10}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	rec = do(http.MethodPost, "/api/v1/paper/orders", `not json`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = do(http.MethodGet, "/api/v1/paper/orders", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var orders []models.Order
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &orders))
+	assert.Len(t, orders, 2)
+
+	rec = do(http.MethodDelete, "/api/v1/paper/orders/2", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	rec = do(http.MethodDelete, "/api/v1/paper/orders/2", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = do(http.MethodGet, "/api/v1/paper/account", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var account models.Account
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &account))
+	assert.InDelta(t, 0.1, account.Balances["BTC"].Free, 1e-12)
+}
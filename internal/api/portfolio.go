@@ -0,0 +1,19 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/portfolio"
+)
+
+// RegisterPortfolio exposes the latest portfolio valuation
+func (s *Server) RegisterPortfolio(valuer *portfolio.Valuer) {
+	s.mux.HandleFunc("GET /api/v1/portfolio", func(w http.ResponseWriter, r *http.Request) {
+		valuation := valuer.Latest()
+		if valuation.EventTime == 0 {
+			writeError(w, http.StatusNotFound, "no portfolio valuation yet")
+			return
+		}
+		writeJSON(w, http.StatusOK, valuation)
+	})
+}
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/portfolio"
+)
+
+func TestRegisterPortfolio(t *testing.T) {
+	valuer := portfolio.NewValuer(portfolio.Config{Holdings: map[string]float64{"btc": 2}})
+
+	server := NewServer(Config{})
+	server.RegisterPortfolio(valuer)
+
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/portfolio", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	valuer.Process(models.FormattedData{EventTime: 1000, Symbol: "BTCUSDT", LastPrice: 60000})
+
+	rec = httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/portfolio", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var valuation models.PortfolioValuation
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &valuation))
+	assert.Equal(t, "USDT", valuation.Quote)
+	assert.Equal(t, 120000.0, valuation.TotalValue)
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"time"
+)
+
+const defaultAddr = ":8080"
+
+// Config holds the settings for the HTTP API
+type Config struct {
+	Addr    string
+	History HistoryConfig
+}
+
+// Server serves the monitor's HTTP API. Features register their routes on it
+// before Start is called.
+type Server struct {
+	mux        *http.ServeMux
+	httpServer *http.Server
+}
+
+// NewServer creates a new Server
+func NewServer(cfg Config) *Server {
+	addr := cfg.Addr
+	if addr == "" {
+		addr = defaultAddr
+	}
+
+	mux := http.NewServeMux()
+	return &Server{
+		mux: mux,
+		httpServer: &http.Server{
+			Addr:              addr,
+			Handler:           mux,
+			ReadHeaderTimeout: 5 * time.Second,
+		},
+	}
+}
+
+// Handler returns the server's root handler
+func (s *Server) Handler() http.Handler {
+	return s.mux
+}
+
+// Start begins serving in the background. It returns once the listener is
+// open so that address errors are reported to the caller.
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("HTTP API listening on %s", listener.Addr())
+	go func() {
+		if err := s.httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("HTTP API error: %v", err)
+		}
+	}()
+
+	return nil
+}
+
+// Shutdown gracefully stops the server
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.httpServer.Shutdown(ctx)
+}
+
+// writeJSON writes v as a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Error encoding HTTP response: %v", err)
+	}
+}
+
+// writeError writes a JSON error response
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
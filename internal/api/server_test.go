@@ -0,0 +1,32 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_StartAndShutdown(t *testing.T) {
+	server := NewServer(Config{Addr: "127.0.0.1:0"})
+	require.NoError(t, server.Start())
+	assert.NoError(t, server.Shutdown(context.Background()))
+}
+
+func TestServer_StartInvalidAddr(t *testing.T) {
+	server := NewServer(Config{Addr: "invalid-address"})
+	assert.Error(t, server.Start())
+}
+
+func TestWriteError(t *testing.T) {
+	rec := httptest.NewRecorder()
+	writeError(rec, http.StatusBadRequest, "bad input")
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, fmt.Sprintf(`{"error": %q}`, "bad input"), rec.Body.String())
+}
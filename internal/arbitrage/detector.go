@@ -0,0 +1,304 @@
+package arbitrage
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+const (
+	defaultFee    = 0.001
+	defaultMaxAge = 5 * time.Second
+)
+
+// Config holds the settings for triangular arbitrage detection
+type Config struct {
+	Enabled bool
+	Persist bool
+	// Threshold is the minimum cycle return after fees, e.g. 0.001 for 0.1%
+	Threshold float64
+	// Fee is the taker fee charged on each leg
+	Fee float64
+	// MaxAge ignores prices older than this relative to the newest tick
+	MaxAge time.Duration `mapstructure:"max_age"`
+	// QuoteAssets is used to split symbols into base and quote assets
+	QuoteAssets []string `mapstructure:"quote_assets"`
+}
+
+// OpportunityProcessor receives closed arbitrage opportunities from a Detector
+type OpportunityProcessor interface {
+	ProcessOpportunity(opportunity models.ArbitrageOpportunity)
+}
+
+type market struct {
+	symbol    string
+	base      string
+	quote     string
+	bid       float64
+	ask       float64
+	last      float64
+	eventTime int64
+}
+
+// convert returns the amount of to received for one unit of from
+func (m *market) convert(from string) float64 {
+	if from == m.base {
+		if m.bid > 0 {
+			return m.bid
+		}
+		return m.last
+	}
+	price := m.ask
+	if price <= 0 {
+		price = m.last
+	}
+	if price <= 0 {
+		return 0
+	}
+	return 1 / price
+}
+
+type cycle struct {
+	id      string
+	path    []string
+	markets []*market
+}
+
+// Detector implements DataProcessor interface. It builds a currency graph from
+// the monitored symbols and reports triangular cycles whose implied return
+// after fees exceeds the threshold, tracking how long each one lasts.
+type Detector struct {
+	cfg            Config
+	markets        map[string]*market
+	cycles         map[string][]*cycle
+	open           map[string]*models.ArbitrageOpportunity
+	downstream     []OpportunityProcessor
+	mutex          sync.Mutex
+	processedCount int
+}
+
+// NewDetector creates a new Detector
+func NewDetector(cfg Config) *Detector {
+	if cfg.Fee <= 0 {
+		cfg.Fee = defaultFee
+	}
+	if cfg.MaxAge <= 0 {
+		cfg.MaxAge = defaultMaxAge
+	}
+
+	return &Detector{
+		cfg:     cfg,
+		markets: make(map[string]*market),
+		cycles:  make(map[string][]*cycle),
+		open:    make(map[string]*models.ArbitrageOpportunity),
+	}
+}
+
+// AddProcessor adds a downstream processor for closed opportunities
+func (d *Detector) AddProcessor(proc OpportunityProcessor) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.downstream = append(d.downstream, proc)
+}
+
+// Process implements the DataProcessor interface
+func (d *Detector) Process(data models.FormattedData) {
+	d.mutex.Lock()
+	closed := d.update(data)
+	d.processedCount++
+	downstream := d.downstream
+	d.mutex.Unlock()
+
+	for _, opportunity := range closed {
+		for _, proc := range downstream {
+			proc.ProcessOpportunity(opportunity)
+		}
+	}
+}
+
+// update records the tick's prices, re-evaluates the cycles through its
+// symbol and returns the opportunities that closed. The caller must hold the
+// mutex.
+func (d *Detector) update(data models.FormattedData) []models.ArbitrageOpportunity {
+	symbol := strings.ToUpper(data.Symbol)
+	m, ok := d.markets[symbol]
+	if !ok {
+		base, quote, split := models.SplitSymbol(symbol, d.cfg.QuoteAssets)
+		if !split {
+			return nil
+		}
+		m = &market{symbol: symbol, base: base, quote: quote}
+		d.markets[symbol] = m
+		d.addCycles(m)
+	}
+	if data.EventTime < m.eventTime {
+		return nil
+	}
+	m.bid, m.ask, m.last, m.eventTime = data.BidPrice, data.AskPrice, data.LastPrice, data.EventTime
+
+	var closed []models.ArbitrageOpportunity
+	maxAge := d.cfg.MaxAge.Milliseconds()
+	for _, c := range d.cycles[symbol] {
+		ret, fresh := d.cycleReturn(c, data.EventTime, maxAge)
+		opportunity, isOpen := d.open[c.id]
+
+		if fresh && ret >= d.cfg.Threshold {
+			if !isOpen {
+				opportunity = &models.ArbitrageOpportunity{
+					Path:      c.path,
+					Symbols:   cycleSymbols(c),
+					StartTime: data.EventTime,
+					MaxReturn: ret,
+				}
+				d.open[c.id] = opportunity
+			}
+			opportunity.EndTime = data.EventTime
+			opportunity.LastReturn = ret
+			if ret > opportunity.MaxReturn {
+				opportunity.MaxReturn = ret
+			}
+			continue
+		}
+
+		if isOpen {
+			opportunity.EndTime = data.EventTime
+			opportunity.DurationMs = opportunity.EndTime - opportunity.StartTime
+			closed = append(closed, *opportunity)
+			delete(d.open, c.id)
+		}
+	}
+
+	return closed
+}
+
+// cycleReturn returns the implied return of a cycle after fees and whether all
+// of its prices are recent enough to trade on
+func (d *Detector) cycleReturn(c *cycle, now, maxAge int64) (float64, bool) {
+	amount := 1.0
+	for i, m := range c.markets {
+		if now-m.eventTime > maxAge {
+			return 0, false
+		}
+		rate := m.convert(c.path[i])
+		if rate <= 0 {
+			return 0, false
+		}
+		amount *= rate * (1 - d.cfg.Fee)
+	}
+	return amount - 1, true
+}
+
+// addCycles registers every triangle the new market completes, in both directions
+func (d *Detector) addCycles(m *market) {
+	for _, second := range d.markets {
+		if second == m || !sharesAsset(m, second) {
+			continue
+		}
+		for _, third := range d.markets {
+			if third == m || third == second || second.symbol > third.symbol {
+				continue
+			}
+			assets := triangleAssets(m, second, third)
+			if assets == nil {
+				continue
+			}
+			for _, path := range [][]string{
+				{assets[0], assets[1], assets[2], assets[0]},
+				{assets[0], assets[2], assets[1], assets[0]},
+			} {
+				c := &cycle{id: strings.Join(path, ">"), path: path}
+				for i := 0; i < 3; i++ {
+					c.markets = append(c.markets, marketBetween([]*market{m, second, third}, path[i], path[i+1]))
+				}
+				for _, leg := range c.markets {
+					d.cycles[leg.symbol] = append(d.cycles[leg.symbol], c)
+				}
+			}
+		}
+	}
+}
+
+func marketBetween(markets []*market, a, b string) *market {
+	for _, m := range markets {
+		if (m.base == a && m.quote == b) || (m.base == b && m.quote == a) {
+			return m
+		}
+	}
+	return nil
+}
+
+// Open returns the opportunities currently above the threshold
+func (d *Detector) Open() []models.ArbitrageOpportunity {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	result := make([]models.ArbitrageOpportunity, 0, len(d.open))
+	for _, opportunity := range d.open {
+		o := *opportunity
+		o.DurationMs = o.EndTime - o.StartTime
+		result = append(result, o)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].MaxReturn > result[j].MaxReturn })
+	return result
+}
+
+// GetProcessedCount returns the number of processed messages
+func (d *Detector) GetProcessedCount() int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.processedCount
+}
+
+// GetBufferSize returns the number of opportunities currently open
+func (d *Detector) GetBufferSize() int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return len(d.open)
+}
+
+func sharesAsset(a, b *market) bool {
+	return a.base == b.base || a.base == b.quote || a.quote == b.base || a.quote == b.quote
+}
+
+// triangleAssets returns the three sorted assets when the markets connect
+// them pairwise, or nil otherwise
+func triangleAssets(markets ...*market) []string {
+	counts := make(map[string]int)
+	pairs := make(map[string]bool)
+	for _, m := range markets {
+		counts[m.base]++
+		counts[m.quote]++
+		key := m.base + "/" + m.quote
+		if m.quote < m.base {
+			key = m.quote + "/" + m.base
+		}
+		if pairs[key] {
+			return nil
+		}
+		pairs[key] = true
+	}
+	if len(counts) != 3 {
+		return nil
+	}
+
+	assets := make([]string, 0, 3)
+	for asset, count := range counts {
+		if count != 2 {
+			return nil
+		}
+		assets = append(assets, asset)
+	}
+	sort.Strings(assets)
+	return assets
+}
+
+func cycleSymbols(c *cycle) []string {
+	symbols := make([]string, len(c.markets))
+	for i, m := range c.markets {
+		symbols[i] = m.symbol
+	}
+	return symbols
+}
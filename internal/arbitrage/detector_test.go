@@ -0,0 +1,106 @@
+package arbitrage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+type mockOpportunityProcessor struct {
+	opportunities []models.ArbitrageOpportunity
+}
+
+func (m *mockOpportunityProcessor) ProcessOpportunity(opportunity models.ArbitrageOpportunity) {
+	m.opportunities = append(m.opportunities, opportunity)
+}
+
+func quote(eventTime int64, symbol string, bid, ask float64) models.FormattedData {
+	return models.FormattedData{EventTime: eventTime, Symbol: symbol, BidPrice: bid, AskPrice: ask, LastPrice: (bid + ask) / 2}
+}
+
+func TestDetector_FairPricesHaveNoOpportunity(t *testing.T) {
+	detector := NewDetector(Config{Threshold: 0.0001})
+
+	detector.Process(quote(1000, "BTCUSDT", 60000, 60001))
+	detector.Process(quote(1000, "ETHBTC", 0.05, 0.05001))
+	detector.Process(quote(1000, "ETHUSDT", 3000, 3000.1))
+
+	assert.Empty(t, detector.Open())
+	assert.Equal(t, 3, detector.GetProcessedCount())
+}
+
+func TestDetector_TracksOpportunityDuration(t *testing.T) {
+	detector := NewDetector(Config{Threshold: 0.001, Fee: 0.00075})
+	sink := &mockOpportunityProcessor{}
+	detector.AddProcessor(sink)
+
+	detector.Process(quote(1000, "BTCUSDT", 60000, 60001))
+	detector.Process(quote(1000, "ETHBTC", 0.05, 0.05001))
+	// ETH is cheap in USDT: buy ETH with USDT, sell for BTC, sell BTC for USDT
+	detector.Process(quote(1000, "ETHUSDT", 2949, 2950))
+
+	open := detector.Open()
+	require.Len(t, open, 1)
+	assert.Equal(t, []string{"BTC", "USDT", "ETH", "BTC"}, open[0].Path)
+	assert.Equal(t, []string{"BTCUSDT", "ETHUSDT", "ETHBTC"}, open[0].Symbols)
+	// 60000 / 2950 * 0.05 * (1 - fee)^3 - 1
+	assert.InDelta(t, 0.01465, open[0].MaxReturn, 1e-4)
+	assert.Equal(t, 1, detector.GetBufferSize())
+
+	detector.Process(quote(2500, "ETHUSDT", 2948, 2949))
+	detector.Process(quote(3000, "BTCUSDT", 60000, 60001))
+	detector.Process(quote(4000, "ETHUSDT", 3000, 3000.1))
+
+	assert.Empty(t, detector.Open())
+	require.Len(t, sink.opportunities, 1)
+	opportunity := sink.opportunities[0]
+	assert.Equal(t, int64(1000), opportunity.StartTime)
+	assert.Equal(t, int64(4000), opportunity.EndTime)
+	assert.Equal(t, int64(3000), opportunity.DurationMs)
+	assert.Greater(t, opportunity.MaxReturn, 0.01465)
+}
+
+func TestDetector_FeesRemoveSmallOpportunities(t *testing.T) {
+	detector := NewDetector(Config{Threshold: 0, Fee: 0.001})
+
+	detector.Process(quote(1000, "BTCUSDT", 60000, 60001))
+	detector.Process(quote(1000, "ETHBTC", 0.05, 0.05001))
+	// 0.1% gross edge is less than the three 0.1% fees
+	detector.Process(quote(1000, "ETHUSDT", 2997, 2997))
+
+	assert.Empty(t, detector.Open())
+}
+
+func TestDetector_IgnoresStalePrices(t *testing.T) {
+	detector := NewDetector(Config{Threshold: 0.001, MaxAge: time.Second})
+
+	detector.Process(quote(1000, "BTCUSDT", 60000, 60001))
+	detector.Process(quote(1000, "ETHBTC", 0.05, 0.05001))
+	detector.Process(quote(5000, "ETHUSDT", 2900, 2901))
+
+	assert.Empty(t, detector.Open())
+}
+
+func TestDetector_FallsBackToLastPrice(t *testing.T) {
+	detector := NewDetector(Config{Threshold: 0.001})
+
+	detector.Process(models.FormattedData{EventTime: 1, Symbol: "BTCUSDT", LastPrice: 60000})
+	detector.Process(models.FormattedData{EventTime: 1, Symbol: "ETHBTC", LastPrice: 0.05})
+	detector.Process(models.FormattedData{EventTime: 1, Symbol: "ETHUSDT", LastPrice: 2900})
+
+	assert.Len(t, detector.Open(), 1)
+}
+
+func TestTriangleAssets(t *testing.T) {
+	btcusdt := &market{base: "BTC", quote: "USDT"}
+	ethbtc := &market{base: "ETH", quote: "BTC"}
+	ethusdt := &market{base: "ETH", quote: "USDT"}
+	ltcusdt := &market{base: "LTC", quote: "USDT"}
+
+	assert.Equal(t, []string{"BTC", "ETH", "USDT"}, triangleAssets(btcusdt, ethbtc, ethusdt))
+	assert.Nil(t, triangleAssets(btcusdt, ethbtc, ltcusdt))
+}
@@ -0,0 +1,238 @@
+package backtest
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/paper"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/strategy"
+)
+
+const (
+	defaultQuote          = "USDT"
+	defaultSampleInterval = time.Hour
+	yearMillis            = float64(365 * 24 * time.Hour / time.Millisecond)
+)
+
+// Config holds the simulated account and how equity is measured
+type Config struct {
+	Paper    paper.Config
+	Strategy strategy.Config
+	// Quote is the asset equity is measured in
+	Quote string
+	// SampleInterval is the spacing of equity samples used for Sharpe ratio
+	SampleInterval time.Duration
+}
+
+// EquityPoint is the account value at a point in time
+type EquityPoint struct {
+	Time   int64   `json:"time"`
+	Equity float64 `json:"equity"`
+}
+
+// Metrics summarises a backtest
+type Metrics struct {
+	StartTime     int64   `json:"start_time"`
+	EndTime       int64   `json:"end_time"`
+	Ticks         int     `json:"ticks"`
+	StartEquity   float64 `json:"start_equity"`
+	EndEquity     float64 `json:"end_equity"`
+	TotalReturn   float64 `json:"total_return"`
+	SharpeRatio   float64 `json:"sharpe_ratio"`
+	MaxDrawdown   float64 `json:"max_drawdown"`
+	Trades        int     `json:"trades"`
+	Fees          float64 `json:"fees"`
+	RealizedPnL   float64 `json:"realized_pnl"`
+	UnrealizedPnL float64 `json:"unrealized_pnl"`
+}
+
+// Report is the outcome of a backtest
+type Report struct {
+	Strategy string        `json:"strategy"`
+	Metrics  Metrics       `json:"metrics"`
+	Trades   []models.Fill `json:"trades"`
+	Equity   []EquityPoint `json:"equity"`
+}
+
+type fillRecorder struct {
+	fills []models.Fill
+}
+
+func (r *fillRecorder) ProcessOrder(order models.Order) {}
+
+func (r *fillRecorder) ProcessFill(fill models.Fill) {
+	r.fills = append(r.fills, fill)
+}
+
+// Run replays ticks, in event time order, through a paper exchange and the
+// configured strategy. Each tick reaches the exchange before the strategy,
+// exactly as in the live processor chain. The strategy only sees ticks once
+// every starting balance can be valued, which is the first equity sample.
+func Run(cfg Config, ticks []models.FormattedData) (Report, error) {
+	if cfg.Quote == "" {
+		cfg.Quote = defaultQuote
+	}
+	cfg.Quote = strings.ToUpper(cfg.Quote)
+	if cfg.SampleInterval == 0 {
+		cfg.SampleInterval = defaultSampleInterval
+	}
+	if cfg.SampleInterval < time.Millisecond {
+		return Report{}, fmt.Errorf("sample interval %s is below the 1ms tick resolution", cfg.SampleInterval)
+	}
+
+	exchange := paper.NewExchange(cfg.Paper)
+	recorder := &fillRecorder{}
+	exchange.AddProcessor(recorder)
+
+	strat, err := strategy.New(cfg.Strategy, exchange)
+	if err != nil {
+		return Report{}, err
+	}
+	if len(ticks) == 0 {
+		return Report{}, fmt.Errorf("no ticks to backtest")
+	}
+
+	sort.SliceStable(ticks, func(i, j int) bool { return ticks[i].EventTime < ticks[j].EventTime })
+
+	interval := cfg.SampleInterval.Milliseconds()
+	prices := make(map[string]float64)
+	report := Report{Strategy: cfg.Strategy.Name}
+	var nextSample int64
+	started := false
+
+	for _, tick := range ticks {
+		exchange.Process(tick)
+		prices[strings.ToUpper(tick.Symbol)] = tick.LastPrice
+
+		// The strategy starts once the starting balances can be valued, so
+		// that the first sample is the account before any trade
+		if !started {
+			if len(unpriced(exchange.Account(), prices, cfg.Quote)) > 0 {
+				continue
+			}
+			started = true
+			report.Equity = append(report.Equity, EquityPoint{Time: tick.EventTime, Equity: equity(exchange.Account(), prices, cfg.Quote)})
+			nextSample = tick.EventTime - tick.EventTime%interval + interval
+		}
+
+		strat.Process(tick)
+		if tick.EventTime >= nextSample {
+			report.Equity = append(report.Equity, EquityPoint{Time: tick.EventTime, Equity: equity(exchange.Account(), prices, cfg.Quote)})
+			nextSample = tick.EventTime - tick.EventTime%interval + interval
+		}
+	}
+	if !started {
+		return Report{}, fmt.Errorf("no ticks price the starting balances of %s in %s",
+			strings.Join(unpriced(exchange.Account(), prices, cfg.Quote), ", "), cfg.Quote)
+	}
+
+	last := ticks[len(ticks)-1]
+	if report.Equity[len(report.Equity)-1].Time != last.EventTime {
+		report.Equity = append(report.Equity, EquityPoint{Time: last.EventTime, Equity: equity(exchange.Account(), prices, cfg.Quote)})
+	}
+
+	account := exchange.Account()
+	report.Trades = recorder.fills
+	report.Metrics = Metrics{
+		StartTime:     ticks[0].EventTime,
+		EndTime:       last.EventTime,
+		Ticks:         len(ticks),
+		StartEquity:   report.Equity[0].Equity,
+		EndEquity:     report.Equity[len(report.Equity)-1].Equity,
+		SharpeRatio:   sharpe(report.Equity, cfg.SampleInterval),
+		MaxDrawdown:   maxDrawdown(report.Equity),
+		Trades:        len(recorder.fills),
+		Fees:          account.Fees,
+		RealizedPnL:   account.RealizedPnL,
+		UnrealizedPnL: account.UnrealizedPnL,
+	}
+	if report.Metrics.StartEquity != 0 {
+		report.Metrics.TotalReturn = report.Metrics.EndEquity/report.Metrics.StartEquity - 1
+	}
+
+	return report, nil
+}
+
+// unpriced returns the held assets, other than the quote asset, whose direct
+// market has not ticked yet
+func unpriced(account models.Account, prices map[string]float64, quote string) []string {
+	var assets []string
+	for asset, balance := range account.Balances {
+		if asset != quote && balance.Free+balance.Locked > 0 && prices[asset+quote] <= 0 {
+			assets = append(assets, asset)
+		}
+	}
+	sort.Strings(assets)
+	return assets
+}
+
+// equity values every balance in the quote asset through its direct market
+func equity(account models.Account, prices map[string]float64, quote string) float64 {
+	total := 0.0
+	for asset, balance := range account.Balances {
+		amount := balance.Free + balance.Locked
+		if asset == quote {
+			total += amount
+			continue
+		}
+		total += amount * prices[asset+quote]
+	}
+	return total
+}
+
+// sharpe returns the annualised Sharpe ratio of the equity sample returns,
+// assuming a zero risk-free rate
+func sharpe(points []EquityPoint, interval time.Duration) float64 {
+	if len(points) < 3 {
+		return 0
+	}
+
+	returns := make([]float64, 0, len(points)-1)
+	for i := 1; i < len(points); i++ {
+		if points[i-1].Equity == 0 {
+			continue
+		}
+		returns = append(returns, points[i].Equity/points[i-1].Equity-1)
+	}
+	if len(returns) < 2 {
+		return 0
+	}
+
+	mean := 0.0
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+	variance := 0.0
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	stddev := math.Sqrt(variance / float64(len(returns)-1))
+	if stddev == 0 {
+		return 0
+	}
+
+	periodsPerYear := yearMillis / float64(interval.Milliseconds())
+	return mean / stddev * math.Sqrt(periodsPerYear)
+}
+
+// maxDrawdown returns the largest peak-to-trough fall in equity as a fraction
+// of the peak
+func maxDrawdown(points []EquityPoint) float64 {
+	peak, worst := 0.0, 0.0
+	for _, p := range points {
+		if p.Equity > peak {
+			peak = p.Equity
+		}
+		if peak > 0 {
+			if drawdown := (peak - p.Equity) / peak; drawdown > worst {
+				worst = drawdown
+			}
+		}
+	}
+	return worst
+}
@@ -0,0 +1,156 @@
+package backtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/paper"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/strategy"
+)
+
+func testConfig() Config {
+	return Config{
+		Paper:          paper.Config{Balances: map[string]float64{"usdt": 1000}, TakerFee: 0.001},
+		Strategy:       strategy.Config{Name: "sma_cross", Symbol: "BTCUSDT", Params: map[string]float64{"fast": 2, "slow": 3, "quantity": 1}},
+		SampleInterval: time.Second,
+	}
+}
+
+func ticks(prices ...float64) []models.FormattedData {
+	result := make([]models.FormattedData, len(prices))
+	for i, price := range prices {
+		result[i] = models.FormattedData{EventTime: int64(i+1) * 1000, Symbol: "BTCUSDT", LastPrice: price}
+	}
+	return result
+}
+
+func TestRun(t *testing.T) {
+	report, err := Run(testConfig(), ticks(100, 100, 100, 90, 80, 90, 110, 120, 130, 110, 90, 80))
+	require.NoError(t, err)
+
+	assert.Equal(t, "sma_cross", report.Strategy)
+	require.Len(t, report.Trades, 2)
+	assert.Equal(t, models.SideBuy, report.Trades[0].Side)
+	assert.Equal(t, models.SideSell, report.Trades[1].Side)
+
+	m := report.Metrics
+	assert.Equal(t, 12, m.Ticks)
+	assert.Equal(t, int64(1000), m.StartTime)
+	assert.Equal(t, int64(12000), m.EndTime)
+	assert.Equal(t, 1000.0, m.StartEquity)
+	assert.Len(t, report.Equity, 12)
+	assert.InDelta(t, m.EndEquity/m.StartEquity-1, m.TotalReturn, 1e-12)
+	assert.InDelta(t, m.RealizedPnL, m.EndEquity-m.StartEquity, 1e-9)
+	assert.Greater(t, m.MaxDrawdown, 0.0)
+	assert.Equal(t, 2, m.Trades)
+	assert.Greater(t, m.Fees, 0.0)
+}
+
+func TestRun_Errors(t *testing.T) {
+	_, err := Run(testConfig(), nil)
+	assert.Error(t, err)
+
+	cfg := testConfig()
+	cfg.Strategy.Name = "missing"
+	_, err = Run(cfg, ticks(1, 2, 3))
+	assert.Error(t, err)
+
+	// Sub-millisecond sampling is rejected rather than dividing by zero
+	cfg = testConfig()
+	cfg.SampleInterval = 500 * time.Microsecond
+	_, err = Run(cfg, ticks(1, 2, 3))
+	assert.ErrorContains(t, err, "sample interval")
+
+	// Starting holdings that never tick cannot be valued
+	cfg = testConfig()
+	cfg.Paper.Balances["eth"] = 1
+	_, err = Run(cfg, ticks(1, 2, 3))
+	assert.ErrorContains(t, err, "ETH")
+}
+
+func TestRun_ValuesStartingHoldings(t *testing.T) {
+	cfg := testConfig()
+	cfg.Paper.Balances["eth"] = 2
+	input := ticks(100, 100, 100, 90, 80, 90, 110, 120, 130, 110, 90, 80)
+	// ETH first ticks after BTC, so the first sample waits for it
+	input = append(input, models.FormattedData{EventTime: 2500, Symbol: "ETHUSDT", LastPrice: 50})
+
+	report, err := Run(cfg, input)
+	require.NoError(t, err)
+	require.NotEmpty(t, report.Equity)
+	assert.Equal(t, int64(2500), report.Equity[0].Time)
+	assert.Equal(t, 1100.0, report.Metrics.StartEquity)
+	assert.InDelta(t, report.Metrics.RealizedPnL, report.Metrics.EndEquity-report.Metrics.StartEquity, 1e-9)
+}
+
+func TestMaxDrawdownAndSharpe(t *testing.T) {
+	points := []EquityPoint{{Equity: 100}, {Equity: 120}, {Equity: 90}, {Equity: 130}, {Equity: 104}}
+	assert.InDelta(t, 0.25, maxDrawdown(points), 1e-12)
+
+	flat := []EquityPoint{{Equity: 100}, {Equity: 100}, {Equity: 100}}
+	assert.Equal(t, 0.0, sharpe(flat, time.Hour))
+
+	rising := []EquityPoint{{Equity: 100}, {Equity: 101}, {Equity: 103}, {Equity: 104}}
+	assert.Greater(t, sharpe(rising, 24*time.Hour), 0.0)
+}
+
+func TestReadTicks(t *testing.T) {
+	input := `{"event_time":1000,"symbol":"BTCUSDT","last_price":100}
+
+{"event_time":2000,"symbol":"ETHUSDT","last_price":10}
+{"event_time":3000,"symbol":"btcusdt","last_price":101}
+{"event_time":9000,"symbol":"BTCUSDT","last_price":102}
+`
+	result, err := ReadTicks(strings.NewReader(input), []string{"BTCUSDT"}, 0, 5000)
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	assert.Equal(t, 101.0, result[1].LastPrice)
+
+	_, err = ReadTicks(strings.NewReader("not json\n"), nil, 0, 1)
+	assert.Error(t, err)
+}
+
+func TestLoadTicks(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"event_time", "symbol", "last_price", "price_change", "high_price", "low_price",
+		"volume", "quote_volume", "open_time", "close_time", "trade_count", "latency"}).
+		AddRow(1000, "BTCUSDT", 100.0, 1.0, 101.0, 99.0, 10.0, 1000.0, 0, 1000, 5, 3)
+	mock.ExpectQuery(`SELECT event_time, symbol, last_price, .+ FROM ticker_data`).
+		WithArgs("BTCUSDT,ETHUSDT", int64(0), int64(5000)).
+		WillReturnRows(rows)
+
+	result, err := LoadTicks(db, []string{"btcusdt", "ETHUSDT"}, 0, 5000)
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.Equal(t, 100.0, result[0].LastPrice)
+	assert.Equal(t, 5, result[0].TradeCount)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReport_Write(t *testing.T) {
+	report := Report{
+		Strategy: "sma_cross",
+		Trades:   []models.Fill{{EventTime: 1000, OrderID: 1, Symbol: "BTCUSDT", Side: models.SideBuy, Price: 100.5, Quantity: 1, Fee: 0.1005, FeeAsset: "USDT"}},
+	}
+
+	var csvOut bytes.Buffer
+	require.NoError(t, report.WriteCSV(&csvOut))
+	assert.Equal(t, "event_time,order_id,symbol,side,price,quantity,fee,fee_asset,maker\n1000,1,BTCUSDT,BUY,100.5,1,0.1005,USDT,false\n", csvOut.String())
+
+	var jsonOut bytes.Buffer
+	require.NoError(t, report.WriteJSON(&jsonOut))
+	var decoded Report
+	require.NoError(t, json.Unmarshal(jsonOut.Bytes(), &decoded))
+	assert.Equal(t, report.Trades, decoded.Trades)
+}
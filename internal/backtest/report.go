@@ -0,0 +1,41 @@
+package backtest
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+// WriteJSON writes the full report as indented JSON
+func (r Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteCSV writes the trade list as CSV, one fill per row
+func (r Report) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"event_time", "order_id", "symbol", "side", "price", "quantity", "fee", "fee_asset", "maker"}); err != nil {
+		return err
+	}
+	for _, fill := range r.Trades {
+		record := []string{
+			strconv.FormatInt(fill.EventTime, 10),
+			strconv.FormatInt(fill.OrderID, 10),
+			fill.Symbol,
+			string(fill.Side),
+			strconv.FormatFloat(fill.Price, 'f', -1, 64),
+			strconv.FormatFloat(fill.Quantity, 'f', -1, 64),
+			strconv.FormatFloat(fill.Fee, 'f', -1, 64),
+			fill.FeeAsset,
+			strconv.FormatBool(fill.Maker),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
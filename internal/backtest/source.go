@@ -0,0 +1,76 @@
+package backtest
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+// LoadTicks reads the ticks of the given symbols with event times in [from, to)
+// from the ticker_data table, oldest first
+func LoadTicks(db *sql.DB, symbols []string, from, to int64) ([]models.FormattedData, error) {
+	upper := make([]string, len(symbols))
+	for i, symbol := range symbols {
+		upper[i] = strings.ToUpper(symbol)
+	}
+
+	rows, err := db.Query(`SELECT event_time, symbol, last_price, price_change, high_price, low_price, volume,
+            quote_volume, open_time, close_time, trade_count, latency
+        FROM ticker_data
+        WHERE UPPER(symbol) = ANY(string_to_array($1, ',')) AND event_time >= $2 AND event_time < $3
+        ORDER BY event_time, id`,
+		strings.Join(upper, ","), from, to,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying ticker_data: %w", err)
+	}
+	defer rows.Close()
+
+	var ticks []models.FormattedData
+	for rows.Next() {
+		var t models.FormattedData
+		if err := rows.Scan(&t.EventTime, &t.Symbol, &t.LastPrice, &t.PriceChange, &t.HighPrice, &t.LowPrice,
+			&t.Volume, &t.QuoteVolume, &t.OpenTime, &t.CloseTime, &t.TradeCount, &t.Latency); err != nil {
+			return nil, fmt.Errorf("error scanning ticker_data: %w", err)
+		}
+		ticks = append(ticks, t)
+	}
+
+	return ticks, rows.Err()
+}
+
+// ReadTicks reads newline-delimited JSON ticks in the FormattedData format,
+// keeping the given symbols (all when empty) with event times in [from, to)
+func ReadTicks(r io.Reader, symbols []string, from, to int64) ([]models.FormattedData, error) {
+	wanted := make(map[string]bool, len(symbols))
+	for _, symbol := range symbols {
+		wanted[strings.ToUpper(symbol)] = true
+	}
+
+	var ticks []models.FormattedData
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var t models.FormattedData
+		if err := json.Unmarshal(scanner.Bytes(), &t); err != nil {
+			return nil, fmt.Errorf("invalid tick on line %d: %w", line, err)
+		}
+		if len(wanted) > 0 && !wanted[strings.ToUpper(t.Symbol)] {
+			continue
+		}
+		if t.EventTime < from || t.EventTime >= to {
+			continue
+		}
+		ticks = append(ticks, t)
+	}
+
+	return ticks, scanner.Err()
+}
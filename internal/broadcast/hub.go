@@ -0,0 +1,450 @@
+package broadcast
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+const (
+	defaultSendBuffer   = 256
+	defaultWriteTimeout = 10 * time.Second
+	defaultPingInterval = 30 * time.Second
+	// throttleResolution is how often throttled updates are checked for
+	// delivery
+	throttleResolution = 50 * time.Millisecond
+)
+
+var (
+	// ErrTooManyClients is returned when MaxClients clients are connected
+	ErrTooManyClients = errors.New("too many clients")
+	// ErrClosed is returned when a client connects after Close
+	ErrClosed = errors.New("broadcast hub is closed")
+)
+
+// Config holds the settings for re-broadcasting ticks to downstream clients
+type Config struct {
+	Enabled bool
+	// MaxRate caps the updates per second per symbol sent to a client, who
+	// may ask for fewer with the rate parameter; 0 sends every tick
+	MaxRate float64 `mapstructure:"max_rate"`
+	// SendBuffer is how many messages may queue for a client before it is
+	// evicted as a slow consumer
+	SendBuffer int `mapstructure:"send_buffer"`
+	// WriteTimeout is how long a single write may block
+	WriteTimeout time.Duration `mapstructure:"write_timeout"`
+	// PingInterval is how often idle connections are kept alive
+	PingInterval time.Duration `mapstructure:"ping_interval"`
+	// MaxClients limits the connected clients; 0 allows any number
+	MaxClients int `mapstructure:"max_clients"`
+	// AllowedOrigins lists the browser origins allowed to connect; empty
+	// allows any origin
+	AllowedOrigins []string `mapstructure:"allowed_origins"`
+}
+
+// SnapshotSource provides the latest state sent to clients when they connect
+type SnapshotSource interface {
+	All(now time.Time) []models.LatestTick
+}
+
+// message is a payload queued for a client. Data is encoded once and shared
+// by every client receiving it.
+type message struct {
+	kind string
+	data []byte
+}
+
+// client is a downstream connection with its subscriptions and throttling
+// state. A nil symbols set subscribes to every symbol.
+type client struct {
+	symbols  map[string]bool
+	interval time.Duration
+	lastSent map[string]time.Time
+	pending  map[string]message
+	send     chan message
+	done     chan struct{}
+	evicted  bool
+	once     sync.Once
+	mutex    sync.Mutex
+}
+
+func (c *client) close() {
+	c.once.Do(func() { close(c.done) })
+}
+
+func (c *client) subscribed(symbol string) bool {
+	return c.symbols == nil || c.symbols[symbol]
+}
+
+// subscriptions lists the subscribed symbols, nil meaning every symbol
+func (c *client) subscriptions() []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.symbols == nil {
+		return nil
+	}
+	list := make([]string, 0, len(c.symbols))
+	for symbol := range c.symbols {
+		list = append(list, symbol)
+	}
+	sort.Strings(list)
+	return list
+}
+
+func (c *client) wasEvicted() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.evicted
+}
+
+// subscribe adds symbols and returns the ones that were new
+func (c *client) subscribe(symbols []string) []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.symbols == nil {
+		c.symbols = make(map[string]bool)
+	}
+	var added []string
+	for _, symbol := range symbols {
+		symbol = strings.ToUpper(symbol)
+		if symbol != "" && !c.symbols[symbol] {
+			c.symbols[symbol] = true
+			added = append(added, symbol)
+		}
+	}
+	return added
+}
+
+func (c *client) unsubscribe(symbols []string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.symbols == nil {
+		c.symbols = make(map[string]bool)
+	}
+	for _, symbol := range symbols {
+		symbol = strings.ToUpper(symbol)
+		delete(c.symbols, symbol)
+		delete(c.pending, symbol)
+	}
+}
+
+// offer sends a tick unless the symbol was sent less than interval ago, in
+// which case it replaces any update already waiting for that symbol. It
+// reports false when the client cannot keep up.
+func (c *client) offer(symbol string, msg message, now time.Time) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if !c.subscribed(symbol) {
+		return true
+	}
+	if c.interval > 0 && now.Sub(c.lastSent[symbol]) < c.interval {
+		c.pending[symbol] = msg
+		return true
+	}
+	c.lastSent[symbol] = now
+	delete(c.pending, symbol)
+	return c.enqueue(msg)
+}
+
+// flushDue sends the waiting updates whose interval has elapsed
+func (c *client) flushDue(now time.Time) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for symbol, msg := range c.pending {
+		if now.Sub(c.lastSent[symbol]) < c.interval {
+			continue
+		}
+		c.lastSent[symbol] = now
+		delete(c.pending, symbol)
+		if !c.enqueue(msg) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *client) enqueue(msg message) bool {
+	select {
+	case c.send <- msg:
+		return true
+	default:
+		return false
+	}
+}
+
+// Hub implements DataProcessor interface and fans ticks out to WebSocket and
+// server-sent events clients, so that many browsers share one upstream
+// Binance connection. Clients that fall SendBuffer messages behind are
+// evicted.
+type Hub struct {
+	cfg            Config
+	snapshots      SnapshotSource
+	clients        map[*client]struct{}
+	mutex          sync.RWMutex
+	processedCount int
+	evictedCount   int
+	closed         bool
+}
+
+// NewHub creates a new Hub. snapshots may be nil, in which case clients
+// receive no initial snapshot.
+func NewHub(cfg Config, snapshots SnapshotSource) *Hub {
+	if cfg.SendBuffer <= 0 {
+		cfg.SendBuffer = defaultSendBuffer
+	}
+	if cfg.WriteTimeout <= 0 {
+		cfg.WriteTimeout = defaultWriteTimeout
+	}
+	if cfg.PingInterval <= 0 {
+		cfg.PingInterval = defaultPingInterval
+	}
+	return &Hub{
+		cfg:       cfg,
+		snapshots: snapshots,
+		clients:   make(map[*client]struct{}),
+	}
+}
+
+// register adds a client subscribed to symbols (every symbol when empty)
+// with at most rate updates per second per symbol, capped by MaxRate, and
+// queues its snapshot
+func (h *Hub) register(symbols []string, rate float64) (*client, error) {
+	c := &client{
+		lastSent: make(map[string]time.Time),
+		pending:  make(map[string]message),
+		send:     make(chan message, h.cfg.SendBuffer),
+		done:     make(chan struct{}),
+	}
+	if len(symbols) > 0 {
+		c.subscribe(symbols)
+	}
+	if h.cfg.MaxRate > 0 && (rate <= 0 || rate > h.cfg.MaxRate) {
+		rate = h.cfg.MaxRate
+	}
+	if rate > 0 {
+		c.interval = time.Duration(float64(time.Second) / rate)
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if h.closed {
+		return nil, ErrClosed
+	}
+	if h.cfg.MaxClients > 0 && len(h.clients) >= h.cfg.MaxClients {
+		return nil, ErrTooManyClients
+	}
+	// The snapshot is queued before the client joins, so that no tick reaches
+	// it ahead of older state
+	if msg, ok := h.snapshotMessage(c, nil); ok {
+		c.enqueue(msg)
+	}
+	h.clients[c] = struct{}{}
+	return c, nil
+}
+
+func (h *Hub) unregister(c *client) {
+	c.close()
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	delete(h.clients, c)
+}
+
+// evict disconnects a client that cannot keep up
+func (h *Hub) evict(c *client) {
+	c.mutex.Lock()
+	evicted := c.evicted
+	c.evicted = true
+	c.mutex.Unlock()
+	if evicted {
+		return
+	}
+
+	log.Printf("Broadcast client too slow, disconnecting")
+	h.mutex.Lock()
+	h.evictedCount++
+	h.mutex.Unlock()
+	h.unregister(c)
+}
+
+// snapshot queues the latest state of the given symbols (the client's
+// subscriptions when nil) for a client
+func (h *Hub) snapshot(c *client, symbols []string) {
+	msg, ok := h.snapshotMessage(c, symbols)
+	if ok && !c.enqueue(msg) {
+		h.evict(c)
+	}
+}
+
+// snapshotMessage encodes the latest state of the given symbols (the
+// client's subscriptions when nil), reporting false without a snapshot source
+func (h *Hub) snapshotMessage(c *client, symbols []string) (message, bool) {
+	if h.snapshots == nil {
+		return message{}, false
+	}
+
+	wanted := make(map[string]bool, len(symbols))
+	for _, symbol := range symbols {
+		wanted[symbol] = true
+	}
+
+	c.mutex.Lock()
+	ticks := []models.LatestTick{}
+	for _, tick := range h.snapshots.All(time.Now()) {
+		symbol := strings.ToUpper(tick.Symbol)
+		if (symbols == nil && c.subscribed(symbol)) || wanted[symbol] {
+			ticks = append(ticks, tick)
+		}
+	}
+	c.mutex.Unlock()
+
+	data, err := json.Marshal(ticks)
+	if err != nil {
+		log.Printf("Error encoding broadcast snapshot: %v", err)
+		return message{}, false
+	}
+	return message{kind: "snapshot", data: data}, true
+}
+
+// Process implements the DataProcessor interface
+func (h *Hub) Process(data models.FormattedData) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Error encoding broadcast tick: %v", err)
+		return
+	}
+	msg := message{kind: "tick", data: payload}
+	symbol := strings.ToUpper(data.Symbol)
+	now := time.Now()
+
+	h.mutex.Lock()
+	h.processedCount++
+	clients := make([]*client, 0, len(h.clients))
+	for c := range h.clients {
+		clients = append(clients, c)
+	}
+	h.mutex.Unlock()
+
+	for _, c := range clients {
+		if !c.offer(symbol, msg, now) {
+			h.evict(c)
+		}
+	}
+}
+
+// Run delivers throttled updates once their interval elapses until stop is
+// closed
+func (h *Hub) Run(stop chan struct{}) {
+	ticker := time.NewTicker(throttleResolution)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			h.mutex.RLock()
+			clients := make([]*client, 0, len(h.clients))
+			for c := range h.clients {
+				if c.interval > 0 {
+					clients = append(clients, c)
+				}
+			}
+			h.mutex.RUnlock()
+
+			for _, c := range clients {
+				if !c.flushDue(now) {
+					h.evict(c)
+				}
+			}
+		}
+	}
+}
+
+// Close disconnects every client and refuses new ones, so that their
+// long-lived requests do not hold up the HTTP server's shutdown
+func (h *Hub) Close() error {
+	h.mutex.Lock()
+	h.closed = true
+	clients := make([]*client, 0, len(h.clients))
+	for c := range h.clients {
+		clients = append(clients, c)
+	}
+	h.clients = make(map[*client]struct{})
+	h.mutex.Unlock()
+
+	for _, c := range clients {
+		c.close()
+	}
+	return nil
+}
+
+func (h *Hub) isClosed() bool {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return h.closed
+}
+
+// Clients returns the number of connected clients
+func (h *Hub) Clients() int {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return len(h.clients)
+}
+
+// GetEvictedCount returns the number of clients evicted as slow consumers
+func (h *Hub) GetEvictedCount() int {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return h.evictedCount
+}
+
+// GetProcessedCount returns the number of processed messages
+func (h *Hub) GetProcessedCount() int {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return h.processedCount
+}
+
+// GetBufferSize returns the number of messages queued for all clients
+func (h *Hub) GetBufferSize() int {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	size := 0
+	for c := range h.clients {
+		size += len(c.send)
+	}
+	return size
+}
+
+// parseSubscription reads the symbols and rate query parameters shared by
+// both endpoints, e.g. ?symbols=btcusdt,ethusdt&rate=2
+func parseSubscription(symbols, rate string) ([]string, float64, error) {
+	var list []string
+	for _, symbol := range strings.Split(symbols, ",") {
+		if symbol = strings.TrimSpace(symbol); symbol != "" {
+			list = append(list, strings.ToUpper(symbol))
+		}
+	}
+
+	var perSecond float64
+	if rate != "" {
+		var err error
+		perSecond, err = strconv.ParseFloat(rate, 64)
+		if err != nil || perSecond <= 0 {
+			return nil, 0, errors.New("invalid rate " + strconv.Quote(rate))
+		}
+	}
+	return list, perSecond, nil
+}
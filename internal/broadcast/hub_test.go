@@ -0,0 +1,215 @@
+package broadcast
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/latest"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+func tick(symbol string, eventTime int64, price float64) models.FormattedData {
+	return models.FormattedData{Symbol: symbol, EventTime: eventTime, LastPrice: price}
+}
+
+func received(t *testing.T, c *client) []message {
+	t.Helper()
+	var messages []message
+	for {
+		select {
+		case msg := <-c.send:
+			messages = append(messages, msg)
+		default:
+			return messages
+		}
+	}
+}
+
+func TestHub_Subscriptions(t *testing.T) {
+	hub := NewHub(Config{}, nil)
+	all, err := hub.register(nil, 0)
+	require.NoError(t, err)
+	btc, err := hub.register([]string{"BTCUSDT"}, 0)
+	require.NoError(t, err)
+
+	hub.Process(tick("BTCUSDT", 1000, 60000))
+	hub.Process(tick("ETHUSDT", 1000, 3000))
+
+	assert.Len(t, received(t, all), 2)
+	messages := received(t, btc)
+	require.Len(t, messages, 1)
+	assert.Equal(t, "tick", messages[0].kind)
+
+	var data models.FormattedData
+	require.NoError(t, json.Unmarshal(messages[0].data, &data))
+	assert.Equal(t, "BTCUSDT", data.Symbol)
+
+	btc.unsubscribe([]string{"btcusdt"})
+	assert.Equal(t, []string{"ETHUSDT"}, btc.subscribe([]string{"ethusdt"}))
+	assert.Equal(t, []string{"ETHUSDT"}, btc.subscriptions())
+	hub.Process(tick("BTCUSDT", 2000, 60000))
+	hub.Process(tick("ETHUSDT", 2000, 3000))
+	assert.Len(t, received(t, btc), 1)
+	assert.Equal(t, 4, hub.GetProcessedCount())
+}
+
+func TestHub_ThrottlesPerSymbol(t *testing.T) {
+	hub := NewHub(Config{MaxRate: 10}, nil)
+	// Asking for more than MaxRate is capped
+	c, err := hub.register(nil, 100)
+	require.NoError(t, err)
+	assert.Equal(t, 100*time.Millisecond, c.interval)
+
+	start := time.Now()
+	c.offer("BTCUSDT", message{kind: "tick", data: []byte("1")}, start)
+	c.offer("BTCUSDT", message{kind: "tick", data: []byte("2")}, start.Add(10*time.Millisecond))
+	c.offer("BTCUSDT", message{kind: "tick", data: []byte("3")}, start.Add(20*time.Millisecond))
+	c.offer("ETHUSDT", message{kind: "tick", data: []byte("e")}, start.Add(20*time.Millisecond))
+
+	// The first tick of each symbol goes out, later ones are coalesced
+	messages := received(t, c)
+	require.Len(t, messages, 2)
+	assert.Equal(t, "1", string(messages[0].data))
+	assert.Equal(t, "e", string(messages[1].data))
+
+	require.True(t, c.flushDue(start.Add(50*time.Millisecond)))
+	assert.Empty(t, received(t, c))
+	require.True(t, c.flushDue(start.Add(100*time.Millisecond)))
+	messages = received(t, c)
+	require.Len(t, messages, 1)
+	assert.Equal(t, "3", string(messages[0].data))
+}
+
+func TestHub_RunDeliversThrottledUpdates(t *testing.T) {
+	hub := NewHub(Config{}, nil)
+	c, err := hub.register(nil, 5)
+	require.NoError(t, err)
+	stop := make(chan struct{})
+	defer close(stop)
+	go hub.Run(stop)
+
+	hub.Process(tick("BTCUSDT", 1000, 60000))
+	hub.Process(tick("BTCUSDT", 2000, 60001))
+	assert.Len(t, received(t, c), 1)
+
+	select {
+	case msg := <-c.send:
+		assert.Contains(t, string(msg.data), "60001")
+	case <-time.After(time.Second):
+		t.Fatal("throttled update was not delivered")
+	}
+}
+
+func TestHub_EvictsSlowConsumers(t *testing.T) {
+	hub := NewHub(Config{SendBuffer: 2}, nil)
+	slow, err := hub.register(nil, 0)
+	require.NoError(t, err)
+	fast, err := hub.register(nil, 0)
+	require.NoError(t, err)
+
+	for i := int64(0); i < 3; i++ {
+		hub.Process(tick("BTCUSDT", i, 60000))
+		received(t, fast)
+	}
+
+	assert.Equal(t, 1, hub.Clients())
+	assert.Equal(t, 1, hub.GetEvictedCount())
+	assert.True(t, slow.wasEvicted())
+	select {
+	case <-slow.done:
+	default:
+		t.Fatal("evicted client was not closed")
+	}
+	assert.False(t, fast.wasEvicted())
+}
+
+func TestHub_MaxClients(t *testing.T) {
+	hub := NewHub(Config{MaxClients: 1}, nil)
+	c, err := hub.register(nil, 0)
+	require.NoError(t, err)
+	_, err = hub.register(nil, 0)
+	assert.ErrorIs(t, err, ErrTooManyClients)
+
+	hub.unregister(c)
+	_, err = hub.register(nil, 0)
+	assert.NoError(t, err)
+}
+
+func TestHub_Close(t *testing.T) {
+	hub := NewHub(Config{}, nil)
+	first, err := hub.register(nil, 0)
+	require.NoError(t, err)
+	second, err := hub.register([]string{"BTCUSDT"}, 0)
+	require.NoError(t, err)
+
+	require.NoError(t, hub.Close())
+	for _, c := range []*client{first, second} {
+		select {
+		case <-c.done:
+		default:
+			t.Fatal("client was not closed")
+		}
+	}
+	assert.Equal(t, 0, hub.Clients())
+
+	_, err = hub.register(nil, 0)
+	assert.ErrorIs(t, err, ErrClosed)
+	// Clients leaving after Close are harmless
+	hub.unregister(first)
+}
+
+func TestHub_Snapshot(t *testing.T) {
+	store := latest.NewStore()
+	store.Process(tick("BTCUSDT", 1000, 60000))
+	store.Process(tick("ETHUSDT", 1000, 3000))
+	hub := NewHub(Config{}, store)
+
+	// register queues the snapshot of the client's subscriptions
+	c, err := hub.register([]string{"ethusdt"}, 0)
+	require.NoError(t, err)
+	hub.snapshot(c, []string{"BTCUSDT"})
+
+	messages := received(t, c)
+	require.Len(t, messages, 2)
+	var snapshot []models.LatestTick
+	require.NoError(t, json.Unmarshal(messages[0].data, &snapshot))
+	require.Len(t, snapshot, 1)
+	assert.Equal(t, "ETHUSDT", snapshot[0].Symbol)
+	require.NoError(t, json.Unmarshal(messages[1].data, &snapshot))
+	require.Len(t, snapshot, 1)
+	assert.Equal(t, "BTCUSDT", snapshot[0].Symbol)
+}
+
+func TestHub_SnapshotPrecedesTicks(t *testing.T) {
+	store := latest.NewStore()
+	store.Process(tick("BTCUSDT", 1000, 60000))
+	hub := NewHub(Config{}, store)
+
+	c, err := hub.register(nil, 0)
+	require.NoError(t, err)
+	hub.Process(tick("BTCUSDT", 2000, 61000))
+
+	messages := received(t, c)
+	require.Len(t, messages, 2)
+	assert.Equal(t, "snapshot", messages[0].kind)
+	assert.Equal(t, "tick", messages[1].kind)
+}
+
+func TestParseSubscription(t *testing.T) {
+	symbols, rate, err := parseSubscription("btcusdt, ethusdt,", "2.5")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"BTCUSDT", "ETHUSDT"}, symbols)
+	assert.Equal(t, 2.5, rate)
+
+	symbols, rate, err = parseSubscription("", "")
+	require.NoError(t, err)
+	assert.Nil(t, symbols)
+	assert.Zero(t, rate)
+
+	_, _, err = parseSubscription("", "fast")
+	assert.Error(t, err)
+}
@@ -0,0 +1,80 @@
+package broadcast
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+// ServeSSE streams ticks as server-sent events: a snapshot event with the
+// latest tick of each subscribed symbol followed by tick events. The symbols
+// and rate query parameters set the subscriptions and throttling; without
+// symbols every symbol is sent. Comments keep idle connections alive.
+func (h *Hub) ServeSSE(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	if !h.checkOrigin(r) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+
+	symbols, rate, err := parseSubscription(r.URL.Query().Get("symbols"), r.URL.Query().Get("rate"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	c, err := h.register(symbols, rate)
+	if errors.Is(err, ErrTooManyClients) || errors.Is(err, ErrClosed) {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	defer h.unregister(c)
+
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	if origin := r.Header.Get("Origin"); origin != "" {
+		header.Set("Access-Control-Allow-Origin", origin)
+	}
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ping := time.NewTicker(h.cfg.PingInterval)
+	defer ping.Stop()
+	controller := http.NewResponseController(w)
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-c.done:
+			return
+		case msg := <-c.send:
+			controller.SetWriteDeadline(time.Now().Add(h.cfg.WriteTimeout))
+			if _, err := w.Write(event(msg)); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-ping.C:
+			controller.SetWriteDeadline(time.Now().Add(h.cfg.WriteTimeout))
+			if _, err := w.Write([]byte(": ping\n\n")); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// event formats a message as a server-sent event named after its kind
+func event(msg message) []byte {
+	out := make([]byte, 0, len(msg.data)+32)
+	out = append(out, "event: "...)
+	out = append(out, msg.kind...)
+	out = append(out, "\ndata: "...)
+	out = append(out, msg.data...)
+	return append(out, "\n\n"...)
+}
@@ -0,0 +1,83 @@
+package broadcast
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/latest"
+)
+
+// readEvent reads the next server-sent event, skipping comments
+func readEvent(t *testing.T, reader *bufio.Reader) (string, string) {
+	t.Helper()
+	var name, data string
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "" && name != "":
+			return name, data
+		case strings.HasPrefix(line, "event: "):
+			name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestServeSSE(t *testing.T) {
+	store := latest.NewStore()
+	store.Process(tick("BTCUSDT", 1000, 60000))
+	store.Process(tick("ETHUSDT", 1000, 3000))
+	hub := NewHub(Config{PingInterval: 20 * time.Millisecond}, store)
+	server := httptest.NewServer(http.HandlerFunc(hub.ServeSSE))
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"?symbols=ethusdt", nil)
+	require.NoError(t, err)
+	req.Header.Set("Origin", "http://localhost:5173")
+	resp, err := server.Client().Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "http://localhost:5173", resp.Header.Get("Access-Control-Allow-Origin"))
+
+	reader := bufio.NewReader(resp.Body)
+	name, data := readEvent(t, reader)
+	assert.Equal(t, "snapshot", name)
+	assert.Contains(t, data, `"symbol":"ETHUSDT"`)
+	assert.NotContains(t, data, "BTCUSDT")
+
+	waitForClients(t, hub, 1)
+	hub.Process(tick("BTCUSDT", 2000, 60100))
+	hub.Process(tick("ETHUSDT", 2000, 3001))
+	name, data = readEvent(t, reader)
+	assert.Equal(t, "tick", name)
+	assert.Contains(t, data, `"last_price":3001`)
+
+	resp.Body.Close()
+	waitForClients(t, hub, 0)
+}
+
+func TestServeSSE_Rejections(t *testing.T) {
+	hub := NewHub(Config{AllowedOrigins: []string{"https://dashboard.example.com"}}, nil)
+
+	rec := httptest.NewRecorder()
+	hub.ServeSSE(rec, httptest.NewRequest(http.MethodGet, "/?rate=0", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	rec = httptest.NewRecorder()
+	hub.ServeSSE(rec, req)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Zero(t, hub.Clients())
+}
@@ -0,0 +1,174 @@
+package broadcast
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// maxRequestSize is the largest control message read from a client
+const maxRequestSize = 4096
+
+// request is a WebSocket control message such as SUBSCRIBE
+type request struct {
+	Method string          `json:"method"`
+	Params []string        `json:"params"`
+	ID     json.RawMessage `json:"id"`
+}
+
+type response struct {
+	Result interface{}     `json:"result"`
+	Error  string          `json:"error,omitempty"`
+	ID     json.RawMessage `json:"id"`
+}
+
+// checkOrigin allows browsers from the configured origins
+func (h *Hub) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if len(h.cfg.AllowedOrigins) == 0 || origin == "" {
+		return true
+	}
+	for _, allowed := range h.cfg.AllowedOrigins {
+		if allowed == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// ServeWS upgrades a request to a WebSocket streaming
+// {"type": "tick", "data": ...} messages, preceded by a snapshot message with
+// the latest tick of each subscribed symbol. The symbols and rate query
+// parameters set the initial subscriptions and throttling; clients connected
+// without symbols receive every symbol until they SUBSCRIBE. Clients send
+// {"method": "SUBSCRIBE"|"UNSUBSCRIBE"|"LIST_SUBSCRIPTIONS", "params": [...], "id": 1}
+// to change their subscriptions.
+func (h *Hub) ServeWS(w http.ResponseWriter, r *http.Request) {
+	symbols, rate, err := parseSubscription(r.URL.Query().Get("symbols"), r.URL.Query().Get("rate"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	c, err := h.register(symbols, rate)
+	if errors.Is(err, ErrTooManyClients) || errors.Is(err, ErrClosed) {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	upgrader := websocket.Upgrader{CheckOrigin: h.checkOrigin}
+	ws, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		h.unregister(c)
+		log.Printf("Broadcast websocket upgrade error: %v", err)
+		return
+	}
+
+	go h.writeWS(ws, c)
+	h.readWS(ws, c)
+}
+
+// writeWS sends queued messages and pings until the client goes away or is
+// evicted
+func (h *Hub) writeWS(ws *websocket.Conn, c *client) {
+	ping := time.NewTicker(h.cfg.PingInterval)
+	defer ping.Stop()
+	defer ws.Close()
+
+	for {
+		select {
+		case <-c.done:
+			if c.wasEvicted() {
+				closing := websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "slow consumer")
+				ws.WriteControl(websocket.CloseMessage, closing, time.Now().Add(h.cfg.WriteTimeout))
+			} else if h.isClosed() {
+				closing := websocket.FormatCloseMessage(websocket.CloseGoingAway, "shutting down")
+				ws.WriteControl(websocket.CloseMessage, closing, time.Now().Add(h.cfg.WriteTimeout))
+			}
+			return
+		case msg := <-c.send:
+			ws.SetWriteDeadline(time.Now().Add(h.cfg.WriteTimeout))
+			if err := ws.WriteMessage(websocket.TextMessage, envelope(msg)); err != nil {
+				h.unregister(c)
+				return
+			}
+		case <-ping.C:
+			if err := ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(h.cfg.WriteTimeout)); err != nil {
+				h.unregister(c)
+				return
+			}
+		}
+	}
+}
+
+// readWS handles subscription requests. Clients that stop answering pings
+// or send requests over maxRequestSize are disconnected.
+func (h *Hub) readWS(ws *websocket.Conn, c *client) {
+	defer h.unregister(c)
+
+	ws.SetReadLimit(maxRequestSize)
+	pongWait := 3 * h.cfg.PingInterval
+	ws.SetReadDeadline(time.Now().Add(pongWait))
+	ws.SetPongHandler(func(string) error {
+		return ws.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, data, err := ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		ws.SetReadDeadline(time.Now().Add(pongWait))
+
+		var req request
+		if err := json.Unmarshal(data, &req); err != nil {
+			h.reply(c, response{Error: "invalid JSON: " + err.Error()})
+			continue
+		}
+
+		switch req.Method {
+		case "SUBSCRIBE":
+			added := c.subscribe(req.Params)
+			h.reply(c, response{ID: req.ID})
+			if len(added) > 0 {
+				h.snapshot(c, added)
+			}
+		case "UNSUBSCRIBE":
+			c.unsubscribe(req.Params)
+			h.reply(c, response{ID: req.ID})
+		case "LIST_SUBSCRIPTIONS":
+			h.reply(c, response{Result: c.subscriptions(), ID: req.ID})
+		default:
+			h.reply(c, response{Error: "unknown method " + req.Method, ID: req.ID})
+		}
+	}
+}
+
+// reply queues a response to a request
+func (h *Hub) reply(c *client, resp response) {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		log.Printf("Error encoding broadcast response: %v", err)
+		return
+	}
+	if !c.enqueue(message{kind: "response", data: data}) {
+		h.evict(c)
+	}
+}
+
+// envelope wraps a message for WebSocket clients. Responses are sent as is.
+func envelope(msg message) []byte {
+	if msg.kind == "response" {
+		return msg.data
+	}
+	out := make([]byte, 0, len(msg.data)+32)
+	out = append(out, `{"type":"`...)
+	out = append(out, msg.kind...)
+	out = append(out, `","data":`...)
+	out = append(out, msg.data...)
+	return append(out, '}')
+}
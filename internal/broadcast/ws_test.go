@@ -0,0 +1,161 @@
+package broadcast
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/latest"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+type wsMessage struct {
+	Type   string          `json:"type"`
+	Data   json.RawMessage `json:"data"`
+	Result json.RawMessage `json:"result"`
+	ID     json.RawMessage `json:"id"`
+}
+
+func dialHub(t *testing.T, hub *Hub, query string) *websocket.Conn {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(hub.ServeWS))
+	t.Cleanup(server.Close)
+
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/?"+query, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { ws.Close() })
+	return ws
+}
+
+func readMessage(t *testing.T, ws *websocket.Conn) wsMessage {
+	t.Helper()
+	require.NoError(t, ws.SetReadDeadline(time.Now().Add(time.Second)))
+	var msg wsMessage
+	require.NoError(t, ws.ReadJSON(&msg))
+	return msg
+}
+
+func waitForClients(t *testing.T, hub *Hub, n int) {
+	t.Helper()
+	require.Eventually(t, func() bool { return hub.Clients() == n }, time.Second, 5*time.Millisecond)
+}
+
+func TestServeWS(t *testing.T) {
+	store := latest.NewStore()
+	store.Process(tick("BTCUSDT", 1000, 60000))
+	hub := NewHub(Config{}, store)
+	ws := dialHub(t, hub, "symbols=btcusdt")
+
+	msg := readMessage(t, ws)
+	assert.Equal(t, "snapshot", msg.Type)
+	var snapshot []models.LatestTick
+	require.NoError(t, json.Unmarshal(msg.Data, &snapshot))
+	require.Len(t, snapshot, 1)
+	assert.Equal(t, 60000.0, snapshot[0].LastPrice)
+
+	waitForClients(t, hub, 1)
+	hub.Process(tick("ETHUSDT", 2000, 3000))
+	hub.Process(tick("BTCUSDT", 2000, 60100))
+	msg = readMessage(t, ws)
+	assert.Equal(t, "tick", msg.Type)
+	var data models.FormattedData
+	require.NoError(t, json.Unmarshal(msg.Data, &data))
+	assert.Equal(t, "BTCUSDT", data.Symbol)
+	assert.Equal(t, 60100.0, data.LastPrice)
+
+	// Closing the connection unregisters the client
+	ws.Close()
+	waitForClients(t, hub, 0)
+}
+
+func TestServeWS_Subscribe(t *testing.T) {
+	store := latest.NewStore()
+	store.Process(tick("ETHUSDT", 1000, 3000))
+	hub := NewHub(Config{}, store)
+	ws := dialHub(t, hub, "symbols=btcusdt")
+	assert.Equal(t, "snapshot", readMessage(t, ws).Type)
+
+	require.NoError(t, ws.WriteJSON(map[string]interface{}{"method": "SUBSCRIBE", "params": []string{"ethusdt"}, "id": 1}))
+	msg := readMessage(t, ws)
+	assert.JSONEq(t, "1", string(msg.ID))
+	msg = readMessage(t, ws)
+	assert.Equal(t, "snapshot", msg.Type)
+	assert.Contains(t, string(msg.Data), "ETHUSDT")
+
+	require.NoError(t, ws.WriteJSON(map[string]interface{}{"method": "UNSUBSCRIBE", "params": []string{"btcusdt"}, "id": 2}))
+	readMessage(t, ws)
+	require.NoError(t, ws.WriteJSON(map[string]interface{}{"method": "LIST_SUBSCRIPTIONS", "id": 3}))
+	msg = readMessage(t, ws)
+	assert.JSONEq(t, `["ETHUSDT"]`, string(msg.Result))
+
+	hub.Process(tick("BTCUSDT", 2000, 60000))
+	hub.Process(tick("ETHUSDT", 2000, 3001))
+	msg = readMessage(t, ws)
+	assert.Equal(t, "tick", msg.Type)
+	assert.Contains(t, string(msg.Data), "ETHUSDT")
+}
+
+func TestServeWS_Rejections(t *testing.T) {
+	hub := NewHub(Config{MaxClients: 1, AllowedOrigins: []string{"https://dashboard.example.com"}}, nil)
+	server := httptest.NewServer(http.HandlerFunc(hub.ServeWS))
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	_, resp, err := websocket.DefaultDialer.Dial(url+"/?rate=-1", nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	_, resp, err = websocket.DefaultDialer.Dial(url, http.Header{"Origin": {"https://evil.example.com"}})
+	require.Error(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	waitForClients(t, hub, 0)
+
+	ws, _, err := websocket.DefaultDialer.Dial(url, http.Header{"Origin": {"https://dashboard.example.com"}})
+	require.NoError(t, err)
+	defer ws.Close()
+	_, resp, err = websocket.DefaultDialer.Dial(url, nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
+
+func TestServeWS_ReadLimit(t *testing.T) {
+	hub := NewHub(Config{}, nil)
+	ws := dialHub(t, hub, "")
+	waitForClients(t, hub, 1)
+
+	// A request over maxRequestSize closes the connection
+	params := `["` + strings.Repeat("A", maxRequestSize) + `"]`
+	require.NoError(t, ws.WriteMessage(websocket.TextMessage, []byte(`{"method":"SUBSCRIBE","params":`+params+`,"id":1}`)))
+	waitForClients(t, hub, 0)
+}
+
+func TestServeWS_EvictsSlowConsumer(t *testing.T) {
+	hub := NewHub(Config{SendBuffer: 1}, nil)
+	ws := dialHub(t, hub, "")
+	waitForClients(t, hub, 1)
+
+	// Nothing is read while the hub floods the client
+	for i := int64(0); i < 10000 && hub.GetEvictedCount() == 0; i++ {
+		hub.Process(tick("BTCUSDT", i, 60000))
+	}
+	require.Equal(t, 1, hub.GetEvictedCount())
+	waitForClients(t, hub, 0)
+
+	require.NoError(t, ws.SetReadDeadline(time.Now().Add(time.Second)))
+	for {
+		if _, _, err := ws.ReadMessage(); err != nil {
+			var closeErr *websocket.CloseError
+			if assert.ErrorAs(t, err, &closeErr) {
+				assert.Equal(t, websocket.CloseTryAgainLater, closeErr.Code)
+			}
+			return
+		}
+	}
+}
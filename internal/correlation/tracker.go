@@ -0,0 +1,347 @@
+package correlation
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+const (
+	defaultBenchmark        = "BTCUSDT"
+	defaultSampleInterval   = time.Minute
+	defaultSnapshotInterval = 5 * time.Minute
+	minSamples              = 3
+)
+
+// Config holds the settings for rolling correlations
+type Config struct {
+	Enabled   bool
+	Persist   bool
+	Benchmark string
+	// SampleInterval is the period over which each return is measured
+	SampleInterval time.Duration `mapstructure:"sample_interval"`
+	// Windows are the rolling window lengths, e.g. "1h", "24h"
+	Windows []string
+	// SnapshotInterval is how often snapshots are sent to downstream processors
+	SnapshotInterval time.Duration `mapstructure:"snapshot_interval"`
+}
+
+// CorrelationProcessor receives periodic correlation snapshots from a Tracker
+type CorrelationProcessor interface {
+	ProcessCorrelations(matrix models.CorrelationMatrix)
+}
+
+type window struct {
+	name    string
+	samples int
+}
+
+// series is a ring buffer of a symbol's most recent returns. Every symbol with
+// a price receives a sample at each interval, so all series end on the same
+// sample and can be aligned from the end.
+type series struct {
+	values []float64
+	next   int
+	count  int
+}
+
+func (s *series) push(v float64) {
+	s.values[s.next] = v
+	s.next = (s.next + 1) % len(s.values)
+	if s.count < len(s.values) {
+		s.count++
+	}
+}
+
+// last returns the n most recent values, oldest first
+func (s *series) last(n int) []float64 {
+	result := make([]float64, n)
+	for i := 0; i < n; i++ {
+		idx := (s.next - n + i + len(s.values)) % len(s.values)
+		result[i] = s.values[idx]
+	}
+	return result
+}
+
+// Tracker implements DataProcessor interface and maintains rolling return
+// correlations between all monitored symbols and their betas against a
+// benchmark. Returns are sampled on event-time boundaries of SampleInterval.
+type Tracker struct {
+	benchmark      string
+	interval       int64
+	windows        []window
+	snapshotEvery  time.Duration
+	bucket         int64
+	prices         map[string]float64
+	closes         map[string]float64
+	returns        map[string]*series
+	maxSamples     int
+	lastSample     int64
+	downstream     []CorrelationProcessor
+	mutex          sync.RWMutex
+	processedCount int
+}
+
+// NewTracker creates a new Tracker
+func NewTracker(cfg Config) (*Tracker, error) {
+	if cfg.Benchmark == "" {
+		cfg.Benchmark = defaultBenchmark
+	}
+	if cfg.SampleInterval <= 0 {
+		cfg.SampleInterval = defaultSampleInterval
+	}
+	if cfg.SnapshotInterval <= 0 {
+		cfg.SnapshotInterval = defaultSnapshotInterval
+	}
+	if len(cfg.Windows) == 0 {
+		cfg.Windows = []string{"1h"}
+	}
+
+	tracker := &Tracker{
+		benchmark:     strings.ToUpper(cfg.Benchmark),
+		interval:      cfg.SampleInterval.Milliseconds(),
+		snapshotEvery: cfg.SnapshotInterval,
+		prices:        make(map[string]float64),
+		closes:        make(map[string]float64),
+		returns:       make(map[string]*series),
+	}
+
+	for _, name := range cfg.Windows {
+		d, err := time.ParseDuration(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid correlation window %q: %w", name, err)
+		}
+		samples := int(d / cfg.SampleInterval)
+		if samples < minSamples {
+			return nil, fmt.Errorf("correlation window %q must cover at least %d sample intervals", name, minSamples)
+		}
+		tracker.windows = append(tracker.windows, window{name: name, samples: samples})
+		if samples > tracker.maxSamples {
+			tracker.maxSamples = samples
+		}
+	}
+
+	return tracker, nil
+}
+
+// AddProcessor adds a downstream processor for periodic snapshots
+func (t *Tracker) AddProcessor(proc CorrelationProcessor) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.downstream = append(t.downstream, proc)
+}
+
+// Process implements the DataProcessor interface
+func (t *Tracker) Process(data models.FormattedData) {
+	if data.LastPrice <= 0 {
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	bucket := data.EventTime / t.interval
+	if bucket > t.bucket {
+		if t.bucket != 0 {
+			t.sample(bucket * t.interval)
+		}
+		t.bucket = bucket
+	}
+	t.prices[strings.ToUpper(data.Symbol)] = data.LastPrice
+	t.processedCount++
+}
+
+// sample closes the current interval, pushing a log return for every symbol
+// with a price. The caller must hold the mutex.
+func (t *Tracker) sample(sampleTime int64) {
+	for symbol, price := range t.prices {
+		prev, ok := t.closes[symbol]
+		t.closes[symbol] = price
+		if !ok {
+			continue
+		}
+		s, ok := t.returns[symbol]
+		if !ok {
+			s = &series{values: make([]float64, t.maxSamples)}
+			t.returns[symbol] = s
+		}
+		s.push(math.Log(price / prev))
+	}
+	t.lastSample = sampleTime
+}
+
+// Windows returns the configured window names
+func (t *Tracker) Windows() []string {
+	names := make([]string, len(t.windows))
+	for i, w := range t.windows {
+		names[i] = w.name
+	}
+	return names
+}
+
+// Snapshot computes the correlation matrix and betas for a window
+func (t *Tracker) Snapshot(windowName string) (models.CorrelationMatrix, error) {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	var w *window
+	for i := range t.windows {
+		if t.windows[i].name == windowName {
+			w = &t.windows[i]
+		}
+	}
+	if w == nil {
+		return models.CorrelationMatrix{}, fmt.Errorf("unknown correlation window %q", windowName)
+	}
+
+	symbols := make([]string, 0, len(t.returns))
+	for symbol := range t.returns {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	matrix := models.CorrelationMatrix{
+		Window:       w.name,
+		SnapshotTime: t.lastSample,
+		Benchmark:    t.benchmark,
+		Symbols:      symbols,
+		Correlations: make(map[string]map[string]float64),
+		Betas:        make(map[string]float64),
+	}
+
+	for i, a := range symbols {
+		for _, b := range symbols[i+1:] {
+			x, y := t.aligned(a, b, w.samples)
+			if corr, ok := correlation(x, y); ok {
+				setPair(matrix.Correlations, a, b, corr)
+				setPair(matrix.Correlations, b, a, corr)
+			}
+		}
+		if a == t.benchmark {
+			continue
+		}
+		if _, ok := t.returns[t.benchmark]; ok {
+			x, y := t.aligned(a, t.benchmark, w.samples)
+			if b, ok := beta(x, y); ok {
+				matrix.Betas[a] = b
+			}
+		}
+	}
+
+	return matrix, nil
+}
+
+// aligned returns the overlapping most recent returns of two symbols
+func (t *Tracker) aligned(a, b string, samples int) ([]float64, []float64) {
+	sa, sb := t.returns[a], t.returns[b]
+	n := samples
+	if sa.count < n {
+		n = sa.count
+	}
+	if sb.count < n {
+		n = sb.count
+	}
+	return sa.last(n), sb.last(n)
+}
+
+// Run sends a snapshot of every window to the downstream processors each
+// snapshot interval until stop is closed
+func (t *Tracker) Run(stop chan struct{}) {
+	ticker := time.NewTicker(t.snapshotEvery)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			t.publish()
+		}
+	}
+}
+
+func (t *Tracker) publish() {
+	t.mutex.RLock()
+	downstream := t.downstream
+	t.mutex.RUnlock()
+
+	for _, name := range t.Windows() {
+		matrix, err := t.Snapshot(name)
+		if err != nil {
+			log.Printf("Error taking correlation snapshot: %v", err)
+			continue
+		}
+		if len(matrix.Symbols) == 0 {
+			continue
+		}
+		for _, proc := range downstream {
+			proc.ProcessCorrelations(matrix)
+		}
+	}
+}
+
+// GetProcessedCount returns the number of processed messages
+func (t *Tracker) GetProcessedCount() int {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	return t.processedCount
+}
+
+// GetBufferSize returns the current size of the buffer (always 0, returns are sampled in place)
+func (t *Tracker) GetBufferSize() int {
+	return 0
+}
+
+func setPair(m map[string]map[string]float64, a, b string, v float64) {
+	if m[a] == nil {
+		m[a] = make(map[string]float64)
+	}
+	m[a][b] = v
+}
+
+func moments(x, y []float64) (covariance, varX, varY float64) {
+	n := float64(len(x))
+	var meanX, meanY float64
+	for i := range x {
+		meanX += x[i]
+		meanY += y[i]
+	}
+	meanX /= n
+	meanY /= n
+	for i := range x {
+		dx, dy := x[i]-meanX, y[i]-meanY
+		covariance += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	return covariance / n, varX / n, varY / n
+}
+
+func correlation(x, y []float64) (float64, bool) {
+	if len(x) < minSamples {
+		return 0, false
+	}
+	covariance, varX, varY := moments(x, y)
+	if varX == 0 || varY == 0 {
+		return 0, false
+	}
+	return covariance / math.Sqrt(varX*varY), true
+}
+
+// beta returns the beta of x against the benchmark returns y
+func beta(x, y []float64) (float64, bool) {
+	if len(x) < minSamples {
+		return 0, false
+	}
+	covariance, _, varY := moments(x, y)
+	if varY == 0 {
+		return 0, false
+	}
+	return covariance / varY, true
+}
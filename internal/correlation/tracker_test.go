@@ -0,0 +1,122 @@
+package correlation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+type mockCorrelationProcessor struct {
+	matrices []models.CorrelationMatrix
+}
+
+func (m *mockCorrelationProcessor) ProcessCorrelations(matrix models.CorrelationMatrix) {
+	m.matrices = append(m.matrices, matrix)
+}
+
+func tick(eventTime int64, symbol string, price float64) models.FormattedData {
+	return models.FormattedData{EventTime: eventTime, Symbol: symbol, LastPrice: price}
+}
+
+func newTestTracker(t *testing.T, windows ...string) *Tracker {
+	tracker, err := NewTracker(Config{SampleInterval: time.Second, Windows: windows})
+	require.NoError(t, err)
+	return tracker
+}
+
+func TestTracker_CorrelationAndBeta(t *testing.T) {
+	tracker := newTestTracker(t, "10s")
+
+	btc := []float64{100, 102, 101, 104, 103, 105, 107}
+	for i, price := range btc {
+		eventTime := int64(i+1) * 1000
+		tracker.Process(tick(eventTime, "BTCUSDT", price))
+		// ETH moves twice as much as BTC in relative terms
+		tracker.Process(tick(eventTime, "ETHUSDT", 50*(price/100)*(price/100)))
+		// LTC moves against BTC
+		tracker.Process(tick(eventTime, "LTCUSDT", 10000/price))
+	}
+	tracker.Process(tick(8000, "BTCUSDT", 107))
+
+	matrix, err := tracker.Snapshot("10s")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"BTCUSDT", "ETHUSDT", "LTCUSDT"}, matrix.Symbols)
+	assert.Equal(t, "BTCUSDT", matrix.Benchmark)
+	assert.Equal(t, int64(8000), matrix.SnapshotTime)
+	assert.InDelta(t, 1.0, matrix.Correlations["BTCUSDT"]["ETHUSDT"], 1e-9)
+	assert.InDelta(t, 1.0, matrix.Correlations["ETHUSDT"]["BTCUSDT"], 1e-9)
+	assert.InDelta(t, -1.0, matrix.Correlations["BTCUSDT"]["LTCUSDT"], 1e-9)
+	assert.InDelta(t, 2.0, matrix.Betas["ETHUSDT"], 1e-9)
+	assert.InDelta(t, -1.0, matrix.Betas["LTCUSDT"], 1e-9)
+	assert.NotContains(t, matrix.Betas, "BTCUSDT")
+	assert.Equal(t, 22, tracker.GetProcessedCount())
+}
+
+func TestTracker_WindowsLimitSamples(t *testing.T) {
+	tracker := newTestTracker(t, "3s", "1m")
+
+	// Correlated early on, then the relationship reverses
+	btc := []float64{100, 101, 102, 103, 104, 105, 104, 105, 104}
+	eth := []float64{100, 101, 102, 103, 104, 105, 106, 105, 106}
+	for i := range btc {
+		eventTime := int64(i+1) * 1000
+		tracker.Process(tick(eventTime, "BTCUSDT", btc[i]))
+		tracker.Process(tick(eventTime, "ETHUSDT", eth[i]))
+	}
+	tracker.Process(tick(10000, "BTCUSDT", 104))
+
+	short, err := tracker.Snapshot("3s")
+	require.NoError(t, err)
+	long, err := tracker.Snapshot("1m")
+	require.NoError(t, err)
+
+	assert.Less(t, short.Correlations["BTCUSDT"]["ETHUSDT"], 0.0)
+	assert.Greater(t, long.Correlations["BTCUSDT"]["ETHUSDT"], short.Correlations["BTCUSDT"]["ETHUSDT"])
+	assert.Equal(t, []string{"3s", "1m"}, tracker.Windows())
+}
+
+func TestTracker_InsufficientSamples(t *testing.T) {
+	tracker := newTestTracker(t, "10s")
+
+	tracker.Process(tick(1000, "BTCUSDT", 100))
+	tracker.Process(tick(1000, "ETHUSDT", 10))
+	tracker.Process(tick(2000, "BTCUSDT", 101))
+	tracker.Process(tick(3000, "BTCUSDT", 102))
+
+	matrix, err := tracker.Snapshot("10s")
+	require.NoError(t, err)
+	assert.Empty(t, matrix.Correlations)
+	assert.Empty(t, matrix.Betas)
+
+	_, err = tracker.Snapshot("1h")
+	assert.Error(t, err)
+}
+
+func TestTracker_Publish(t *testing.T) {
+	tracker := newTestTracker(t, "10s")
+	sink := &mockCorrelationProcessor{}
+	tracker.AddProcessor(sink)
+
+	tracker.publish()
+	assert.Empty(t, sink.matrices)
+
+	tracker.Process(tick(1000, "BTCUSDT", 100))
+	tracker.Process(tick(2000, "BTCUSDT", 101))
+	tracker.Process(tick(3000, "BTCUSDT", 102))
+	tracker.publish()
+
+	require.Len(t, sink.matrices, 1)
+	assert.Equal(t, "10s", sink.matrices[0].Window)
+}
+
+func TestNewTracker_InvalidWindows(t *testing.T) {
+	_, err := NewTracker(Config{Windows: []string{"soon"}})
+	assert.Error(t, err)
+
+	_, err = NewTracker(Config{SampleInterval: time.Minute, Windows: []string{"2m"}})
+	assert.Error(t, err)
+}
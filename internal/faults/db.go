@@ -0,0 +1,167 @@
+package faults
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInjected is returned by statements that FailNext made fail
+var ErrInjected = errors.New("injected database error")
+
+// Row is a ticker_data row stored by a DB
+type Row struct {
+	EventTime  int64
+	Symbol     string
+	InsertedAt time.Time
+}
+
+// ErrDuplicate is returned by inserts of an already stored (symbol,
+// event_time) key without ON CONFLICT
+var ErrDuplicate = errors.New("duplicate key value violates unique constraint")
+
+type rowKey struct {
+	symbol    string
+	eventTime int64
+}
+
+// DB is an in-memory database/sql driver that stores ticker_data inserts,
+// unique on (symbol, event_time), and can fail or slow down statements on
+// demand. Other statements succeed without effect.
+type DB struct {
+	rows     []Row
+	keys     map[rowKey]bool
+	failNext int
+	latency  time.Duration
+	failed   int
+	mutex    sync.Mutex
+}
+
+// NewDB creates a new DB and the *sql.DB handle to pass to writers
+func NewDB() (*DB, *sql.DB) {
+	db := &DB{keys: make(map[rowKey]bool)}
+	return db, sql.OpenDB(db)
+}
+
+// FailNext makes the next n statements return ErrInjected
+func (d *DB) FailNext(n int) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.failNext = n
+}
+
+// SetLatency delays every statement; statements whose context expires first
+// return the context's error
+func (d *DB) SetLatency(latency time.Duration) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.latency = latency
+}
+
+// Rows returns the stored rows in insertion order
+func (d *DB) Rows() []Row {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return append([]Row(nil), d.rows...)
+}
+
+// GetFailedCount returns the number of statements that failed or timed out
+func (d *DB) GetFailedCount() int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.failed
+}
+
+// Connect implements driver.Connector
+func (d *DB) Connect(ctx context.Context) (driver.Conn, error) {
+	return &conn{db: d}, nil
+}
+
+// Driver implements driver.Connector
+func (d *DB) Driver() driver.Driver {
+	return dbDriver{db: d}
+}
+
+// exec runs a statement and returns the number of rows it stored
+func (d *DB) exec(ctx context.Context, query string, args []driver.NamedValue) (int64, error) {
+	d.mutex.Lock()
+	latency := d.latency
+	fail := d.failNext > 0
+	if fail {
+		d.failNext--
+	}
+	d.mutex.Unlock()
+
+	if latency > 0 {
+		select {
+		case <-ctx.Done():
+			d.mutex.Lock()
+			d.failed++
+			d.mutex.Unlock()
+			return 0, ctx.Err()
+		case <-time.After(latency):
+		}
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if fail {
+		d.failed++
+		return 0, ErrInjected
+	}
+	if !strings.Contains(query, "INSERT INTO ticker_data") {
+		return 0, nil
+	}
+	if len(args) < 2 {
+		return 0, fmt.Errorf("ticker_data insert needs event_time and symbol, got %d arguments", len(args))
+	}
+	eventTime, _ := args[0].Value.(int64)
+	symbol, _ := args[1].Value.(string)
+	key := rowKey{symbol: symbol, eventTime: eventTime}
+	if d.keys[key] {
+		if strings.Contains(query, "ON CONFLICT") {
+			return 0, nil
+		}
+		return 0, ErrDuplicate
+	}
+	d.keys[key] = true
+	d.rows = append(d.rows, Row{EventTime: eventTime, Symbol: symbol, InsertedAt: time.Now()})
+	return 1, nil
+}
+
+type dbDriver struct {
+	db *DB
+}
+
+func (d dbDriver) Open(name string) (driver.Conn, error) {
+	return &conn{db: d.db}, nil
+}
+
+type conn struct {
+	db *DB
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	rows, err := c.db.exec(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(rows), nil
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("faults: prepared statements are not supported")
+}
+
+func (c *conn) Close() error {
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return nil, errors.New("faults: transactions are not supported")
+}
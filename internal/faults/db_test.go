@@ -0,0 +1,75 @@
+package faults
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const insert = `INSERT INTO ticker_data (event_time, symbol) VALUES ($1, $2)`
+
+func TestDB_RecordsInserts(t *testing.T) {
+	db, sqlDB := NewDB()
+	defer sqlDB.Close()
+
+	_, err := sqlDB.Exec(insert, int64(1000), "btcusdt")
+	require.NoError(t, err)
+	_, err = sqlDB.Exec(`UPDATE candles SET close = 1`)
+	require.NoError(t, err)
+
+	rows := db.Rows()
+	require.Len(t, rows, 1)
+	assert.Equal(t, int64(1000), rows[0].EventTime)
+	assert.Equal(t, "btcusdt", rows[0].Symbol)
+}
+
+func TestDB_UniqueKey(t *testing.T) {
+	db, sqlDB := NewDB()
+	defer sqlDB.Close()
+
+	_, err := sqlDB.Exec(insert, int64(1000), "btcusdt")
+	require.NoError(t, err)
+	_, err = sqlDB.Exec(insert, int64(1000), "btcusdt")
+	assert.ErrorIs(t, err, ErrDuplicate)
+
+	result, err := sqlDB.Exec(insert+` ON CONFLICT (symbol, event_time) DO NOTHING`, int64(1000), "btcusdt")
+	require.NoError(t, err)
+	rows, err := result.RowsAffected()
+	require.NoError(t, err)
+	assert.Zero(t, rows)
+	assert.Len(t, db.Rows(), 1)
+}
+
+func TestDB_FailNext(t *testing.T) {
+	db, sqlDB := NewDB()
+	defer sqlDB.Close()
+
+	db.FailNext(2)
+	for i := 0; i < 3; i++ {
+		_, err := sqlDB.Exec(insert, int64(i), "btcusdt")
+		if i < 2 {
+			assert.ErrorIs(t, err, ErrInjected)
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+	assert.Len(t, db.Rows(), 1)
+	assert.Equal(t, 2, db.GetFailedCount())
+}
+
+func TestDB_LatencyHonoursContext(t *testing.T) {
+	db, sqlDB := NewDB()
+	defer sqlDB.Close()
+
+	db.SetLatency(time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := sqlDB.ExecContext(ctx, insert, int64(1), "btcusdt")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Empty(t, db.Rows())
+	assert.Equal(t, 1, db.GetFailedCount())
+}
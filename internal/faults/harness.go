@@ -0,0 +1,279 @@
+package faults
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/processor"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/simulator"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/websocket"
+)
+
+const (
+	defaultSymbol       = "BTCUSDT"
+	defaultTickInterval = 20 * time.Millisecond
+	defaultReadTimeout  = 250 * time.Millisecond
+	defaultExecTimeout  = 100 * time.Millisecond
+	startTimeout        = 5 * time.Second
+)
+
+// Config holds the settings of a fault-injection Harness
+type Config struct {
+	Symbol string
+	// TickInterval is how often the simulator emits a ticker
+	TickInterval time.Duration
+	// ReadTimeout is the client's half-open connection timeout
+	ReadTimeout time.Duration
+	Reconnect   websocket.ReconnectConfig
+	// MaxAttempts, RetryDelay and ExecTimeout make up the writer's retry policy
+	MaxAttempts int
+	RetryDelay  time.Duration
+	ExecTimeout time.Duration
+}
+
+// Report summarises how much of the simulated feed was persisted
+type Report struct {
+	// Expected is the number of ticks the simulator emitted
+	Expected int
+	// Persisted is the number of distinct emitted ticks that were stored
+	Persisted int
+	// Duplicates is the number of rows storing an already stored tick
+	Duplicates int
+	// Deduplicated is the number of duplicate ticks the writer skipped
+	Deduplicated int
+	// OutOfOrder is the number of rows stored after a later tick
+	OutOfOrder   int
+	Completeness float64
+	Reconnects   int
+	WriteFailed  int
+}
+
+// Harness runs a monitor pipeline - simulator, fault proxy, websocket client
+// and PGWriter on a fault-injecting DB - so that scenarios can inject
+// failures and assert on data completeness and recovery time
+type Harness struct {
+	Simulator *simulator.Simulator
+	Proxy     *Proxy
+	DB        *DB
+	Client    *websocket.Client
+	Writer    *processor.PGWriter
+
+	tickInterval time.Duration
+	simServer    *httptest.Server
+	proxyServer  *httptest.Server
+	emitted      []int64
+	stop         chan struct{}
+	stopTicks    chan struct{}
+	wg           sync.WaitGroup
+	mutex        sync.Mutex
+}
+
+// NewHarness creates a new Harness with the client connected through the proxy
+func NewHarness(cfg Config) (*Harness, error) {
+	if cfg.Symbol == "" {
+		cfg.Symbol = defaultSymbol
+	}
+	if cfg.TickInterval <= 0 {
+		cfg.TickInterval = defaultTickInterval
+	}
+	if cfg.ReadTimeout <= 0 {
+		cfg.ReadTimeout = defaultReadTimeout
+	}
+	if cfg.Reconnect.MinBackoff <= 0 {
+		cfg.Reconnect = websocket.ReconnectConfig{MinBackoff: 10 * time.Millisecond, MaxBackoff: 100 * time.Millisecond}
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 3
+	}
+	if cfg.ExecTimeout <= 0 {
+		cfg.ExecTimeout = defaultExecTimeout
+	}
+
+	sim, err := simulator.NewSimulator(simulator.Config{
+		Symbols: map[string]float64{cfg.Symbol: 50000},
+		Seed:    1,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	h := &Harness{
+		Simulator:    sim,
+		tickInterval: cfg.TickInterval,
+		stop:         make(chan struct{}),
+		stopTicks:    make(chan struct{}),
+	}
+	h.simServer = httptest.NewServer(sim.Handler())
+	h.Proxy = NewProxy(wsURL(h.simServer))
+	h.proxyServer = httptest.NewServer(h.Proxy)
+
+	var sqlDB *sql.DB
+	h.DB, sqlDB = NewDB()
+	h.Writer, err = processor.NewPGWriter(sqlDB)
+	if err != nil {
+		h.closeServers()
+		return nil, err
+	}
+	h.Writer.SetRetryPolicy(cfg.MaxAttempts, cfg.RetryDelay, cfg.ExecTimeout)
+
+	h.Client = websocket.NewClient()
+	h.Client.SetReconnect(cfg.Reconnect)
+	h.Client.SetReadTimeout(cfg.ReadTimeout)
+	h.Client.AddProcessor(h.Writer)
+	uri := fmt.Sprintf("%s/ws/%s@ticker", wsURL(h.proxyServer), strings.ToLower(cfg.Symbol))
+	if err := h.Client.Connect(uri); err != nil {
+		h.Writer.Close()
+		h.closeServers()
+		return nil, err
+	}
+
+	return h, nil
+}
+
+func wsURL(server *httptest.Server) string {
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+// Start begins listening and, once the stream is subscribed, ticking the
+// simulator every TickInterval
+func (h *Harness) Start() error {
+	deadline := time.Now().Add(startTimeout)
+	for h.Simulator.Connections() == 0 {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("client did not reach the simulator within %s", startTimeout)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	h.wg.Add(2)
+	go func() {
+		defer h.wg.Done()
+		h.Client.Listen(h.stop)
+	}()
+	go func() {
+		defer h.wg.Done()
+		h.tick()
+	}()
+	return nil
+}
+
+func (h *Harness) tick() {
+	ticker := time.NewTicker(h.tickInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-h.stop:
+			return
+		case <-h.stopTicks:
+			return
+		case now := <-ticker.C:
+			h.mutex.Lock()
+			h.emitted = append(h.emitted, now.UnixMilli())
+			h.mutex.Unlock()
+			h.Simulator.Tick(now)
+		}
+	}
+}
+
+// WaitForRecovery waits for the first row stored after since, returning how
+// long after since it was stored
+func (h *Harness) WaitForRecovery(since time.Time, timeout time.Duration) (time.Duration, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		for _, row := range h.DB.Rows() {
+			if row.InsertedAt.After(since) {
+				return row.InsertedAt.Sub(since), nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return 0, fmt.Errorf("no data stored within %s of the fault", timeout)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// Finish stops the simulator, waits for frames in flight to be stored and
+// reports on completeness. The pipeline keeps running until Close.
+func (h *Harness) Finish(settle time.Duration) Report {
+	close(h.stopTicks)
+
+	// Wait until no row has been stored for the settle period
+	count := len(h.DB.Rows())
+	for {
+		time.Sleep(settle)
+		next := len(h.DB.Rows())
+		if next == count {
+			break
+		}
+		count = next
+	}
+
+	return h.Report()
+}
+
+// Report compares the emitted ticks with the stored rows
+func (h *Harness) Report() Report {
+	h.mutex.Lock()
+	emitted := append([]int64(nil), h.emitted...)
+	h.mutex.Unlock()
+
+	expected := make(map[int64]bool, len(emitted))
+	for _, eventTime := range emitted {
+		expected[eventTime] = true
+	}
+
+	report := Report{
+		Expected:     len(emitted),
+		Reconnects:   h.Client.GetReconnectCount(),
+		WriteFailed:  h.Writer.GetFailedCount(),
+		Deduplicated: h.Writer.GetDuplicateCount(),
+	}
+	stored := make(map[int64]bool)
+	var latest int64
+	for _, row := range h.DB.Rows() {
+		if stored[row.EventTime] {
+			report.Duplicates++
+			continue
+		}
+		stored[row.EventTime] = true
+		if expected[row.EventTime] {
+			report.Persisted++
+		}
+		if row.EventTime < latest {
+			report.OutOfOrder++
+		}
+		if row.EventTime > latest {
+			latest = row.EventTime
+		}
+	}
+	if report.Expected > 0 {
+		report.Completeness = float64(report.Persisted) / float64(report.Expected)
+	}
+	return report
+}
+
+// Close stops the pipeline and the servers
+func (h *Harness) Close() {
+	select {
+	case <-h.stop:
+		return
+	default:
+	}
+	close(h.stop)
+	h.Proxy.Disconnect()
+	h.wg.Wait()
+	h.Client.Close()
+	h.Writer.Close()
+	h.closeServers()
+}
+
+func (h *Harness) closeServers() {
+	h.Simulator.Close()
+	h.proxyServer.Close()
+	h.simServer.Close()
+}
@@ -0,0 +1,157 @@
+package faults
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const settle = 100 * time.Millisecond
+
+func startHarness(t *testing.T, cfg Config) *Harness {
+	t.Helper()
+	h, err := NewHarness(cfg)
+	require.NoError(t, err)
+	t.Cleanup(h.Close)
+	require.NoError(t, h.Start())
+	return h
+}
+
+func TestHarness_Baseline(t *testing.T) {
+	h := startHarness(t, Config{})
+	time.Sleep(300 * time.Millisecond)
+
+	report := h.Finish(settle)
+	assert.Greater(t, report.Expected, 5)
+	assert.Equal(t, 1.0, report.Completeness)
+	assert.Zero(t, report.Duplicates)
+	assert.Zero(t, report.Reconnects)
+}
+
+func TestHarness_Disconnect(t *testing.T) {
+	h := startHarness(t, Config{})
+	time.Sleep(200 * time.Millisecond)
+
+	fault := time.Now()
+	h.Proxy.Disconnect()
+	recovery, err := h.WaitForRecovery(fault, 2*time.Second)
+	require.NoError(t, err)
+	assert.Less(t, recovery, time.Second)
+	time.Sleep(200 * time.Millisecond)
+
+	report := h.Finish(settle)
+	assert.Equal(t, 1, report.Reconnects)
+	assert.GreaterOrEqual(t, report.Completeness, 0.7)
+}
+
+func TestHarness_HalfOpenConnection(t *testing.T) {
+	h := startHarness(t, Config{ReadTimeout: 200 * time.Millisecond})
+	time.Sleep(200 * time.Millisecond)
+
+	fault := time.Now()
+	h.Proxy.HalfOpen()
+	recovery, err := h.WaitForRecovery(fault, 2*time.Second)
+	require.NoError(t, err)
+	// Nothing arrives until the read timeout gives up on the connection
+	assert.Greater(t, recovery, 100*time.Millisecond)
+	assert.Less(t, recovery, time.Second)
+	time.Sleep(600 * time.Millisecond)
+
+	report := h.Finish(settle)
+	assert.Equal(t, 1, report.Reconnects)
+	assert.Less(t, report.Completeness, 1.0)
+	assert.GreaterOrEqual(t, report.Completeness, 0.6)
+}
+
+func TestHarness_SlowReads(t *testing.T) {
+	h := startHarness(t, Config{})
+	// Frames arrive slower than they are produced and queue up upstream
+	h.Proxy.SetDelay(30 * time.Millisecond)
+	time.Sleep(300 * time.Millisecond)
+
+	report := h.Finish(settle)
+	assert.Equal(t, 1.0, report.Completeness)
+	assert.Zero(t, report.Reconnects)
+}
+
+func TestHarness_MalformedJSON(t *testing.T) {
+	h := startHarness(t, Config{})
+	h.Proxy.InjectMalformed(5)
+	time.Sleep(300 * time.Millisecond)
+
+	report := h.Finish(settle)
+	assert.Equal(t, 1.0, report.Completeness)
+	assert.Zero(t, report.Reconnects)
+}
+
+func TestHarness_DuplicatedMessages(t *testing.T) {
+	h := startHarness(t, Config{})
+	h.Proxy.SetDuplicate(true)
+	time.Sleep(200 * time.Millisecond)
+
+	report := h.Finish(settle)
+	assert.Equal(t, 1.0, report.Completeness)
+	assert.Zero(t, report.Duplicates)
+	assert.Greater(t, report.Deduplicated, 0)
+}
+
+func TestHarness_OutOfOrderMessages(t *testing.T) {
+	h := startHarness(t, Config{})
+	h.Proxy.SetReorder(true)
+	time.Sleep(200 * time.Millisecond)
+	// The last held frame is released by the next one
+	h.Proxy.SetReorder(false)
+	time.Sleep(100 * time.Millisecond)
+
+	report := h.Finish(settle)
+	assert.Equal(t, 1.0, report.Completeness)
+	assert.Greater(t, report.OutOfOrder, 0)
+}
+
+func TestHarness_DatabaseErrorsRetried(t *testing.T) {
+	h := startHarness(t, Config{MaxAttempts: 3})
+	time.Sleep(100 * time.Millisecond)
+	h.DB.FailNext(2)
+	time.Sleep(200 * time.Millisecond)
+
+	report := h.Finish(settle)
+	assert.Equal(t, 1.0, report.Completeness)
+	assert.Zero(t, report.WriteFailed)
+	assert.Equal(t, 2, h.DB.GetFailedCount())
+}
+
+func TestHarness_DatabaseOutage(t *testing.T) {
+	h := startHarness(t, Config{MaxAttempts: 3})
+	time.Sleep(100 * time.Millisecond)
+
+	fault := time.Now()
+	h.DB.FailNext(9)
+	recovery, err := h.WaitForRecovery(fault, 2*time.Second)
+	require.NoError(t, err)
+	assert.Less(t, recovery, time.Second)
+	time.Sleep(100 * time.Millisecond)
+
+	report := h.Finish(settle)
+	assert.Equal(t, 3, report.WriteFailed)
+	assert.Equal(t, report.Expected-3, report.Persisted)
+}
+
+func TestHarness_DatabaseTimeouts(t *testing.T) {
+	h := startHarness(t, Config{MaxAttempts: 1, ExecTimeout: 20 * time.Millisecond})
+	time.Sleep(100 * time.Millisecond)
+
+	h.DB.SetLatency(time.Second)
+	time.Sleep(150 * time.Millisecond)
+	cleared := time.Now()
+	h.DB.SetLatency(0)
+	recovery, err := h.WaitForRecovery(cleared, 2*time.Second)
+	require.NoError(t, err)
+	assert.Less(t, recovery, 200*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+
+	report := h.Finish(settle)
+	assert.Greater(t, report.WriteFailed, 0)
+	assert.Equal(t, report.Expected-report.WriteFailed, report.Persisted)
+}
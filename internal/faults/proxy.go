@@ -0,0 +1,225 @@
+package faults
+
+import (
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// malformedFrame is a truncated ticker event that cannot be parsed
+var malformedFrame = []byte(`{"e":"24hrTicker","E":17000000`)
+
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// proxyConn is a client connection and its upstream counterpart
+type proxyConn struct {
+	client   *websocket.Conn
+	upstream *websocket.Conn
+	stalled  bool
+	once     sync.Once
+}
+
+func (c *proxyConn) close() {
+	c.once.Do(func() {
+		c.client.Close()
+		c.upstream.Close()
+	})
+}
+
+// Proxy is a websocket proxy that sits between a client and an upstream
+// stream and injects faults into the frames it forwards
+type Proxy struct {
+	upstream  string
+	conns     map[*proxyConn]struct{}
+	delay     time.Duration
+	malformed int
+	duplicate bool
+	reorder   bool
+	forwarded int
+	mutex     sync.Mutex
+}
+
+// NewProxy creates a new Proxy forwarding to the upstream websocket base URL,
+// e.g. ws://localhost:8090. Request paths and queries are passed through.
+func NewProxy(upstream string) *Proxy {
+	return &Proxy{
+		upstream: upstream,
+		conns:    make(map[*proxyConn]struct{}),
+	}
+}
+
+// Disconnect drops every proxied connection
+func (p *Proxy) Disconnect() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	for c := range p.conns {
+		c.close()
+	}
+}
+
+// HalfOpen silently stops forwarding frames on the current connections while
+// keeping them open. New connections are not affected.
+func (p *Proxy) HalfOpen() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	for c := range p.conns {
+		c.stalled = true
+	}
+}
+
+// SetDelay delays every forwarded frame, simulating a slow reader
+func (p *Proxy) SetDelay(delay time.Duration) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.delay = delay
+}
+
+// InjectMalformed sends a malformed frame before each of the next n frames
+func (p *Proxy) InjectMalformed(n int) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.malformed += n
+}
+
+// SetDuplicate sends every frame twice while enabled
+func (p *Proxy) SetDuplicate(enabled bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.duplicate = enabled
+}
+
+// SetReorder swaps every pair of consecutive frames while enabled
+func (p *Proxy) SetReorder(enabled bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.reorder = enabled
+}
+
+// Connections returns the number of open proxied connections
+func (p *Proxy) Connections() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return len(p.conns)
+}
+
+// GetForwardedCount returns the number of upstream frames written to clients
+func (p *Proxy) GetForwardedCount() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.forwarded
+}
+
+// ServeHTTP implements http.Handler, dialing the upstream for every client
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	uri := p.upstream + r.URL.Path
+	if r.URL.RawQuery != "" {
+		uri += "?" + r.URL.RawQuery
+	}
+	upstream, _, err := websocket.DefaultDialer.Dial(uri, nil)
+	if err != nil {
+		log.Printf("Fault proxy cannot reach %s: %v", uri, err)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	client, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("Fault proxy websocket upgrade error: %v", err)
+		upstream.Close()
+		return
+	}
+
+	c := &proxyConn{client: client, upstream: upstream}
+	p.mutex.Lock()
+	p.conns[c] = struct{}{}
+	p.mutex.Unlock()
+
+	defer func() {
+		c.close()
+		p.mutex.Lock()
+		delete(p.conns, c)
+		p.mutex.Unlock()
+	}()
+
+	go p.forwardRequests(c)
+	p.forwardFrames(c)
+}
+
+// forwardRequests passes client messages such as SUBSCRIBE upstream
+func (p *Proxy) forwardRequests(c *proxyConn) {
+	defer c.close()
+	for {
+		messageType, message, err := c.client.ReadMessage()
+		if err != nil {
+			return
+		}
+		if err := c.upstream.WriteMessage(messageType, message); err != nil {
+			return
+		}
+	}
+}
+
+// forwardFrames passes upstream frames to the client, applying the faults
+// configured when each frame arrives
+func (p *Proxy) forwardFrames(c *proxyConn) {
+	var held []byte
+	for {
+		_, message, err := c.upstream.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		p.mutex.Lock()
+		stalled := c.stalled
+		delay := p.delay
+		malformed := p.malformed > 0
+		if malformed && !stalled {
+			p.malformed--
+		}
+		duplicate := p.duplicate
+		reorder := p.reorder
+		p.mutex.Unlock()
+
+		if stalled {
+			continue
+		}
+		if delay > 0 {
+			time.Sleep(delay)
+		}
+		if reorder && held == nil {
+			held = message
+			continue
+		}
+
+		frames := [][]byte{message}
+		if held != nil {
+			frames = append(frames, held)
+			held = nil
+		}
+		for _, frame := range frames {
+			if malformed {
+				if err := c.client.WriteMessage(websocket.TextMessage, malformedFrame); err != nil {
+					return
+				}
+				malformed = false
+			}
+			copies := 1
+			if duplicate {
+				copies = 2
+			}
+			for i := 0; i < copies; i++ {
+				if err := c.client.WriteMessage(websocket.TextMessage, frame); err != nil {
+					return
+				}
+			}
+			p.mutex.Lock()
+			p.forwarded++
+			p.mutex.Unlock()
+		}
+	}
+}
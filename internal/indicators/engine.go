@@ -0,0 +1,207 @@
+package indicators
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+// Config holds the indicator periods. Zero values fall back to the defaults.
+type Config struct {
+	Enabled         bool
+	Persist         bool
+	SMAPeriod       int     `mapstructure:"sma_period"`
+	EMAPeriod       int     `mapstructure:"ema_period"`
+	RSIPeriod       int     `mapstructure:"rsi_period"`
+	MACDFast        int     `mapstructure:"macd_fast"`
+	MACDSlow        int     `mapstructure:"macd_slow"`
+	MACDSignal      int     `mapstructure:"macd_signal"`
+	BollingerPeriod int     `mapstructure:"bollinger_period"`
+	BollingerWidth  float64 `mapstructure:"bollinger_width"`
+	ATRPeriod       int     `mapstructure:"atr_period"`
+}
+
+func (c Config) withDefaults() Config {
+	setDefault := func(v *int, d int) {
+		if *v <= 0 {
+			*v = d
+		}
+	}
+	setDefault(&c.SMAPeriod, 20)
+	setDefault(&c.EMAPeriod, 20)
+	setDefault(&c.RSIPeriod, 14)
+	setDefault(&c.MACDFast, 12)
+	setDefault(&c.MACDSlow, 26)
+	setDefault(&c.MACDSignal, 9)
+	setDefault(&c.BollingerPeriod, 20)
+	setDefault(&c.ATRPeriod, 14)
+	if c.BollingerWidth <= 0 {
+		c.BollingerWidth = 2
+	}
+	return c
+}
+
+// IndicatorProcessor receives updated indicator values from an Engine
+type IndicatorProcessor interface {
+	ProcessIndicators(values models.IndicatorValues)
+}
+
+type seriesKey struct {
+	symbol   string
+	interval string
+}
+
+type series struct {
+	sma       *SMA
+	ema       *EMA
+	rsi       *RSI
+	macd      *MACD
+	bollinger *Bollinger
+	atr       *ATR
+	vwap      *VWAP
+}
+
+// Engine implements the CandleProcessor interface and incrementally updates
+// the indicators of every symbol and interval as candles close
+type Engine struct {
+	cfg            Config
+	series         map[seriesKey]*series
+	latest         map[seriesKey]models.IndicatorValues
+	downstream     []IndicatorProcessor
+	mutex          sync.RWMutex
+	processedCount int
+}
+
+// NewEngine creates a new Engine
+func NewEngine(cfg Config) *Engine {
+	return &Engine{
+		cfg:    cfg.withDefaults(),
+		series: make(map[seriesKey]*series),
+		latest: make(map[seriesKey]models.IndicatorValues),
+	}
+}
+
+// AddProcessor adds a downstream processor for indicator updates
+func (e *Engine) AddProcessor(proc IndicatorProcessor) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.downstream = append(e.downstream, proc)
+}
+
+// ProcessCandle implements the CandleProcessor interface
+func (e *Engine) ProcessCandle(candle models.Candle) {
+	e.mutex.Lock()
+	key := seriesKey{symbol: strings.ToUpper(candle.Symbol), interval: candle.Interval}
+	s, ok := e.series[key]
+	if !ok {
+		s = e.newSeries()
+		e.series[key] = s
+	}
+
+	s.sma.Update(candle.Close)
+	s.ema.Update(candle.Close)
+	s.rsi.Update(candle.Close)
+	s.macd.Update(candle.Close)
+	s.bollinger.Update(candle.Close)
+	s.atr.Update(candle)
+	s.vwap.Update(candle)
+
+	values := models.IndicatorValues{
+		Symbol:   key.symbol,
+		Interval: candle.Interval,
+		OpenTime: candle.OpenTime,
+	}
+	if s.sma.Ready() {
+		values.SMA = float(s.sma.Value())
+	}
+	if s.ema.Ready() {
+		values.EMA = float(s.ema.Value())
+	}
+	if s.rsi.Ready() {
+		values.RSI = float(s.rsi.Value())
+	}
+	if s.macd.Ready() {
+		values.MACD = float(s.macd.Value())
+		values.MACDSignal = float(s.macd.Signal())
+		values.MACDHistogram = float(s.macd.Histogram())
+	}
+	if s.bollinger.Ready() {
+		upper, middle, lower := s.bollinger.Bands()
+		values.BollingerUpper = float(upper)
+		values.BollingerMiddle = float(middle)
+		values.BollingerLower = float(lower)
+	}
+	if s.atr.Ready() {
+		values.ATR = float(s.atr.Value())
+	}
+	if s.vwap.Ready() {
+		values.VWAP = float(s.vwap.Value())
+	}
+
+	e.latest[key] = values
+	e.processedCount++
+	downstream := e.downstream
+	e.mutex.Unlock()
+
+	for _, proc := range downstream {
+		proc.ProcessIndicators(values)
+	}
+}
+
+// Latest returns the most recent indicator values for a symbol and interval
+func (e *Engine) Latest(symbol, interval string) (models.IndicatorValues, bool) {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	values, ok := e.latest[seriesKey{symbol: strings.ToUpper(symbol), interval: interval}]
+	return values, ok
+}
+
+// LatestForSymbol returns the most recent indicator values of every interval
+// tracked for a symbol
+func (e *Engine) LatestForSymbol(symbol string) []models.IndicatorValues {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	symbol = strings.ToUpper(symbol)
+	var result []models.IndicatorValues
+	for key, values := range e.latest {
+		if key.symbol == symbol {
+			result = append(result, values)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return intervalDuration(result[i].Interval) < intervalDuration(result[j].Interval)
+	})
+	return result
+}
+
+// GetProcessedCount returns the number of processed candles
+func (e *Engine) GetProcessedCount() int {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return e.processedCount
+}
+
+func (e *Engine) newSeries() *series {
+	return &series{
+		sma:       NewSMA(e.cfg.SMAPeriod),
+		ema:       NewEMA(e.cfg.EMAPeriod),
+		rsi:       NewRSI(e.cfg.RSIPeriod),
+		macd:      NewMACD(e.cfg.MACDFast, e.cfg.MACDSlow, e.cfg.MACDSignal),
+		bollinger: NewBollinger(e.cfg.BollingerPeriod, e.cfg.BollingerWidth),
+		atr:       NewATR(e.cfg.ATRPeriod),
+		vwap:      NewVWAP(),
+	}
+}
+
+func intervalDuration(interval string) time.Duration {
+	d, _ := time.ParseDuration(interval)
+	return d
+}
+
+func float(v float64) *float64 {
+	return &v
+}
@@ -0,0 +1,80 @@
+package indicators
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+type mockIndicatorProcessor struct {
+	values []models.IndicatorValues
+}
+
+func (m *mockIndicatorProcessor) ProcessIndicators(values models.IndicatorValues) {
+	m.values = append(m.values, values)
+}
+
+func candle(symbol, interval string, openTime int64, price float64) models.Candle {
+	return models.Candle{
+		Symbol:   symbol,
+		Interval: interval,
+		OpenTime: openTime,
+		Open:     price,
+		High:     price + 1,
+		Low:      price - 1,
+		Close:    price,
+		Volume:   1,
+	}
+}
+
+func TestEngine_ProcessCandle(t *testing.T) {
+	engine := NewEngine(Config{SMAPeriod: 3, EMAPeriod: 3, RSIPeriod: 3, MACDFast: 2, MACDSlow: 3, MACDSignal: 2, BollingerPeriod: 3, ATRPeriod: 3})
+	sink := &mockIndicatorProcessor{}
+	engine.AddProcessor(sink)
+
+	engine.ProcessCandle(candle("BTCUSDT", "1m", 0, 100))
+	values, ok := engine.Latest("btcusdt", "1m")
+	require.True(t, ok)
+	assert.Nil(t, values.SMA, "SMA should not be reported before a full period")
+	assert.NotNil(t, values.VWAP)
+
+	for i := int64(1); i < 6; i++ {
+		engine.ProcessCandle(candle("BTCUSDT", "1m", i*60_000, 100+float64(i)))
+	}
+
+	values, ok = engine.Latest("BTCUSDT", "1m")
+	require.True(t, ok)
+	assert.Equal(t, int64(300_000), values.OpenTime)
+	require.NotNil(t, values.SMA)
+	assert.Equal(t, 104.0, *values.SMA)
+	assert.NotNil(t, values.EMA)
+	assert.NotNil(t, values.RSI)
+	assert.Equal(t, 100.0, *values.RSI)
+	assert.NotNil(t, values.MACD)
+	assert.NotNil(t, values.BollingerUpper)
+	assert.NotNil(t, values.ATR)
+
+	assert.Len(t, sink.values, 6)
+	assert.Equal(t, 6, engine.GetProcessedCount())
+}
+
+func TestEngine_LatestForSymbol(t *testing.T) {
+	engine := NewEngine(Config{})
+
+	engine.ProcessCandle(candle("ETHUSDT", "5m", 0, 10))
+	engine.ProcessCandle(candle("ETHUSDT", "1m", 0, 10))
+	engine.ProcessCandle(candle("ETHUSDT", "1h", 0, 10))
+	engine.ProcessCandle(candle("BTCUSDT", "1m", 0, 10))
+
+	values := engine.LatestForSymbol("ethusdt")
+	require.Len(t, values, 3)
+	assert.Equal(t, "1m", values[0].Interval)
+	assert.Equal(t, "5m", values[1].Interval)
+	assert.Equal(t, "1h", values[2].Interval)
+
+	_, ok := engine.Latest("LTCUSDT", "1m")
+	assert.False(t, ok)
+}
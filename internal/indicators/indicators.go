@@ -0,0 +1,320 @@
+package indicators
+
+import (
+	"math"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+// window is a fixed-size ring buffer keeping a running sum and sum of squares
+type window struct {
+	values []float64
+	next   int
+	count  int
+	sum    float64
+	sumSq  float64
+}
+
+func newWindow(period int) *window {
+	return &window{values: make([]float64, period)}
+}
+
+func (w *window) push(v float64) {
+	if w.count == len(w.values) {
+		old := w.values[w.next]
+		w.sum -= old
+		w.sumSq -= old * old
+	} else {
+		w.count++
+	}
+	w.values[w.next] = v
+	w.next = (w.next + 1) % len(w.values)
+	w.sum += v
+	w.sumSq += v * v
+}
+
+func (w *window) full() bool {
+	return w.count == len(w.values)
+}
+
+func (w *window) mean() float64 {
+	if w.count == 0 {
+		return 0
+	}
+	return w.sum / float64(w.count)
+}
+
+func (w *window) stddev() float64 {
+	if w.count == 0 {
+		return 0
+	}
+	mean := w.mean()
+	variance := w.sumSq/float64(w.count) - mean*mean
+	if variance < 0 {
+		return 0
+	}
+	return math.Sqrt(variance)
+}
+
+// SMA is a simple moving average
+type SMA struct {
+	w *window
+}
+
+// NewSMA creates a new SMA over the given period
+func NewSMA(period int) *SMA {
+	return &SMA{w: newWindow(period)}
+}
+
+// Update adds a value and returns the current average
+func (s *SMA) Update(v float64) float64 {
+	s.w.push(v)
+	return s.w.mean()
+}
+
+// Value returns the current average
+func (s *SMA) Value() float64 {
+	return s.w.mean()
+}
+
+// Ready reports whether a full period has been observed
+func (s *SMA) Ready() bool {
+	return s.w.full()
+}
+
+// EMA is an exponential moving average seeded with the SMA of its first period
+type EMA struct {
+	period int
+	alpha  float64
+	count  int
+	value  float64
+}
+
+// NewEMA creates a new EMA over the given period
+func NewEMA(period int) *EMA {
+	return &EMA{period: period, alpha: 2 / float64(period+1)}
+}
+
+// Update adds a value and returns the current average
+func (e *EMA) Update(v float64) float64 {
+	e.count++
+	if e.count <= e.period {
+		e.value += (v - e.value) / float64(e.count)
+		return e.value
+	}
+	e.value += e.alpha * (v - e.value)
+	return e.value
+}
+
+// Value returns the current average
+func (e *EMA) Value() float64 {
+	return e.value
+}
+
+// Ready reports whether a full period has been observed
+func (e *EMA) Ready() bool {
+	return e.count >= e.period
+}
+
+// wilder is Wilder's smoothed average, seeded with the mean of its first period
+type wilder struct {
+	period int
+	count  int
+	value  float64
+}
+
+func (w *wilder) update(v float64) {
+	w.count++
+	if w.count <= w.period {
+		w.value += (v - w.value) / float64(w.count)
+		return
+	}
+	w.value = (w.value*float64(w.period-1) + v) / float64(w.period)
+}
+
+func (w *wilder) ready() bool {
+	return w.count >= w.period
+}
+
+// RSI is the relative strength index using Wilder's smoothing
+type RSI struct {
+	gain    wilder
+	loss    wilder
+	prev    float64
+	hasPrev bool
+}
+
+// NewRSI creates a new RSI over the given period
+func NewRSI(period int) *RSI {
+	return &RSI{gain: wilder{period: period}, loss: wilder{period: period}}
+}
+
+// Update adds a closing price and returns the current RSI
+func (r *RSI) Update(v float64) float64 {
+	if r.hasPrev {
+		change := v - r.prev
+		r.gain.update(math.Max(change, 0))
+		r.loss.update(math.Max(-change, 0))
+	}
+	r.prev = v
+	r.hasPrev = true
+	return r.Value()
+}
+
+// Value returns the current RSI
+func (r *RSI) Value() float64 {
+	if r.loss.value == 0 {
+		if r.gain.value == 0 {
+			return 50
+		}
+		return 100
+	}
+	rs := r.gain.value / r.loss.value
+	return 100 - 100/(1+rs)
+}
+
+// Ready reports whether a full period of changes has been observed
+func (r *RSI) Ready() bool {
+	return r.gain.ready()
+}
+
+// MACD is the moving average convergence divergence with its signal line
+type MACD struct {
+	fast   *EMA
+	slow   *EMA
+	signal *EMA
+}
+
+// NewMACD creates a new MACD with the given fast, slow and signal periods
+func NewMACD(fast, slow, signal int) *MACD {
+	return &MACD{fast: NewEMA(fast), slow: NewEMA(slow), signal: NewEMA(signal)}
+}
+
+// Update adds a closing price
+func (m *MACD) Update(v float64) {
+	m.fast.Update(v)
+	m.slow.Update(v)
+	if m.slow.Ready() {
+		m.signal.Update(m.Value())
+	}
+}
+
+// Value returns the MACD line
+func (m *MACD) Value() float64 {
+	return m.fast.Value() - m.slow.Value()
+}
+
+// Signal returns the signal line
+func (m *MACD) Signal() float64 {
+	return m.signal.Value()
+}
+
+// Histogram returns the difference between the MACD and signal lines
+func (m *MACD) Histogram() float64 {
+	return m.Value() - m.Signal()
+}
+
+// Ready reports whether the signal line has a full period
+func (m *MACD) Ready() bool {
+	return m.signal.Ready()
+}
+
+// Bollinger computes Bollinger Bands from a rolling mean and standard deviation
+type Bollinger struct {
+	w *window
+	k float64
+}
+
+// NewBollinger creates new Bollinger Bands over the given period and width
+func NewBollinger(period int, k float64) *Bollinger {
+	return &Bollinger{w: newWindow(period), k: k}
+}
+
+// Update adds a closing price
+func (b *Bollinger) Update(v float64) {
+	b.w.push(v)
+}
+
+// Bands returns the upper, middle and lower bands
+func (b *Bollinger) Bands() (upper, middle, lower float64) {
+	middle = b.w.mean()
+	width := b.k * b.w.stddev()
+	return middle + width, middle, middle - width
+}
+
+// Ready reports whether a full period has been observed
+func (b *Bollinger) Ready() bool {
+	return b.w.full()
+}
+
+// ATR is the average true range using Wilder's smoothing
+type ATR struct {
+	avg       wilder
+	prevClose float64
+	hasPrev   bool
+}
+
+// NewATR creates a new ATR over the given period
+func NewATR(period int) *ATR {
+	return &ATR{avg: wilder{period: period}}
+}
+
+// Update adds a candle and returns the current ATR
+func (a *ATR) Update(c models.Candle) float64 {
+	trueRange := c.High - c.Low
+	if a.hasPrev {
+		trueRange = math.Max(trueRange, math.Max(math.Abs(c.High-a.prevClose), math.Abs(c.Low-a.prevClose)))
+	}
+	a.avg.update(trueRange)
+	a.prevClose = c.Close
+	a.hasPrev = true
+	return a.avg.value
+}
+
+// Value returns the current ATR
+func (a *ATR) Value() float64 {
+	return a.avg.value
+}
+
+// Ready reports whether a full period has been observed
+func (a *ATR) Ready() bool {
+	return a.avg.ready()
+}
+
+// VWAP is the volume-weighted average price, reset at each UTC day boundary
+type VWAP struct {
+	day         int64
+	priceVolume float64
+	volume      float64
+}
+
+// NewVWAP creates a new VWAP
+func NewVWAP() *VWAP {
+	return &VWAP{day: -1}
+}
+
+// Update adds a candle, weighting its typical price by its volume
+func (v *VWAP) Update(c models.Candle) {
+	day := c.OpenTime / 86_400_000
+	if day != v.day {
+		v.day = day
+		v.priceVolume = 0
+		v.volume = 0
+	}
+	typical := (c.High + c.Low + c.Close) / 3
+	v.priceVolume += typical * c.Volume
+	v.volume += c.Volume
+}
+
+// Value returns the current VWAP
+func (v *VWAP) Value() float64 {
+	if v.volume == 0 {
+		return 0
+	}
+	return v.priceVolume / v.volume
+}
+
+// Ready reports whether any volume has been observed in the current session
+func (v *VWAP) Ready() bool {
+	return v.volume > 0
+}
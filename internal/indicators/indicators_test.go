@@ -0,0 +1,102 @@
+package indicators
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+func TestSMA(t *testing.T) {
+	sma := NewSMA(3)
+	sma.Update(1)
+	sma.Update(2)
+	assert.False(t, sma.Ready())
+	assert.Equal(t, 2.0, sma.Update(3))
+	assert.True(t, sma.Ready())
+	assert.Equal(t, 3.0, sma.Update(4))
+	assert.Equal(t, 4.0, sma.Update(5))
+}
+
+func TestEMA(t *testing.T) {
+	ema := NewEMA(3)
+	ema.Update(1)
+	ema.Update(2)
+	ema.Update(3)
+	assert.True(t, ema.Ready())
+	assert.Equal(t, 2.0, ema.Value(), "EMA should be seeded with the SMA")
+
+	// alpha = 2 / (3 + 1) = 0.5
+	assert.Equal(t, 3.0, ema.Update(4))
+	assert.Equal(t, 4.0, ema.Update(5))
+}
+
+func TestRSI(t *testing.T) {
+	rsi := NewRSI(2)
+	rsi.Update(10)
+	rsi.Update(12)
+	assert.False(t, rsi.Ready())
+	rsi.Update(11)
+	assert.True(t, rsi.Ready())
+	// avg gain = 1, avg loss = 0.5, RS = 2
+	assert.InDelta(t, 66.666, rsi.Value(), 0.001)
+
+	rising := NewRSI(2)
+	for _, v := range []float64{1, 2, 3} {
+		rising.Update(v)
+	}
+	assert.Equal(t, 100.0, rising.Value())
+}
+
+func TestMACD(t *testing.T) {
+	macd := NewMACD(2, 4, 2)
+	for v := 1.0; v <= 4; v++ {
+		macd.Update(v)
+	}
+	assert.False(t, macd.Ready())
+	macd.Update(5)
+	assert.True(t, macd.Ready())
+	assert.Greater(t, macd.Value(), 0.0, "MACD should be positive in an uptrend")
+	assert.InDelta(t, macd.Value()-macd.Signal(), macd.Histogram(), 1e-12)
+}
+
+func TestBollinger(t *testing.T) {
+	bollinger := NewBollinger(4, 2)
+	for _, v := range []float64{2, 4, 4, 6} {
+		bollinger.Update(v)
+	}
+	assert.True(t, bollinger.Ready())
+
+	upper, middle, lower := bollinger.Bands()
+	// mean = 4, population stddev = sqrt(2)
+	assert.Equal(t, 4.0, middle)
+	assert.InDelta(t, 4+2*1.41421356, upper, 1e-6)
+	assert.InDelta(t, 4-2*1.41421356, lower, 1e-6)
+}
+
+func TestATR(t *testing.T) {
+	atr := NewATR(2)
+	atr.Update(models.Candle{High: 10, Low: 8, Close: 9})
+	atr.Update(models.Candle{High: 12, Low: 10, Close: 11})
+	assert.True(t, atr.Ready())
+	// true ranges: 2 and max(2, |12-9|, |10-9|) = 3
+	assert.Equal(t, 2.5, atr.Value())
+
+	atr.Update(models.Candle{High: 11, Low: 10, Close: 10})
+	// Wilder smoothing: (2.5 * 1 + 1) / 2
+	assert.Equal(t, 1.75, atr.Value())
+}
+
+func TestVWAP(t *testing.T) {
+	vwap := NewVWAP()
+	assert.False(t, vwap.Ready())
+
+	vwap.Update(models.Candle{OpenTime: 0, High: 12, Low: 8, Close: 10, Volume: 1})
+	vwap.Update(models.Candle{OpenTime: 60_000, High: 21, Low: 19, Close: 20, Volume: 3})
+	assert.Equal(t, 17.5, vwap.Value())
+
+	// A new UTC day starts a new session
+	vwap.Update(models.Candle{OpenTime: 86_400_000, High: 5, Low: 5, Close: 5, Volume: 2})
+	assert.Equal(t, 5.0, vwap.Value())
+}
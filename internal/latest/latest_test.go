@@ -0,0 +1,69 @@
+package latest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+func newTestStore(now *time.Time) *Store {
+	store := NewStore()
+	store.clock = func() time.Time { return *now }
+	return store
+}
+
+func TestStore_DerivedFields(t *testing.T) {
+	now := time.UnixMilli(1_000_000)
+	store := newTestStore(&now)
+
+	store.Process(models.FormattedData{Symbol: "BTCUSDT", EventTime: 1000, LastPrice: 100})
+	now = now.Add(2 * time.Second)
+	store.Process(models.FormattedData{Symbol: "BTCUSDT", EventTime: 2000, LastPrice: 105})
+
+	tick, ok := store.Latest("btcusdt", now.Add(500*time.Millisecond))
+	require.True(t, ok)
+	assert.Equal(t, int64(2000), tick.EventTime)
+	assert.Equal(t, 105.0, tick.LastPrice)
+	assert.Equal(t, int64(1_000_000), tick.ConnectedAt)
+	assert.Equal(t, 100.0, tick.ConnectPrice)
+	assert.Equal(t, 5.0, tick.ChangeSinceConnect)
+	assert.InDelta(t, 5.0, tick.ChangeSinceConnectPercent, 1e-9)
+	assert.Equal(t, int64(1_002_000), tick.UpdatedAt)
+	assert.Equal(t, int64(500), tick.LastUpdateAge)
+	assert.Equal(t, 2, tick.UpdateCount)
+
+	_, ok = store.Latest("ETHUSDT", now)
+	assert.False(t, ok)
+}
+
+func TestStore_IgnoresOlderTicks(t *testing.T) {
+	now := time.UnixMilli(1_000_000)
+	store := newTestStore(&now)
+
+	store.Process(models.FormattedData{Symbol: "BTCUSDT", EventTime: 2000, LastPrice: 105})
+	store.Process(models.FormattedData{Symbol: "BTCUSDT", EventTime: 1000, LastPrice: 100})
+
+	tick, ok := store.Latest("BTCUSDT", now)
+	require.True(t, ok)
+	assert.Equal(t, 105.0, tick.LastPrice)
+	assert.Equal(t, 1, tick.UpdateCount)
+	assert.Equal(t, 2, store.GetProcessedCount())
+}
+
+func TestStore_All(t *testing.T) {
+	now := time.UnixMilli(1_000_000)
+	store := newTestStore(&now)
+
+	store.Process(models.FormattedData{Symbol: "ETHUSDT", EventTime: 1000, LastPrice: 3000})
+	store.Process(models.FormattedData{Symbol: "BTCUSDT", EventTime: 1000, LastPrice: 60000})
+
+	all := store.All(now)
+	require.Len(t, all, 2)
+	assert.Equal(t, "BTCUSDT", all[0].Symbol)
+	assert.Equal(t, "ETHUSDT", all[1].Symbol)
+	assert.Empty(t, NewStore().All(now))
+}
@@ -0,0 +1,166 @@
+package migrations
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testMigrations = []Migration{
+	{Version: 1, Name: "create_a", Up: "CREATE TABLE a (id INT)", Down: "DROP TABLE a"},
+	{Version: 2, Name: "create_b", Up: "CREATE TABLE b (id INT)", Down: "DROP TABLE b"},
+}
+
+func expectPrepare(mock sqlmock.Sqlmock, applied ...int64) {
+	mock.ExpectExec(`SELECT pg_advisory_lock\(\$1\)`).WithArgs(lockID).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).WillReturnResult(sqlmock.NewResult(0, 0))
+	expectApplied(mock, true, applied...)
+}
+
+func expectApplied(mock sqlmock.Sqlmock, exists bool, applied ...int64) {
+	mock.ExpectQuery(`SELECT to_regclass`).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(exists))
+	if !exists {
+		return
+	}
+	rows := sqlmock.NewRows([]string{"version", "applied_at"})
+	for _, version := range applied {
+		rows.AddRow(version, time.Unix(1700000000, 0))
+	}
+	mock.ExpectQuery(`SELECT version, applied_at FROM schema_migrations`).WillReturnRows(rows)
+}
+
+func expectUnlock(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(`SELECT pg_advisory_unlock\(\$1\)`).WithArgs(lockID).WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func TestEmbedded(t *testing.T) {
+	migrations, err := Embedded()
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+	assert.Equal(t, int64(1), migrations[0].Version)
+	assert.Equal(t, "initial_schema", migrations[0].Name)
+	assert.Contains(t, migrations[0].Up, "CREATE TABLE IF NOT EXISTS ticker_data")
+	assert.Contains(t, migrations[0].Down, "DROP TABLE IF EXISTS candles")
+	// Tables that databases created by database/init.sql already had are kept
+	assert.NotContains(t, migrations[0].Down, "DROP TABLE IF EXISTS ticker_data")
+	assert.NotContains(t, migrations[0].Down, "DROP TABLE IF EXISTS exchange_info")
+}
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0002_add_index.up.sql":    {Data: []byte("CREATE INDEX i ON a (id)")},
+		"0001_create_a.up.sql":     {Data: []byte("CREATE TABLE a (id INT)")},
+		"0001_create_a.down.sql":   {Data: []byte("DROP TABLE a")},
+		"README.md":                {Data: []byte("not a migration")},
+		"0003_missing_up.down.sql": {Data: []byte("SELECT 1")},
+	}
+
+	_, err := Load(fsys)
+	assert.ErrorContains(t, err, "migration 3_missing_up has no up script")
+
+	delete(fsys, "0003_missing_up.down.sql")
+	migrations, err := Load(fsys)
+	require.NoError(t, err)
+	require.Len(t, migrations, 2)
+	assert.Equal(t, Migration{Version: 1, Name: "create_a", Up: "CREATE TABLE a (id INT)", Down: "DROP TABLE a"}, migrations[0])
+	assert.Equal(t, int64(2), migrations[1].Version)
+	assert.Empty(t, migrations[1].Down)
+}
+
+func TestMigrator_Up(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	expectPrepare(mock, 1)
+	mock.ExpectBegin()
+	mock.ExpectExec(`CREATE TABLE b`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO schema_migrations`).WithArgs(int64(2), "create_b").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	expectUnlock(mock)
+
+	count, err := NewMigrator(db, testMigrations).Up(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrator_UpRollsBackFailedMigration(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	expectPrepare(mock)
+	mock.ExpectBegin()
+	mock.ExpectExec(`CREATE TABLE a`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO schema_migrations`).WithArgs(int64(1), "create_a").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec(`CREATE TABLE b`).WillReturnError(errors.New("syntax error"))
+	mock.ExpectRollback()
+	expectUnlock(mock)
+
+	count, err := NewMigrator(db, testMigrations).Up(context.Background())
+	assert.ErrorContains(t, err, "error applying migration 2_create_b: syntax error")
+	assert.Equal(t, 1, count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrator_Down(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	expectPrepare(mock, 1, 2)
+	mock.ExpectBegin()
+	mock.ExpectExec(`DROP TABLE b`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM schema_migrations WHERE version = \$1`).WithArgs(int64(2)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	expectUnlock(mock)
+
+	count, err := NewMigrator(db, testMigrations).Down(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrator_DryRun(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// A dry run neither locks nor creates schema_migrations
+	expectApplied(mock, false)
+
+	var out bytes.Buffer
+	migrator := NewMigrator(db, testMigrations)
+	migrator.SetDryRun(&out)
+	count, err := migrator.Up(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, "-- 1_create_a\nCREATE TABLE a (id INT)\n-- 2_create_b\nCREATE TABLE b (id INT)\n", out.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrator_Status(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	expectApplied(mock, true, 1)
+
+	statuses, err := NewMigrator(db, testMigrations).Status(context.Background())
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	assert.True(t, statuses[0].Applied)
+	assert.Equal(t, time.Unix(1700000000, 0), statuses[0].AppliedAt)
+	assert.False(t, statuses[1].Applied)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,11 @@
+-- ticker_data and exchange_info predate migrations (database/init.sql) and
+-- may hold a production history that 0001 never created, so they are kept.
+
+DROP TABLE IF EXISTS paper_fills;
+DROP TABLE IF EXISTS paper_orders;
+DROP TABLE IF EXISTS portfolio_valuations;
+DROP TABLE IF EXISTS correlation_snapshots;
+DROP TABLE IF EXISTS arbitrage_opportunities;
+DROP TABLE IF EXISTS anomalies;
+DROP TABLE IF EXISTS indicator_values;
+DROP TABLE IF EXISTS candles;
@@ -0,0 +1,144 @@
+-- Baseline schema, previously created by database/init.sql. Tables are
+-- created only if missing so that existing databases can adopt migrations.
+
+CREATE TABLE IF NOT EXISTS exchange_info
+(
+    symbol       VARCHAR(50),
+    status       VARCHAR(50),
+    base_asset   VARCHAR(50),
+    quote_asset  VARCHAR(50),
+    filter_type  VARCHAR(50),
+    filter_key   VARCHAR(50),
+    filter_value VARCHAR(50),
+    CONSTRAINT exchange_info_unique UNIQUE (symbol, filter_type, filter_key)
+);
+
+CREATE TABLE IF NOT EXISTS ticker_data
+(
+    id           SERIAL PRIMARY KEY,
+    event_time   BIGINT,
+    symbol       TEXT,
+    last_price   DOUBLE PRECISION,
+    price_change DOUBLE PRECISION,
+    high_price   DOUBLE PRECISION,
+    low_price    DOUBLE PRECISION,
+    volume       DOUBLE PRECISION,
+    quote_volume DOUBLE PRECISION,
+    open_time    BIGINT,
+    close_time   BIGINT,
+    trade_count  INT,
+    latency      BIGINT
+);
+
+CREATE TABLE IF NOT EXISTS candles
+(
+    symbol       TEXT   NOT NULL,
+    interval     TEXT   NOT NULL,
+    open_time    BIGINT NOT NULL,
+    close_time   BIGINT NOT NULL,
+    open         DOUBLE PRECISION,
+    high         DOUBLE PRECISION,
+    low          DOUBLE PRECISION,
+    close        DOUBLE PRECISION,
+    volume       DOUBLE PRECISION,
+    quote_volume DOUBLE PRECISION,
+    trade_count  INT,
+    PRIMARY KEY (symbol, interval, open_time)
+);
+
+CREATE TABLE IF NOT EXISTS indicator_values
+(
+    symbol           TEXT   NOT NULL,
+    interval         TEXT   NOT NULL,
+    open_time        BIGINT NOT NULL,
+    sma              DOUBLE PRECISION,
+    ema              DOUBLE PRECISION,
+    rsi              DOUBLE PRECISION,
+    macd             DOUBLE PRECISION,
+    macd_signal      DOUBLE PRECISION,
+    macd_histogram   DOUBLE PRECISION,
+    bollinger_upper  DOUBLE PRECISION,
+    bollinger_middle DOUBLE PRECISION,
+    bollinger_lower  DOUBLE PRECISION,
+    atr              DOUBLE PRECISION,
+    vwap             DOUBLE PRECISION,
+    PRIMARY KEY (symbol, interval, open_time)
+);
+
+CREATE TABLE IF NOT EXISTS anomalies
+(
+    id         SERIAL PRIMARY KEY,
+    symbol     TEXT   NOT NULL,
+    kind       TEXT   NOT NULL,
+    event_time BIGINT NOT NULL,
+    value      DOUBLE PRECISION,
+    score      DOUBLE PRECISION,
+    message    TEXT
+);
+
+CREATE TABLE IF NOT EXISTS arbitrage_opportunities
+(
+    id          SERIAL PRIMARY KEY,
+    path        TEXT   NOT NULL,
+    symbols     TEXT   NOT NULL,
+    start_time  BIGINT NOT NULL,
+    end_time    BIGINT NOT NULL,
+    duration_ms BIGINT NOT NULL,
+    max_return  DOUBLE PRECISION,
+    last_return DOUBLE PRECISION
+);
+
+CREATE TABLE IF NOT EXISTS correlation_snapshots
+(
+    id            SERIAL PRIMARY KEY,
+    snapshot_time BIGINT NOT NULL,
+    window_name   TEXT   NOT NULL,
+    symbol        TEXT   NOT NULL,
+    other_symbol  TEXT   NOT NULL,
+    correlation   DOUBLE PRECISION,
+    beta          DOUBLE PRECISION
+);
+
+CREATE TABLE IF NOT EXISTS portfolio_valuations
+(
+    id             SERIAL PRIMARY KEY,
+    event_time     BIGINT NOT NULL,
+    quote          TEXT   NOT NULL,
+    total_value    DOUBLE PRECISION,
+    day_open_value DOUBLE PRECISION,
+    day_pnl        DOUBLE PRECISION,
+    assets         JSONB
+);
+
+CREATE TABLE IF NOT EXISTS paper_orders
+(
+    session      TEXT   NOT NULL,
+    id           BIGINT NOT NULL,
+    symbol       TEXT   NOT NULL,
+    side         TEXT   NOT NULL,
+    type         TEXT   NOT NULL,
+    quantity     DOUBLE PRECISION,
+    price        DOUBLE PRECISION,
+    stop_price   DOUBLE PRECISION,
+    status       TEXT   NOT NULL,
+    filled_price DOUBLE PRECISION,
+    reason       TEXT,
+    created_time BIGINT,
+    updated_time BIGINT,
+    PRIMARY KEY (session, id)
+);
+
+CREATE TABLE IF NOT EXISTS paper_fills
+(
+    id         SERIAL PRIMARY KEY,
+    session    TEXT   NOT NULL,
+    order_id   BIGINT NOT NULL,
+    symbol     TEXT   NOT NULL,
+    side       TEXT   NOT NULL,
+    price      DOUBLE PRECISION,
+    quantity   DOUBLE PRECISION,
+    fee        DOUBLE PRECISION,
+    fee_asset  TEXT,
+    maker      BOOLEAN,
+    event_time BIGINT
+);
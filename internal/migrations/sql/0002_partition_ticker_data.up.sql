@@ -0,0 +1,4 @@
+-- Partitioning ticker_data rewrites the whole table, so it is no longer a
+-- startup migration. Stop the monitor and run
+-- `go run cmd/migrate/main.go partition` instead; see partition.Manager.Convert.
+SELECT 1;
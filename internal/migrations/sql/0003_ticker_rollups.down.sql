@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS ticker_data_event_time_brin;
+DROP TABLE IF EXISTS rollup_checkpoints;
+DROP TABLE IF EXISTS ticker_rollups_1d;
+DROP TABLE IF EXISTS ticker_rollups_1h;
+DROP TABLE IF EXISTS ticker_rollups_1m;
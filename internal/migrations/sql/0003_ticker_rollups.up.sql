@@ -0,0 +1,39 @@
+-- Per-symbol summaries of ticker_data kept after raw ticks are dropped.
+-- Minutes are rolled up from ticker_data, hours from minutes and days from
+-- hours. Volumes are Binance's rolling 24h figures, so the deltas are the
+-- change in the rolling volume over the bucket.
+
+CREATE TABLE ticker_rollups_1m
+(
+    symbol             TEXT   NOT NULL,
+    bucket_start       BIGINT NOT NULL,
+    open               DOUBLE PRECISION,
+    high               DOUBLE PRECISION,
+    low                DOUBLE PRECISION,
+    close              DOUBLE PRECISION,
+    open_volume        DOUBLE PRECISION,
+    close_volume       DOUBLE PRECISION,
+    open_quote_volume  DOUBLE PRECISION,
+    close_quote_volume DOUBLE PRECISION,
+    volume_delta       DOUBLE PRECISION GENERATED ALWAYS AS (close_volume - open_volume) STORED,
+    quote_volume_delta DOUBLE PRECISION GENERATED ALWAYS AS (close_quote_volume - open_quote_volume) STORED,
+    avg_latency        DOUBLE PRECISION,
+    message_count      BIGINT NOT NULL,
+    PRIMARY KEY (symbol, bucket_start)
+);
+
+CREATE TABLE ticker_rollups_1h (LIKE ticker_rollups_1m INCLUDING ALL);
+CREATE TABLE ticker_rollups_1d (LIKE ticker_rollups_1m INCLUDING ALL);
+
+-- watermark is the end (exclusive, event time milliseconds) of the last
+-- bucket each rollup has completed
+CREATE TABLE rollup_checkpoints
+(
+    name       TEXT PRIMARY KEY,
+    watermark  BIGINT      NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Rollups and retention read ticker_data by event time range. Ticks arrive
+-- in event time order, so a small BRIN index serves those ranges.
+CREATE INDEX IF NOT EXISTS ticker_data_event_time_brin ON ticker_data USING brin (event_time);
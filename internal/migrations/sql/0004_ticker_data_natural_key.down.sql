@@ -0,0 +1 @@
+DROP INDEX ticker_data_symbol_event_time_key;
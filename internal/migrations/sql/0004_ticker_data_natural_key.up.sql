@@ -0,0 +1,17 @@
+-- Make (symbol, event_time) the natural key of ticker_data so that
+-- reconnects, replays and re-deliveries cannot store a tick twice. The
+-- oldest copy of every existing duplicate is kept.
+--
+-- The self-join DELETE scans the whole table, and the non-concurrent
+-- CREATE UNIQUE INDEX scans it again while locking it against writes, so on
+-- a large ticker_data this migration holds up startup until both finish.
+
+DELETE FROM ticker_data a
+    USING ticker_data b
+WHERE a.symbol = b.symbol
+  AND a.event_time = b.event_time
+  AND a.id > b.id;
+
+-- The unique index serves (symbol, event_time) queries as well. A
+-- TimescaleDB hypertable created in this database already has it.
+CREATE UNIQUE INDEX IF NOT EXISTS ticker_data_symbol_event_time_key ON ticker_data (symbol, event_time);
@@ -0,0 +1,5 @@
+-- Candles emitted at shutdown cover only part of their interval. They are
+-- flagged so that the candle for the same interval after a restart is merged
+-- into them instead of replacing them.
+
+ALTER TABLE candles ADD COLUMN partial BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,21 @@
+package models
+
+// AnomalyKind identifies the type of anomaly detected
+type AnomalyKind string
+
+const (
+	PriceOutlier AnomalyKind = "price_outlier"
+	VolumeSpike  AnomalyKind = "volume_spike"
+	TradeSpike   AnomalyKind = "trade_spike"
+	QuietPeriod  AnomalyKind = "quiet_period"
+)
+
+// Anomaly represents a statistically unusual observation for a symbol
+type Anomaly struct {
+	Symbol    string      `json:"symbol"`
+	Kind      AnomalyKind `json:"kind"`
+	EventTime int64       `json:"event_time"`
+	Value     float64     `json:"value"`
+	Score     float64     `json:"score"`
+	Message   string      `json:"message"`
+}
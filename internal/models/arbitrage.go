@@ -0,0 +1,14 @@
+package models
+
+// ArbitrageOpportunity is a triangular cycle whose implied return after fees
+// exceeded the configured threshold for a period of time
+type ArbitrageOpportunity struct {
+	// Path lists the assets traded through, starting and ending with the same asset
+	Path       []string `json:"path"`
+	Symbols    []string `json:"symbols"`
+	StartTime  int64    `json:"start_time"`
+	EndTime    int64    `json:"end_time"`
+	DurationMs int64    `json:"duration_ms"`
+	MaxReturn  float64  `json:"max_return"`
+	LastReturn float64  `json:"last_return"`
+}
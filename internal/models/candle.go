@@ -0,0 +1,27 @@
+package models
+
+// Candle represents an OHLCV candle aggregated from ticker data
+type Candle struct {
+	Symbol      string  `json:"symbol"`
+	Interval    string  `json:"interval"`
+	OpenTime    int64   `json:"open_time"`
+	CloseTime   int64   `json:"close_time"`
+	Open        float64 `json:"open"`
+	High        float64 `json:"high"`
+	Low         float64 `json:"low"`
+	Close       float64 `json:"close"`
+	Volume      float64 `json:"volume"`
+	QuoteVolume float64 `json:"quote_volume"`
+	TradeCount  int     `json:"trade_count"`
+	// Partial is set on candles emitted before their interval ended, at
+	// shutdown
+	Partial bool `json:"partial,omitempty"`
+}
+
+// Trade represents a single executed trade from a trade stream
+type Trade struct {
+	EventTime int64   `json:"event_time"`
+	Symbol    string  `json:"symbol"`
+	Price     float64 `json:"price"`
+	Quantity  float64 `json:"quantity"`
+}
@@ -0,0 +1,13 @@
+package models
+
+// CorrelationMatrix is a snapshot of rolling return correlations between the
+// monitored symbols and their betas against a benchmark symbol. Pairs without
+// enough overlapping samples are omitted.
+type CorrelationMatrix struct {
+	Window       string                        `json:"window"`
+	SnapshotTime int64                         `json:"snapshot_time"`
+	Benchmark    string                        `json:"benchmark"`
+	Symbols      []string                      `json:"symbols"`
+	Correlations map[string]map[string]float64 `json:"correlations"`
+	Betas        map[string]float64            `json:"betas"`
+}
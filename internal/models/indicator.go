@@ -0,0 +1,20 @@
+package models
+
+// IndicatorValues holds the technical indicators of a symbol and interval as
+// of a closed candle. Indicators without enough history yet are nil.
+type IndicatorValues struct {
+	Symbol          string   `json:"symbol"`
+	Interval        string   `json:"interval"`
+	OpenTime        int64    `json:"open_time"`
+	SMA             *float64 `json:"sma,omitempty"`
+	EMA             *float64 `json:"ema,omitempty"`
+	RSI             *float64 `json:"rsi,omitempty"`
+	MACD            *float64 `json:"macd,omitempty"`
+	MACDSignal      *float64 `json:"macd_signal,omitempty"`
+	MACDHistogram   *float64 `json:"macd_histogram,omitempty"`
+	BollingerUpper  *float64 `json:"bollinger_upper,omitempty"`
+	BollingerMiddle *float64 `json:"bollinger_middle,omitempty"`
+	BollingerLower  *float64 `json:"bollinger_lower,omitempty"`
+	ATR             *float64 `json:"atr,omitempty"`
+	VWAP            *float64 `json:"vwap,omitempty"`
+}
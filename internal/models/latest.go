@@ -0,0 +1,18 @@
+package models
+
+// LatestTick is the most recent tick of a symbol with fields derived since
+// the monitor started receiving it. Times are epoch milliseconds.
+type LatestTick struct {
+	FormattedData
+	// ConnectedAt is when the first tick of the symbol was received
+	ConnectedAt  int64   `json:"connected_at"`
+	ConnectPrice float64 `json:"connect_price"`
+	// ChangeSinceConnect is the last price minus ConnectPrice
+	ChangeSinceConnect        float64 `json:"change_since_connect"`
+	ChangeSinceConnectPercent float64 `json:"change_since_connect_percent"`
+	// UpdatedAt is when the tick was received and LastUpdateAge how long ago
+	// that was when the state was read
+	UpdatedAt     int64 `json:"updated_at"`
+	LastUpdateAge int64 `json:"last_update_age_ms"`
+	UpdateCount   int   `json:"update_count"`
+}
@@ -0,0 +1,91 @@
+package models
+
+// OrderSide is the side of a paper trading order
+type OrderSide string
+
+// OrderType is the type of a paper trading order
+type OrderType string
+
+// OrderStatus is the lifecycle state of a paper trading order
+type OrderStatus string
+
+const (
+	SideBuy  OrderSide = "BUY"
+	SideSell OrderSide = "SELL"
+
+	OrderMarket OrderType = "MARKET"
+	OrderLimit  OrderType = "LIMIT"
+	OrderStop   OrderType = "STOP"
+
+	StatusNew      OrderStatus = "NEW"
+	StatusFilled   OrderStatus = "FILLED"
+	StatusCanceled OrderStatus = "CANCELED"
+	StatusRejected OrderStatus = "REJECTED"
+)
+
+// OrderRequest describes a new paper trading order. Price is required for
+// limit orders and StopPrice for stop orders.
+type OrderRequest struct {
+	Symbol    string    `json:"symbol"`
+	Side      OrderSide `json:"side"`
+	Type      OrderType `json:"type"`
+	Quantity  float64   `json:"quantity"`
+	Price     float64   `json:"price,omitempty"`
+	StopPrice float64   `json:"stop_price,omitempty"`
+}
+
+// Order is a paper trading order and its current state
+type Order struct {
+	ID          int64       `json:"id"`
+	Symbol      string      `json:"symbol"`
+	Side        OrderSide   `json:"side"`
+	Type        OrderType   `json:"type"`
+	Quantity    float64     `json:"quantity"`
+	Price       float64     `json:"price,omitempty"`
+	StopPrice   float64     `json:"stop_price,omitempty"`
+	Status      OrderStatus `json:"status"`
+	FilledPrice float64     `json:"filled_price,omitempty"`
+	Reason      string      `json:"reason,omitempty"`
+	CreatedTime int64       `json:"created_time"`
+	UpdatedTime int64       `json:"updated_time"`
+}
+
+// Fill is the execution of a paper trading order. Fees are charged in the
+// quote asset.
+type Fill struct {
+	OrderID   int64     `json:"order_id"`
+	Symbol    string    `json:"symbol"`
+	Side      OrderSide `json:"side"`
+	Price     float64   `json:"price"`
+	Quantity  float64   `json:"quantity"`
+	Fee       float64   `json:"fee"`
+	FeeAsset  string    `json:"fee_asset"`
+	Maker     bool      `json:"maker"`
+	EventTime int64     `json:"event_time"`
+}
+
+// Balance is the free and locked amount of an asset in a paper account
+type Balance struct {
+	Free   float64 `json:"free"`
+	Locked float64 `json:"locked"`
+}
+
+// Position is the net quantity bought through paper trading in a symbol, with
+// its average cost and PnL in the quote asset. RealizedPnL is net of fees.
+type Position struct {
+	Symbol        string  `json:"symbol"`
+	Quantity      float64 `json:"quantity"`
+	AvgPrice      float64 `json:"avg_price"`
+	LastPrice     float64 `json:"last_price"`
+	RealizedPnL   float64 `json:"realized_pnl"`
+	UnrealizedPnL float64 `json:"unrealized_pnl"`
+}
+
+// Account is a snapshot of a paper trading account
+type Account struct {
+	Balances      map[string]Balance `json:"balances"`
+	Positions     []Position         `json:"positions"`
+	RealizedPnL   float64            `json:"realized_pnl"`
+	UnrealizedPnL float64            `json:"unrealized_pnl"`
+	Fees          float64            `json:"fees"`
+}
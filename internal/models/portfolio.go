@@ -0,0 +1,24 @@
+package models
+
+// AssetValuation is the value of a single holding in the portfolio quote currency
+type AssetValuation struct {
+	Asset    string  `json:"asset"`
+	Quantity float64 `json:"quantity"`
+	Price    float64 `json:"price"`
+	Value    float64 `json:"value"`
+	Weight   float64 `json:"weight"`
+	DayPnL   float64 `json:"day_pnl"`
+}
+
+// PortfolioValuation is a point-in-time valuation of the configured holdings.
+// Assets without a price route to the quote currency are listed in Unpriced.
+type PortfolioValuation struct {
+	EventTime     int64            `json:"event_time"`
+	Quote         string           `json:"quote"`
+	TotalValue    float64          `json:"total_value"`
+	DayOpenValue  float64          `json:"day_open_value"`
+	DayPnL        float64          `json:"day_pnl"`
+	DayPnLPercent float64          `json:"day_pnl_percent"`
+	Assets        []AssetValuation `json:"assets"`
+	Unpriced      []string         `json:"unpriced,omitempty"`
+}
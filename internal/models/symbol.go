@@ -0,0 +1,24 @@
+package models
+
+import "strings"
+
+// DefaultQuoteAssets lists common Binance quote assets, longest first so that
+// e.g. FDUSD is matched before USD-like suffixes
+var DefaultQuoteAssets = []string{"FDUSD", "USDT", "USDC", "BUSD", "TUSD", "BTC", "ETH", "BNB", "EUR", "TRY", "GBP"}
+
+// SplitSymbol splits a symbol such as BTCUSDT into its base and quote assets
+// using the given quote assets (DefaultQuoteAssets when nil)
+func SplitSymbol(symbol string, quoteAssets []string) (base, quote string, ok bool) {
+	if quoteAssets == nil {
+		quoteAssets = DefaultQuoteAssets
+	}
+
+	symbol = strings.ToUpper(symbol)
+	for _, q := range quoteAssets {
+		q = strings.ToUpper(q)
+		if strings.HasSuffix(symbol, q) && len(symbol) > len(q) {
+			return strings.TrimSuffix(symbol, q), q, true
+		}
+	}
+	return "", "", false
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitSymbol(t *testing.T) {
+	testCases := []struct {
+		symbol string
+		base   string
+		quote  string
+		ok     bool
+	}{
+		{"BTCUSDT", "BTC", "USDT", true},
+		{"ethbtc", "ETH", "BTC", true},
+		{"BNBFDUSD", "BNB", "FDUSD", true},
+		{"USDT", "", "", false},
+		{"XYZABC", "", "", false},
+	}
+
+	for _, tc := range testCases {
+		base, quote, ok := SplitSymbol(tc.symbol, nil)
+		assert.Equal(t, tc.ok, ok, tc.symbol)
+		assert.Equal(t, tc.base, base, tc.symbol)
+		assert.Equal(t, tc.quote, quote, tc.symbol)
+	}
+
+	base, quote, ok := SplitSymbol("ABCXYZ", []string{"XYZ"})
+	assert.True(t, ok)
+	assert.Equal(t, "ABC", base)
+	assert.Equal(t, "XYZ", quote)
+}
@@ -12,6 +12,8 @@ type TickerData struct {
 	EventTime    utils.Int64 `json:"E"`
 	Symbol       string      `json:"s"`
 	LastPrice    string      `json:"c"`
+	BidPrice     string      `json:"b"`
+	AskPrice     string      `json:"a"`
 	PriceChange  string      `json:"p"`
 	HighPrice    string      `json:"h"`
 	LowPrice     string      `json:"l"`
@@ -26,26 +28,36 @@ type TickerData struct {
 
 // FormattedData represents the structure of the processed data
 type FormattedData struct {
-	EventTime   int64
-	Symbol      string
-	LastPrice   float64
-	PriceChange float64
-	HighPrice   float64
-	LowPrice    float64
-	Volume      float64
-	QuoteVolume float64
-	OpenTime    int64
-	CloseTime   int64
-	TradeCount  int
-	Latency     int64
+	EventTime   int64   `json:"event_time"`
+	Symbol      string  `json:"symbol"`
+	LastPrice   float64 `json:"last_price"`
+	BidPrice    float64 `json:"bid_price"`
+	AskPrice    float64 `json:"ask_price"`
+	PriceChange float64 `json:"price_change"`
+	HighPrice   float64 `json:"high_price"`
+	LowPrice    float64 `json:"low_price"`
+	Volume      float64 `json:"volume"`
+	QuoteVolume float64 `json:"quote_volume"`
+	OpenTime    int64   `json:"open_time"`
+	CloseTime   int64   `json:"close_time"`
+	TradeCount  int     `json:"trade_count"`
+	Latency     int64   `json:"latency"`
 }
 
 // FormatTickerData converts TickerData to FormattedData
 func FormatTickerData(td TickerData) FormattedData {
+	return FormatTickerDataAt(td, time.Now())
+}
+
+// FormatTickerDataAt converts TickerData received at the given time to
+// FormattedData, so that replayed frames keep their original latency
+func FormatTickerDataAt(td TickerData, receivedAt time.Time) FormattedData {
 	return FormattedData{
 		EventTime:   int64(td.EventTime),
 		Symbol:      td.Symbol,
 		LastPrice:   parseFloat(td.LastPrice),
+		BidPrice:    parseFloat(td.BidPrice),
+		AskPrice:    parseFloat(td.AskPrice),
 		PriceChange: parseFloat(td.PriceChange),
 		HighPrice:   parseFloat(td.HighPrice),
 		LowPrice:    parseFloat(td.LowPrice),
@@ -54,7 +66,7 @@ func FormatTickerData(td TickerData) FormattedData {
 		OpenTime:    int64(td.OpenTime),
 		CloseTime:   int64(td.CloseTime),
 		TradeCount:  td.TradeCount,
-		Latency:     time.Now().UnixMilli() - int64(td.EventTime),
+		Latency:     receivedAt.UnixMilli() - int64(td.EventTime),
 	}
 }
 
@@ -16,6 +16,8 @@ func TestFormatTickerData(t *testing.T) {
 		EventTime:    utils.Int64(now), // Use current time
 		Symbol:       "BTCUSDT",
 		LastPrice:    "35000.00",
+		BidPrice:     "34999.50",
+		AskPrice:     "35000.50",
 		PriceChange:  "1000.00",
 		HighPrice:    "36000.00",
 		LowPrice:     "34000.00",
@@ -35,6 +37,8 @@ func TestFormatTickerData(t *testing.T) {
 	assert.Equal(t, now, result.EventTime)
 	assert.Equal(t, "BTCUSDT", result.Symbol)
 	assert.Equal(t, 35000.00, result.LastPrice)
+	assert.Equal(t, 34999.50, result.BidPrice)
+	assert.Equal(t, 35000.50, result.AskPrice)
 	assert.Equal(t, 1000.00, result.PriceChange)
 	assert.Equal(t, 36000.00, result.HighPrice)
 	assert.Equal(t, 34000.00, result.LowPrice)
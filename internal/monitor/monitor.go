@@ -1,21 +1,45 @@
 package monitor
 
 import (
-	"database/sql"
 	"fmt"
 	"log"
 	"time"
 
 	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/processor"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/recorder"
 	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/websocket"
 )
 
-// MonitorSymbol starts monitoring for a specific symbol
-func MonitorSymbol(symbol string, db *sql.DB, stop chan struct{}) {
+// StreamURL is the base URL of the Binance websocket API. Point it at the
+// simulator to run the monitor offline.
+var StreamURL = "wss://stream.binance.com:9443"
+
+// Reconnect is the backoff used to re-establish dropped connections
+var Reconnect = websocket.ReconnectConfig{MinBackoff: time.Second, MaxBackoff: 30 * time.Second}
+
+// ReadTimeout is how long a connection may stay silent before it is treated
+// as half-open and reconnected. Ticker streams push every second.
+var ReadTimeout = 30 * time.Second
+
+// MonitorSymbol starts monitoring for a specific symbol, writing its ticks to
+// store. The store and any additional processors are shared with other
+// symbols and are closed by the caller.
+func MonitorSymbol(symbol string, store processor.TickStore, stop chan struct{}, processors ...processor.DataProcessor) {
+	RecordSymbol(symbol, store, stop, nil, processors...)
+}
+
+// RecordSymbol monitors a symbol like MonitorSymbol and also passes every raw
+// frame to the recorder when it is not nil
+func RecordSymbol(symbol string, store processor.TickStore, stop chan struct{}, recorder websocket.FrameRecorder, processors ...processor.DataProcessor) {
 	log.Printf("Starting monitoring for symbol: %s", symbol)
-	uri := fmt.Sprintf("wss://stream.binance.com:9443/ws/%s@ticker", symbol)
+	uri := fmt.Sprintf("%s/ws/%s@ticker", StreamURL, symbol)
 
 	client := websocket.NewClient()
+	client.SetReconnect(Reconnect)
+	client.SetReadTimeout(ReadTimeout)
+	if recorder != nil {
+		client.SetRecorder(recorder)
+	}
 	if err := client.Connect(uri); err != nil {
 		log.Fatalf("WebSocket connection error for symbol %s: %v", symbol, err)
 	}
@@ -25,36 +49,51 @@ func MonitorSymbol(symbol string, db *sql.DB, stop chan struct{}) {
 		}
 	}()
 
-	// Create PGWriter
-	pgWriter, err := processor.NewPGWriter(db)
-	if err != nil {
-		log.Fatalf("Error creating PostgreSQL writer for symbol %s: %v", symbol, err)
+	client.AddProcessor(store)
+	for _, proc := range processors {
+		client.AddProcessor(proc)
 	}
-	defer func() {
-		if err := pgWriter.Close(); err != nil {
-			log.Printf("Error closing PostgreSQL writer for symbol %s: %v", symbol, err)
-		}
-	}()
-
-	client.AddProcessor(pgWriter)
 
 	go client.Listen(stop)
 
 	log.Printf("WebSocket connection opened for %s", symbol)
 
-	// Periodic summary
+	<-stop
+	log.Printf("Stopping monitoring for symbol: %s", symbol)
+}
+
+// LogSummary logs how many ticks the store has processed and buffered every
+// 5 seconds until stop is closed
+func LogSummary(store processor.TickStore, stop chan struct{}) {
 	ticker := time.NewTicker(5 * time.Second)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-stop:
-			log.Printf("Stopping monitoring for symbol: %s", symbol)
 			return
 		case <-ticker.C:
-			// Print a summary every 5 seconds
-			log.Printf("Symbol: %s - Last 5 seconds: Processed %d messages", symbol, pgWriter.GetProcessedCount())
-			log.Printf("Symbol: %s - Current buffer size: %d", symbol, pgWriter.GetBufferSize())
+			log.Printf("Tick store - Processed %d messages", store.GetProcessedCount())
+			log.Printf("Tick store - Current buffer size: %d", store.GetBufferSize())
 		}
 	}
 }
+
+// Replay feeds the frames recorded in dir through the normal parsing and
+// processor pipeline in recording order, writing ticks to store. See
+// recorder.Replay for speed.
+func Replay(dir string, speed float64, store processor.TickStore, stop chan struct{}, processors ...processor.DataProcessor) error {
+	log.Printf("Replaying recorded frames from %s at speed %v", dir, speed)
+
+	client := websocket.NewClient()
+	client.AddProcessor(store)
+	for _, proc := range processors {
+		client.AddProcessor(proc)
+	}
+
+	count, err := recorder.ReplayFrames(dir, speed, stop, func(frame recorder.Frame) {
+		client.HandleFrame(frame.ReceivedAt, frame.Data)
+	})
+	log.Printf("Replayed %d frames from %s", count, dir)
+	return err
+}
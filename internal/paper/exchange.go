@@ -0,0 +1,567 @@
+package paper
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+const (
+	defaultFee = 0.001
+	epsilon    = 1e-12
+)
+
+var (
+	// ErrInvalidOrder is returned for malformed order requests
+	ErrInvalidOrder = errors.New("invalid order")
+	// ErrFilter is returned when an order violates the symbol's exchange_info filters
+	ErrFilter = errors.New("order rejected by exchange filter")
+	// ErrInsufficientBalance is returned when the account cannot pay for an order
+	ErrInsufficientBalance = errors.New("insufficient balance")
+	// ErrNoPrice is returned for market orders on symbols that have not ticked yet
+	ErrNoPrice = errors.New("no price for symbol")
+	// ErrUnknownOrder is returned when cancelling an order that is not open
+	ErrUnknownOrder = errors.New("unknown or closed order")
+)
+
+// Config holds the paper trading account and execution settings
+type Config struct {
+	Enabled bool
+	Persist bool
+	// Balances are the starting balances per asset, e.g. usdt: 10000
+	Balances map[string]float64
+	// MakerFee is charged on resting limit orders, TakerFee on everything else
+	MakerFee float64 `mapstructure:"maker_fee"`
+	TakerFee float64 `mapstructure:"taker_fee"`
+	// Slippage moves market and triggered stop fills against the order, e.g. 0.0005 for 5 bps
+	Slippage float64
+	// QuoteAssets is used to split symbols into base and quote assets
+	QuoteAssets []string `mapstructure:"quote_assets"`
+}
+
+// TradeProcessor receives order updates and fills from an Exchange. They are
+// delivered while the exchange holds its dispatch lock, so a processor must
+// not place or cancel orders on the same exchange from these calls, which
+// would deadlock; hand such work to another goroutine instead.
+type TradeProcessor interface {
+	ProcessOrder(order models.Order)
+	ProcessFill(fill models.Fill)
+}
+
+type book struct {
+	bid  float64
+	ask  float64
+	last float64
+}
+
+// buyPrice is the price a taker buy pays, the best ask or the last price
+func (b *book) buyPrice() float64 {
+	if b.ask > 0 {
+		return b.ask
+	}
+	return b.last
+}
+
+// sellPrice is the price a taker sell receives, the best bid or the last price
+func (b *book) sellPrice() float64 {
+	if b.bid > 0 {
+		return b.bid
+	}
+	return b.last
+}
+
+// tradeEvent is an order update or fill waiting to be dispatched
+type tradeEvent struct {
+	order *models.Order
+	fill  *models.Fill
+}
+
+// Exchange implements DataProcessor interface and simulates an exchange fed by
+// the live ticks. Market orders fill against the top of book, limit orders
+// rest until the book crosses their price and stop orders become market
+// orders once the last price reaches the stop. The exchange clock is the
+// event time of the latest tick, so replays are deterministic. Order updates
+// and fills reach the downstream processors after the exchange is unlocked,
+// so slow writers do not hold up other symbols' ticks.
+type Exchange struct {
+	cfg       Config
+	filters   map[string]Filters
+	books     map[string]*book
+	balances  map[string]*models.Balance
+	positions map[string]*models.Position
+	orders    map[int64]*models.Order
+	// open indexes the resting orders by symbol, oldest first
+	open           map[string][]*models.Order
+	locked         map[int64]float64
+	nextID         int64
+	clock          int64
+	fees           float64
+	downstream     []TradeProcessor
+	outbox         []tradeEvent
+	mutex          sync.RWMutex
+	dispatchMutex  sync.Mutex
+	processedCount int
+}
+
+// NewExchange creates a new Exchange with the configured starting balances
+func NewExchange(cfg Config) *Exchange {
+	if cfg.MakerFee == 0 {
+		cfg.MakerFee = defaultFee
+	}
+	if cfg.TakerFee == 0 {
+		cfg.TakerFee = defaultFee
+	}
+
+	exchange := &Exchange{
+		cfg:       cfg,
+		filters:   make(map[string]Filters),
+		books:     make(map[string]*book),
+		balances:  make(map[string]*models.Balance),
+		positions: make(map[string]*models.Position),
+		orders:    make(map[int64]*models.Order),
+		open:      make(map[string][]*models.Order),
+		locked:    make(map[int64]float64),
+	}
+	for asset, amount := range cfg.Balances {
+		exchange.balances[strings.ToUpper(asset)] = &models.Balance{Free: amount}
+	}
+
+	return exchange
+}
+
+// SetFilters replaces the exchange_info filters used to validate orders
+func (e *Exchange) SetFilters(filters map[string]Filters) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.filters = filters
+}
+
+// AddProcessor adds a downstream processor for order updates and fills
+func (e *Exchange) AddProcessor(proc TradeProcessor) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.downstream = append(e.downstream, proc)
+}
+
+// Process implements the DataProcessor interface
+func (e *Exchange) Process(data models.FormattedData) {
+	if data.LastPrice <= 0 {
+		return
+	}
+
+	e.mutex.Lock()
+	defer e.unlockAndDispatch()
+
+	symbol := strings.ToUpper(data.Symbol)
+	b, ok := e.books[symbol]
+	if !ok {
+		b = &book{}
+		e.books[symbol] = b
+	}
+	b.bid, b.ask, b.last = data.BidPrice, data.AskPrice, data.LastPrice
+	if data.EventTime > e.clock {
+		e.clock = data.EventTime
+	}
+	if position, ok := e.positions[symbol]; ok {
+		position.LastPrice = data.LastPrice
+	}
+	e.processedCount++
+
+	open := e.open[symbol]
+	if len(open) == 0 {
+		return
+	}
+	remaining := open[:0]
+	for _, order := range open {
+		e.trigger(order, b)
+		if order.Status == models.StatusNew {
+			remaining = append(remaining, order)
+		}
+	}
+	clear(open[len(remaining):])
+	e.open[symbol] = remaining
+}
+
+// trigger fills a resting order if the book has reached it. The caller must
+// hold the mutex.
+func (e *Exchange) trigger(order *models.Order, b *book) {
+	switch order.Type {
+	case models.OrderLimit:
+		if order.Side == models.SideBuy && b.buyPrice() <= order.Price ||
+			order.Side == models.SideSell && b.sellPrice() >= order.Price {
+			e.fill(order, order.Price, true)
+		}
+	case models.OrderStop:
+		if order.Side == models.SideBuy && b.last >= order.StopPrice ||
+			order.Side == models.SideSell && b.last <= order.StopPrice {
+			e.fill(order, e.takerPrice(order.Side, b), false)
+		}
+	}
+}
+
+// takerPrice is the top of book price moved against the order by the
+// configured slippage. The caller must hold the mutex.
+func (e *Exchange) takerPrice(side models.OrderSide, b *book) float64 {
+	if side == models.SideBuy {
+		return b.buyPrice() * (1 + e.cfg.Slippage)
+	}
+	return b.sellPrice() * (1 - e.cfg.Slippage)
+}
+
+// PlaceOrder validates and submits an order. Market orders and marketable
+// limit orders fill immediately; other orders rest until triggered.
+func (e *Exchange) PlaceOrder(req models.OrderRequest) (models.Order, error) {
+	e.mutex.Lock()
+	defer e.unlockAndDispatch()
+
+	symbol := strings.ToUpper(req.Symbol)
+	base, quote, ok := models.SplitSymbol(symbol, e.cfg.QuoteAssets)
+	if !ok {
+		return models.Order{}, fmt.Errorf("%w: unknown symbol %q", ErrInvalidOrder, req.Symbol)
+	}
+	if req.Side != models.SideBuy && req.Side != models.SideSell {
+		return models.Order{}, fmt.Errorf("%w: unknown side %q", ErrInvalidOrder, req.Side)
+	}
+	if req.Quantity <= 0 {
+		return models.Order{}, fmt.Errorf("%w: quantity must be positive", ErrInvalidOrder)
+	}
+
+	b := e.books[symbol]
+	var checkPrice float64
+	switch req.Type {
+	case models.OrderMarket:
+		if b == nil {
+			return models.Order{}, fmt.Errorf("%w %s", ErrNoPrice, symbol)
+		}
+		checkPrice = b.last
+	case models.OrderLimit:
+		if req.Price <= 0 {
+			return models.Order{}, fmt.Errorf("%w: limit orders need a price", ErrInvalidOrder)
+		}
+		checkPrice = req.Price
+	case models.OrderStop:
+		if req.StopPrice <= 0 {
+			return models.Order{}, fmt.Errorf("%w: stop orders need a stop price", ErrInvalidOrder)
+		}
+		checkPrice = req.StopPrice
+	default:
+		return models.Order{}, fmt.Errorf("%w: unknown type %q", ErrInvalidOrder, req.Type)
+	}
+	if f, ok := e.filters[symbol]; ok {
+		if err := f.Validate(req.Quantity, checkPrice); err != nil {
+			return models.Order{}, err
+		}
+	}
+
+	order := &models.Order{
+		Symbol:      symbol,
+		Side:        req.Side,
+		Type:        req.Type,
+		Quantity:    req.Quantity,
+		Price:       req.Price,
+		StopPrice:   req.StopPrice,
+		Status:      models.StatusNew,
+		CreatedTime: e.clock,
+		UpdatedTime: e.clock,
+	}
+
+	switch {
+	case req.Type == models.OrderMarket:
+		price := e.takerPrice(req.Side, b)
+		if !e.canAfford(order, base, quote, price, e.cfg.TakerFee) {
+			return models.Order{}, ErrInsufficientBalance
+		}
+		e.add(order)
+		e.fill(order, price, false)
+	case req.Type == models.OrderLimit && b != nil && marketable(order, b):
+		price := b.buyPrice()
+		if req.Side == models.SideSell {
+			price = b.sellPrice()
+		}
+		if !e.canAfford(order, base, quote, price, e.cfg.TakerFee) {
+			return models.Order{}, ErrInsufficientBalance
+		}
+		e.add(order)
+		e.fill(order, price, false)
+	case req.Type == models.OrderLimit:
+		asset, amount := base, order.Quantity
+		if order.Side == models.SideBuy {
+			asset, amount = quote, order.Quantity*order.Price*(1+e.cfg.MakerFee)
+		}
+		balance := e.balance(asset)
+		if balance.Free+epsilon < amount {
+			return models.Order{}, ErrInsufficientBalance
+		}
+		balance.Free -= amount
+		balance.Locked += amount
+		e.add(order)
+		e.rest(order)
+		e.locked[order.ID] = amount
+	default:
+		e.add(order)
+		e.rest(order)
+	}
+
+	return *order, nil
+}
+
+// marketable reports whether a limit order crosses the current book
+func marketable(order *models.Order, b *book) bool {
+	if order.Side == models.SideBuy {
+		return b.buyPrice() <= order.Price
+	}
+	return b.sellPrice() >= order.Price
+}
+
+// canAfford reports whether the free balance covers an immediate fill. The
+// caller must hold the mutex.
+func (e *Exchange) canAfford(order *models.Order, base, quote string, price, feeRate float64) bool {
+	if order.Side == models.SideBuy {
+		return e.balance(quote).Free+epsilon >= order.Quantity*price*(1+feeRate)
+	}
+	return e.balance(base).Free+epsilon >= order.Quantity
+}
+
+// add registers a new order. The caller must hold the mutex.
+func (e *Exchange) add(order *models.Order) {
+	e.nextID++
+	order.ID = e.nextID
+	e.orders[order.ID] = order
+	e.emitOrder(order)
+}
+
+// rest indexes an order that waits to be triggered. The caller must hold the
+// mutex.
+func (e *Exchange) rest(order *models.Order) {
+	e.open[order.Symbol] = append(e.open[order.Symbol], order)
+}
+
+// fill executes an order at a price, settling balances and the position. An
+// order that can no longer be paid for is rejected. The caller must hold the
+// mutex.
+func (e *Exchange) fill(order *models.Order, price float64, maker bool) {
+	base, quote, _ := models.SplitSymbol(order.Symbol, e.cfg.QuoteAssets)
+	e.unlock(order)
+
+	feeRate := e.cfg.TakerFee
+	if maker {
+		feeRate = e.cfg.MakerFee
+	}
+	if !e.canAfford(order, base, quote, price, feeRate) {
+		order.Status = models.StatusRejected
+		order.Reason = ErrInsufficientBalance.Error()
+		order.UpdatedTime = e.clock
+		e.emitOrder(order)
+		return
+	}
+
+	notional := order.Quantity * price
+	fee := notional * feeRate
+	if order.Side == models.SideBuy {
+		e.balance(quote).Free -= notional + fee
+		e.balance(base).Free += order.Quantity
+	} else {
+		e.balance(base).Free -= order.Quantity
+		e.balance(quote).Free += notional - fee
+	}
+	e.fees += fee
+	e.updatePosition(order, price, fee)
+
+	order.Status = models.StatusFilled
+	order.FilledPrice = price
+	order.UpdatedTime = e.clock
+	e.emitOrder(order)
+
+	fill := models.Fill{
+		OrderID:   order.ID,
+		Symbol:    order.Symbol,
+		Side:      order.Side,
+		Price:     price,
+		Quantity:  order.Quantity,
+		Fee:       fee,
+		FeeAsset:  quote,
+		Maker:     maker,
+		EventTime: e.clock,
+	}
+	e.outbox = append(e.outbox, tradeEvent{fill: &fill})
+}
+
+// updatePosition applies a fill to the symbol's position. Sells beyond the
+// position (e.g. of starting balances) realize no PnL. The caller must hold
+// the mutex.
+func (e *Exchange) updatePosition(order *models.Order, price, fee float64) {
+	position, ok := e.positions[order.Symbol]
+	if !ok {
+		position = &models.Position{Symbol: order.Symbol}
+		e.positions[order.Symbol] = position
+	}
+	position.LastPrice = e.books[order.Symbol].last
+	position.RealizedPnL -= fee
+
+	if order.Side == models.SideBuy {
+		quantity := position.Quantity + order.Quantity
+		position.AvgPrice = (position.AvgPrice*position.Quantity + price*order.Quantity) / quantity
+		position.Quantity = quantity
+		return
+	}
+
+	covered := order.Quantity
+	if covered > position.Quantity {
+		covered = position.Quantity
+	}
+	position.RealizedPnL += (price - position.AvgPrice) * covered
+	position.Quantity -= covered
+	if position.Quantity <= epsilon {
+		position.Quantity = 0
+		position.AvgPrice = 0
+	}
+}
+
+// unlock releases the funds reserved by a resting order. The caller must hold
+// the mutex.
+func (e *Exchange) unlock(order *models.Order) {
+	amount, ok := e.locked[order.ID]
+	if !ok {
+		return
+	}
+	delete(e.locked, order.ID)
+
+	base, quote, _ := models.SplitSymbol(order.Symbol, e.cfg.QuoteAssets)
+	asset := base
+	if order.Side == models.SideBuy {
+		asset = quote
+	}
+	balance := e.balance(asset)
+	balance.Locked -= amount
+	balance.Free += amount
+}
+
+// balance returns the balance of an asset, creating it if needed. The caller
+// must hold the mutex.
+func (e *Exchange) balance(asset string) *models.Balance {
+	balance, ok := e.balances[asset]
+	if !ok {
+		balance = &models.Balance{}
+		e.balances[asset] = balance
+	}
+	return balance
+}
+
+// emitOrder queues an order update. The caller must hold the mutex.
+func (e *Exchange) emitOrder(order *models.Order) {
+	update := *order
+	e.outbox = append(e.outbox, tradeEvent{order: &update})
+}
+
+// unlockAndDispatch releases the mutex and hands the queued order updates and
+// fills to the downstream processors. dispatchMutex is taken before the
+// release so that events are delivered in the order they happened. It is not
+// reentrant: a processor calling back into PlaceOrder or CancelOrder blocks
+// forever.
+func (e *Exchange) unlockAndDispatch() {
+	events := e.outbox
+	e.outbox = nil
+	if len(events) == 0 {
+		e.mutex.Unlock()
+		return
+	}
+	downstream := e.downstream
+
+	e.dispatchMutex.Lock()
+	defer e.dispatchMutex.Unlock()
+	e.mutex.Unlock()
+
+	for _, event := range events {
+		for _, proc := range downstream {
+			if event.fill != nil {
+				proc.ProcessFill(*event.fill)
+			} else {
+				proc.ProcessOrder(*event.order)
+			}
+		}
+	}
+}
+
+// CancelOrder cancels an open order and releases its reserved funds
+func (e *Exchange) CancelOrder(id int64) (models.Order, error) {
+	e.mutex.Lock()
+	defer e.unlockAndDispatch()
+
+	order, ok := e.orders[id]
+	if !ok || order.Status != models.StatusNew {
+		return models.Order{}, fmt.Errorf("%w: %d", ErrUnknownOrder, id)
+	}
+
+	e.unlock(order)
+	order.Status = models.StatusCanceled
+	order.UpdatedTime = e.clock
+	e.emitOrder(order)
+
+	open := e.open[order.Symbol]
+	if i := slices.Index(open, order); i >= 0 {
+		e.open[order.Symbol] = slices.Delete(open, i, i+1)
+	}
+
+	return *order, nil
+}
+
+// Orders returns all orders, oldest first
+func (e *Exchange) Orders() []models.Order {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	orders := make([]models.Order, 0, len(e.orders))
+	for _, order := range e.orders {
+		orders = append(orders, *order)
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].ID < orders[j].ID })
+	return orders
+}
+
+// Account returns the balances, positions and PnL of the account
+func (e *Exchange) Account() models.Account {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	account := models.Account{
+		Balances: make(map[string]models.Balance, len(e.balances)),
+		Fees:     e.fees,
+	}
+	for asset, balance := range e.balances {
+		account.Balances[asset] = *balance
+	}
+	for _, position := range e.positions {
+		p := *position
+		p.UnrealizedPnL = (p.LastPrice - p.AvgPrice) * p.Quantity
+		account.Positions = append(account.Positions, p)
+		account.RealizedPnL += p.RealizedPnL
+		account.UnrealizedPnL += p.UnrealizedPnL
+	}
+	sort.Slice(account.Positions, func(i, j int) bool { return account.Positions[i].Symbol < account.Positions[j].Symbol })
+
+	return account
+}
+
+// GetProcessedCount returns the number of processed messages
+func (e *Exchange) GetProcessedCount() int {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return e.processedCount
+}
+
+// GetBufferSize returns the number of open orders
+func (e *Exchange) GetBufferSize() int {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	count := 0
+	for _, open := range e.open {
+		count += len(open)
+	}
+	return count
+}
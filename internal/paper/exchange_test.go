@@ -0,0 +1,225 @@
+package paper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+type mockTradeProcessor struct {
+	orders []models.Order
+	fills  []models.Fill
+}
+
+func (m *mockTradeProcessor) ProcessOrder(order models.Order) {
+	m.orders = append(m.orders, order)
+}
+
+func (m *mockTradeProcessor) ProcessFill(fill models.Fill) {
+	m.fills = append(m.fills, fill)
+}
+
+func quote(eventTime int64, symbol string, bid, ask float64) models.FormattedData {
+	return models.FormattedData{EventTime: eventTime, Symbol: symbol, BidPrice: bid, AskPrice: ask, LastPrice: (bid + ask) / 2}
+}
+
+func newTestExchange() *Exchange {
+	return NewExchange(Config{Balances: map[string]float64{"usdt": 10000}, MakerFee: 0.001, TakerFee: 0.002})
+}
+
+func TestExchange_MarketOrder(t *testing.T) {
+	exchange := NewExchange(Config{Balances: map[string]float64{"usdt": 10000}, TakerFee: 0.001, Slippage: 0.001})
+	sink := &mockTradeProcessor{}
+	exchange.AddProcessor(sink)
+
+	_, err := exchange.PlaceOrder(models.OrderRequest{Symbol: "BTCUSDT", Side: models.SideBuy, Type: models.OrderMarket, Quantity: 0.1})
+	assert.ErrorIs(t, err, ErrNoPrice)
+
+	exchange.Process(quote(1000, "BTCUSDT", 49990, 50000))
+	order, err := exchange.PlaceOrder(models.OrderRequest{Symbol: "btcusdt", Side: models.SideBuy, Type: models.OrderMarket, Quantity: 0.1})
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusFilled, order.Status)
+	// Ask plus 10 bps of slippage
+	assert.InDelta(t, 50050.0, order.FilledPrice, 1e-9)
+
+	require.Len(t, sink.fills, 1)
+	assert.InDelta(t, 5.005, sink.fills[0].Fee, 1e-9)
+	assert.Equal(t, "USDT", sink.fills[0].FeeAsset)
+	assert.Equal(t, int64(1000), sink.fills[0].EventTime)
+
+	account := exchange.Account()
+	assert.InDelta(t, 10000-5005-5.005, account.Balances["USDT"].Free, 1e-9)
+	assert.InDelta(t, 0.1, account.Balances["BTC"].Free, 1e-12)
+	require.Len(t, account.Positions, 1)
+	assert.InDelta(t, 50050.0, account.Positions[0].AvgPrice, 1e-9)
+}
+
+func TestExchange_LimitOrderRestsAndFills(t *testing.T) {
+	exchange := newTestExchange()
+	exchange.Process(quote(1000, "BTCUSDT", 49990, 50000))
+
+	order, err := exchange.PlaceOrder(models.OrderRequest{Symbol: "BTCUSDT", Side: models.SideBuy, Type: models.OrderLimit, Quantity: 0.1, Price: 49000})
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusNew, order.Status)
+	assert.Equal(t, 1, exchange.GetBufferSize())
+
+	account := exchange.Account()
+	assert.InDelta(t, 4904.9, account.Balances["USDT"].Locked, 1e-9)
+
+	exchange.Process(quote(2000, "BTCUSDT", 48900, 48950))
+
+	orders := exchange.Orders()
+	require.Len(t, orders, 1)
+	assert.Equal(t, models.StatusFilled, orders[0].Status)
+	assert.Equal(t, 49000.0, orders[0].FilledPrice)
+	assert.Equal(t, int64(2000), orders[0].UpdatedTime)
+
+	account = exchange.Account()
+	assert.InDelta(t, 0, account.Balances["USDT"].Locked, 1e-9)
+	assert.InDelta(t, 10000-4900-4.9, account.Balances["USDT"].Free, 1e-9)
+	assert.Equal(t, 0, exchange.GetBufferSize())
+}
+
+func TestExchange_MarketableLimitFillsAsTaker(t *testing.T) {
+	exchange := newTestExchange()
+	exchange.Process(quote(1000, "BTCUSDT", 49990, 50000))
+
+	order, err := exchange.PlaceOrder(models.OrderRequest{Symbol: "BTCUSDT", Side: models.SideBuy, Type: models.OrderLimit, Quantity: 0.1, Price: 51000})
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusFilled, order.Status)
+	assert.Equal(t, 50000.0, order.FilledPrice)
+	assert.InDelta(t, 10.0, exchange.Account().Fees, 1e-9)
+}
+
+func TestExchange_StopOrderAndPnL(t *testing.T) {
+	exchange := newTestExchange()
+	exchange.Process(quote(1000, "BTCUSDT", 49990, 50010))
+	_, err := exchange.PlaceOrder(models.OrderRequest{Symbol: "BTCUSDT", Side: models.SideBuy, Type: models.OrderMarket, Quantity: 0.1})
+	require.NoError(t, err)
+
+	stop, err := exchange.PlaceOrder(models.OrderRequest{Symbol: "BTCUSDT", Side: models.SideSell, Type: models.OrderStop, Quantity: 0.1, StopPrice: 49000})
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusNew, stop.Status)
+
+	exchange.Process(quote(2000, "BTCUSDT", 49500, 49510))
+	assert.InDelta(t, (49505.0-50010.0)*0.1, exchange.Account().UnrealizedPnL, 1e-9)
+
+	exchange.Process(quote(3000, "BTCUSDT", 48990, 49000))
+
+	orders := exchange.Orders()
+	assert.Equal(t, models.StatusFilled, orders[1].Status)
+	assert.Equal(t, 48990.0, orders[1].FilledPrice)
+
+	account := exchange.Account()
+	require.Len(t, account.Positions, 1)
+	assert.Equal(t, 0.0, account.Positions[0].Quantity)
+	buyFee, sellFee := 5001*0.002, 4899*0.002
+	assert.InDelta(t, -102-buyFee-sellFee, account.RealizedPnL, 1e-9)
+	assert.InDelta(t, buyFee+sellFee, account.Fees, 1e-9)
+	assert.Equal(t, 0.0, account.UnrealizedPnL)
+}
+
+func TestExchange_StopRejectedWithoutBalance(t *testing.T) {
+	exchange := newTestExchange()
+	exchange.Process(quote(1000, "BTCUSDT", 49990, 50010))
+
+	_, err := exchange.PlaceOrder(models.OrderRequest{Symbol: "BTCUSDT", Side: models.SideSell, Type: models.OrderStop, Quantity: 1, StopPrice: 49000})
+	require.NoError(t, err)
+
+	exchange.Process(quote(2000, "BTCUSDT", 48000, 48010))
+
+	order := exchange.Orders()[0]
+	assert.Equal(t, models.StatusRejected, order.Status)
+	assert.Equal(t, ErrInsufficientBalance.Error(), order.Reason)
+}
+
+func TestExchange_CancelReleasesFunds(t *testing.T) {
+	exchange := newTestExchange()
+	sink := &mockTradeProcessor{}
+	exchange.AddProcessor(sink)
+
+	order, err := exchange.PlaceOrder(models.OrderRequest{Symbol: "BTCUSDT", Side: models.SideBuy, Type: models.OrderLimit, Quantity: 0.1, Price: 40000})
+	require.NoError(t, err)
+
+	canceled, err := exchange.CancelOrder(order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusCanceled, canceled.Status)
+
+	balance := exchange.Account().Balances["USDT"]
+	assert.InDelta(t, 10000.0, balance.Free, 1e-9)
+	assert.InDelta(t, 0.0, balance.Locked, 1e-9)
+
+	_, err = exchange.CancelOrder(order.ID)
+	assert.ErrorIs(t, err, ErrUnknownOrder)
+	assert.Len(t, sink.orders, 2)
+	assert.Equal(t, 0, exchange.GetBufferSize())
+}
+
+// accountReader reads the account while handling updates, which needs the
+// exchange to be unlocked
+type accountReader struct {
+	exchange *Exchange
+	updates  []models.OrderStatus
+	balances []float64
+}
+
+func (r *accountReader) ProcessOrder(order models.Order) {
+	r.updates = append(r.updates, order.Status)
+}
+
+func (r *accountReader) ProcessFill(fill models.Fill) {
+	r.balances = append(r.balances, r.exchange.Account().Balances["BTC"].Free)
+}
+
+func TestExchange_DispatchesAfterUnlocking(t *testing.T) {
+	exchange := newTestExchange()
+	reader := &accountReader{exchange: exchange}
+	exchange.AddProcessor(reader)
+
+	exchange.Process(quote(1000, "BTCUSDT", 49990, 50000))
+	exchange.Process(quote(1000, "ETHUSDT", 2999, 3000))
+	_, err := exchange.PlaceOrder(models.OrderRequest{Symbol: "BTCUSDT", Side: models.SideBuy, Type: models.OrderLimit, Quantity: 0.1, Price: 49000})
+	require.NoError(t, err)
+	_, err = exchange.PlaceOrder(models.OrderRequest{Symbol: "ETHUSDT", Side: models.SideBuy, Type: models.OrderLimit, Quantity: 1, Price: 2000})
+	require.NoError(t, err)
+
+	// Only the crossed symbol's order fills
+	exchange.Process(quote(2000, "BTCUSDT", 48900, 48950))
+	assert.Equal(t, []models.OrderStatus{models.StatusNew, models.StatusNew, models.StatusFilled}, reader.updates)
+	require.Len(t, reader.balances, 1)
+	assert.InDelta(t, 0.1, reader.balances[0], 1e-12)
+	assert.Equal(t, 1, exchange.GetBufferSize())
+}
+
+func TestExchange_RejectsInvalidOrders(t *testing.T) {
+	exchange := newTestExchange()
+	exchange.SetFilters(map[string]Filters{"BTCUSDT": {MinQty: 0.00001, StepSize: 0.00001, TickSize: 0.01, MinNotional: 5}})
+	exchange.Process(quote(1000, "BTCUSDT", 49990, 50010))
+
+	tests := []struct {
+		name string
+		req  models.OrderRequest
+		err  error
+	}{
+		{"unknown symbol", models.OrderRequest{Symbol: "XYZ", Side: models.SideBuy, Type: models.OrderMarket, Quantity: 1}, ErrInvalidOrder},
+		{"bad side", models.OrderRequest{Symbol: "BTCUSDT", Side: "HOLD", Type: models.OrderMarket, Quantity: 1}, ErrInvalidOrder},
+		{"no quantity", models.OrderRequest{Symbol: "BTCUSDT", Side: models.SideBuy, Type: models.OrderMarket}, ErrInvalidOrder},
+		{"limit without price", models.OrderRequest{Symbol: "BTCUSDT", Side: models.SideBuy, Type: models.OrderLimit, Quantity: 0.1}, ErrInvalidOrder},
+		{"lot size", models.OrderRequest{Symbol: "BTCUSDT", Side: models.SideBuy, Type: models.OrderMarket, Quantity: 0.000015}, ErrFilter},
+		{"tick size", models.OrderRequest{Symbol: "BTCUSDT", Side: models.SideBuy, Type: models.OrderLimit, Quantity: 0.1, Price: 40000.005}, ErrFilter},
+		{"notional", models.OrderRequest{Symbol: "BTCUSDT", Side: models.SideBuy, Type: models.OrderLimit, Quantity: 0.0001, Price: 40000}, ErrFilter},
+		{"balance", models.OrderRequest{Symbol: "BTCUSDT", Side: models.SideBuy, Type: models.OrderMarket, Quantity: 1}, ErrInsufficientBalance},
+		{"sell balance", models.OrderRequest{Symbol: "BTCUSDT", Side: models.SideSell, Type: models.OrderLimit, Quantity: 1, Price: 60000}, ErrInsufficientBalance},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := exchange.PlaceOrder(tt.req)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+	assert.Empty(t, exchange.Orders())
+}
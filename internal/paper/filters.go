@@ -0,0 +1,106 @@
+package paper
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Filters are the exchange_info trading rules of a symbol. Zero values disable
+// the corresponding check.
+type Filters struct {
+	MinQty      float64
+	MaxQty      float64
+	StepSize    float64
+	MinPrice    float64
+	MaxPrice    float64
+	TickSize    float64
+	MinNotional float64
+}
+
+// Validate checks an order quantity and price against the filters the same
+// way the exchange does, returning an ErrFilter for the first failing rule
+func (f Filters) Validate(quantity, price float64) error {
+	if f.MinQty > 0 && quantity < f.MinQty {
+		return fmt.Errorf("%w: LOT_SIZE quantity %v below minimum %v", ErrFilter, quantity, f.MinQty)
+	}
+	if f.MaxQty > 0 && quantity > f.MaxQty {
+		return fmt.Errorf("%w: LOT_SIZE quantity %v above maximum %v", ErrFilter, quantity, f.MaxQty)
+	}
+	if !onStep(quantity, f.MinQty, f.StepSize) {
+		return fmt.Errorf("%w: LOT_SIZE quantity %v is not a multiple of step size %v", ErrFilter, quantity, f.StepSize)
+	}
+	if price <= 0 {
+		return nil
+	}
+	if f.MinPrice > 0 && price < f.MinPrice {
+		return fmt.Errorf("%w: PRICE_FILTER price %v below minimum %v", ErrFilter, price, f.MinPrice)
+	}
+	if f.MaxPrice > 0 && price > f.MaxPrice {
+		return fmt.Errorf("%w: PRICE_FILTER price %v above maximum %v", ErrFilter, price, f.MaxPrice)
+	}
+	if !onStep(price, f.MinPrice, f.TickSize) {
+		return fmt.Errorf("%w: PRICE_FILTER price %v is not a multiple of tick size %v", ErrFilter, price, f.TickSize)
+	}
+	if f.MinNotional > 0 && quantity*price < f.MinNotional {
+		return fmt.Errorf("%w: NOTIONAL %v below minimum %v", ErrFilter, quantity*price, f.MinNotional)
+	}
+	return nil
+}
+
+// onStep reports whether (value - min) is a whole multiple of step, allowing
+// for floating point error
+func onStep(value, min, step float64) bool {
+	if step <= 0 {
+		return true
+	}
+	steps := (value - min) / step
+	return math.Abs(steps-math.Round(steps)) < 1e-6
+}
+
+// LoadFilters reads the LOT_SIZE, PRICE_FILTER and (MIN_)NOTIONAL filters of
+// every symbol from the exchange_info table
+func LoadFilters(db *sql.DB) (map[string]Filters, error) {
+	rows, err := db.Query(`SELECT symbol, filter_type, filter_key, filter_value FROM exchange_info
+        WHERE filter_type IN ('LOT_SIZE', 'PRICE_FILTER', 'MIN_NOTIONAL', 'NOTIONAL')`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying exchange_info: %w", err)
+	}
+	defer rows.Close()
+
+	filters := make(map[string]Filters)
+	for rows.Next() {
+		var symbol, filterType, key, raw string
+		if err := rows.Scan(&symbol, &filterType, &key, &raw); err != nil {
+			return nil, fmt.Errorf("error scanning exchange_info: %w", err)
+		}
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %s value %q for %s: %w", filterType, key, raw, symbol, err)
+		}
+
+		symbol = strings.ToUpper(symbol)
+		f := filters[symbol]
+		switch filterType + "." + key {
+		case "LOT_SIZE.minQty":
+			f.MinQty = value
+		case "LOT_SIZE.maxQty":
+			f.MaxQty = value
+		case "LOT_SIZE.stepSize":
+			f.StepSize = value
+		case "PRICE_FILTER.minPrice":
+			f.MinPrice = value
+		case "PRICE_FILTER.maxPrice":
+			f.MaxPrice = value
+		case "PRICE_FILTER.tickSize":
+			f.TickSize = value
+		case "MIN_NOTIONAL.minNotional", "NOTIONAL.minNotional":
+			f.MinNotional = value
+		}
+		filters[symbol] = f
+	}
+
+	return filters, rows.Err()
+}
@@ -0,0 +1,43 @@
+package paper
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilters_Validate(t *testing.T) {
+	f := Filters{MinQty: 0.001, MaxQty: 100, StepSize: 0.001, MinPrice: 0.01, MaxPrice: 1000000, TickSize: 0.01, MinNotional: 10}
+
+	assert.NoError(t, f.Validate(0.123, 50000.12))
+	assert.NoError(t, f.Validate(1, 0))
+	assert.ErrorIs(t, f.Validate(0.0005, 50000), ErrFilter)
+	assert.ErrorIs(t, f.Validate(101, 50000), ErrFilter)
+	assert.ErrorIs(t, f.Validate(0.1234, 50000), ErrFilter)
+	assert.ErrorIs(t, f.Validate(1, 0.001), ErrFilter)
+	assert.ErrorIs(t, f.Validate(1, 50000.123), ErrFilter)
+	assert.ErrorIs(t, f.Validate(0.001, 5000), ErrFilter)
+	assert.NoError(t, Filters{}.Validate(0.123456789, 1.23456789))
+}
+
+func TestLoadFilters(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"symbol", "filter_type", "filter_key", "filter_value"}).
+		AddRow("BTCUSDT", "LOT_SIZE", "minQty", "0.00001000").
+		AddRow("BTCUSDT", "LOT_SIZE", "stepSize", "0.00001000").
+		AddRow("BTCUSDT", "PRICE_FILTER", "tickSize", "0.01000000").
+		AddRow("BTCUSDT", "NOTIONAL", "minNotional", "5.00000000").
+		AddRow("ethbtc", "LOT_SIZE", "maxQty", "100000.00000000")
+	mock.ExpectQuery(`SELECT symbol, filter_type, filter_key, filter_value FROM exchange_info`).WillReturnRows(rows)
+
+	filters, err := LoadFilters(db)
+	require.NoError(t, err)
+	assert.Equal(t, Filters{MinQty: 0.00001, StepSize: 0.00001, TickSize: 0.01, MinNotional: 5}, filters["BTCUSDT"])
+	assert.Equal(t, 100000.0, filters["ETHBTC"].MaxQty)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
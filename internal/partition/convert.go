@@ -0,0 +1,231 @@
+package partition
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+const (
+	// staging is the partitioned table filled while converting
+	staging = table + "_partitioned"
+	// nullArchive keeps the rows without an event time, which no partition
+	// can hold
+	nullArchive = table + "_null_event_time"
+)
+
+// IsPartitioned reports whether ticker_data is a partitioned table
+func IsPartitioned(ctx context.Context, db *sql.DB) (bool, error) {
+	var kind string
+	err := db.QueryRowContext(ctx, `SELECT relkind FROM pg_class WHERE oid = to_regclass($1)`, table).Scan(&kind)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error checking whether %s is partitioned: %w", table, err)
+	}
+	return kind == "p", nil
+}
+
+// Convert turns an unpartitioned ticker_data into one range-partitioned by
+// the configured interval. It is an offline step for a stopped monitor with
+// every migration applied, and needs room for a second copy of the table:
+//
+//   - rows without an event time are moved to ticker_data_null_event_time;
+//   - a partitioned copy is created with the same columns, keeping the
+//     INTEGER id and its sequence;
+//   - the rows are copied one period at a time, each in its own transaction,
+//     so an interrupted conversion resumes after the last copied period and
+//     retention can later drop the old periods one by one;
+//   - the copy replaces ticker_data once both hold the same number of rows.
+//
+// Partitions for the current and upcoming periods are created afterwards.
+func (m *Manager) Convert(ctx context.Context, now time.Time) error {
+	partitioned, err := IsPartitioned(ctx, m.db)
+	if err != nil {
+		return err
+	}
+	if partitioned {
+		log.Printf("%s is already partitioned", table)
+		return m.Maintain(ctx, now)
+	}
+
+	if err := m.archiveNullEventTimes(ctx); err != nil {
+		return err
+	}
+	if err := m.createStaging(ctx); err != nil {
+		return err
+	}
+	if err := m.copyPeriods(ctx); err != nil {
+		return err
+	}
+	if err := m.swap(ctx); err != nil {
+		return err
+	}
+	return m.Maintain(ctx, now)
+}
+
+// archiveNullEventTimes moves rows without an event time out of ticker_data
+func (m *Manager) archiveNullEventTimes(ctx context.Context) error {
+	if _, err := m.db.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (LIKE %s INCLUDING DEFAULTS)`, nullArchive, table)); err != nil {
+		return fmt.Errorf("error creating %s: %w", nullArchive, err)
+	}
+	result, err := m.db.ExecContext(ctx, fmt.Sprintf(`WITH moved AS (
+            DELETE FROM %s WHERE event_time IS NULL RETURNING *
+        ) INSERT INTO %s SELECT * FROM moved`, table, nullArchive))
+	if err != nil {
+		return fmt.Errorf("error archiving rows without an event time: %w", err)
+	}
+	if moved, _ := result.RowsAffected(); moved > 0 {
+		log.Printf("Moved %d %s rows without an event time to %s", moved, table, nullArchive)
+	}
+	return nil
+}
+
+// createStaging creates the partitioned copy of ticker_data with the same
+// indexes. Each period is read from the old table through the event time
+// BRIN index created by migration 0003.
+func (m *Manager) createStaging(ctx context.Context) error {
+	statements := []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s
+        (
+            id           INTEGER NOT NULL DEFAULT nextval('ticker_data_id_seq'),
+            event_time   BIGINT  NOT NULL,
+            symbol       TEXT,
+            last_price   DOUBLE PRECISION,
+            price_change DOUBLE PRECISION,
+            high_price   DOUBLE PRECISION,
+            low_price    DOUBLE PRECISION,
+            volume       DOUBLE PRECISION,
+            quote_volume DOUBLE PRECISION,
+            open_time    BIGINT,
+            close_time   BIGINT,
+            trade_count  INT,
+            latency      BIGINT,
+            PRIMARY KEY (id, event_time)
+        ) PARTITION BY RANGE (event_time)`, staging),
+		fmt.Sprintf(`CREATE UNIQUE INDEX IF NOT EXISTS %s_symbol_event_time_key ON %s (symbol, event_time)`, staging, staging),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s_event_time_brin ON %s USING brin (event_time)`, staging, staging),
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s_default PARTITION OF %s DEFAULT`, staging, staging),
+	}
+	for _, statement := range statements {
+		if _, err := m.db.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("error creating %s: %w", staging, err)
+		}
+	}
+	return nil
+}
+
+// copyPeriods copies ticker_data into one partition per period, skipping the
+// periods copied by an earlier run
+func (m *Manager) copyPeriods(ctx context.Context) error {
+	var first, last sql.NullInt64
+	if err := m.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT min(event_time), max(event_time) FROM %s`, table)).Scan(&first, &last); err != nil {
+		return fmt.Errorf("error reading the %s time range: %w", table, err)
+	}
+	if !first.Valid {
+		return nil
+	}
+
+	existing := make(map[string]bool)
+	rows, err := m.db.QueryContext(ctx, `SELECT c.relname
+        FROM pg_inherits i
+        JOIN pg_class c ON c.oid = i.inhrelid
+        JOIN pg_class p ON p.oid = i.inhparent
+        WHERE p.relname = $1`, staging)
+	if err != nil {
+		return fmt.Errorf("error listing copied periods: %w", err)
+	}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		existing[name] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	end := time.UnixMilli(last.Int64)
+	for start := m.periodStart(time.UnixMilli(first.Int64)); !start.After(end); start = m.nextPeriod(start) {
+		name := m.partitionName(start)
+		if existing[name] {
+			continue
+		}
+		copied, err := m.copyPeriod(ctx, name, start.UnixMilli(), m.nextPeriod(start).UnixMilli())
+		if err != nil {
+			return fmt.Errorf("error copying partition %s: %w", name, err)
+		}
+		log.Printf("Copied %d rows into partition %s", copied, name)
+	}
+	return nil
+}
+
+func (m *Manager) copyPeriod(ctx context.Context, name string, from, to int64) (int64, error) {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	ident := quoteIdent(name)
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE %s PARTITION OF %s FOR VALUES FROM (%d) TO (%d)`, ident, staging, from, to)); err != nil {
+		return 0, err
+	}
+	result, err := tx.ExecContext(ctx, fmt.Sprintf(`INSERT INTO %s SELECT * FROM %s WHERE event_time >= %d AND event_time < %d`, ident, table, from, to))
+	if err != nil {
+		return 0, err
+	}
+	copied, _ := result.RowsAffected()
+	return copied, tx.Commit()
+}
+
+// swap replaces ticker_data with the partitioned copy and drops the old
+// table, whose sequence moves to the copy
+func (m *Manager) swap(ctx context.Context) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`LOCK TABLE %s IN ACCESS EXCLUSIVE MODE`, table)); err != nil {
+		return err
+	}
+	var original, copied int64
+	if err := tx.QueryRowContext(ctx, fmt.Sprintf(`SELECT count(*) FROM %s`, table)).Scan(&original); err != nil {
+		return err
+	}
+	if err := tx.QueryRowContext(ctx, fmt.Sprintf(`SELECT count(*) FROM %s`, staging)).Scan(&copied); err != nil {
+		return err
+	}
+	if original != copied {
+		return fmt.Errorf("%s has %d rows but its partitioned copy has %d, was the monitor still running?", table, original, copied)
+	}
+
+	statements := []string{
+		fmt.Sprintf(`ALTER SEQUENCE %s_id_seq OWNED BY %s.id`, table, staging),
+		fmt.Sprintf(`DROP TABLE %s`, table),
+		fmt.Sprintf(`ALTER TABLE %s RENAME TO %s`, staging, table),
+		fmt.Sprintf(`ALTER TABLE %s RENAME CONSTRAINT %s_pkey TO %s_pkey`, table, staging, table),
+		fmt.Sprintf(`ALTER INDEX %s_symbol_event_time_key RENAME TO %s_symbol_event_time_key`, staging, table),
+		fmt.Sprintf(`ALTER INDEX %s_event_time_brin RENAME TO %s_event_time_brin`, staging, table),
+		fmt.Sprintf(`ALTER TABLE %s_default RENAME TO %s_default`, staging, table),
+	}
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("error replacing %s: %w", table, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	log.Printf("Replaced %s with a table partitioned by %s holding %d rows", table, m.cfg.Interval, copied)
+	return nil
+}
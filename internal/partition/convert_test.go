@@ -0,0 +1,145 @@
+package partition
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func expectRelkind(mock sqlmock.Sqlmock, kind string) {
+	mock.ExpectQuery(`SELECT relkind FROM pg_class`).WithArgs("ticker_data").
+		WillReturnRows(sqlmock.NewRows([]string{"relkind"}).AddRow(kind))
+}
+
+func expectStaging(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS ticker_data_null_event_time`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM ticker_data WHERE event_time IS NULL RETURNING \*`).WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS ticker_data_partitioned`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`CREATE UNIQUE INDEX IF NOT EXISTS ticker_data_partitioned_symbol_event_time_key`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`CREATE INDEX IF NOT EXISTS ticker_data_partitioned_event_time_brin ON ticker_data_partitioned USING brin`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS ticker_data_partitioned_default PARTITION OF ticker_data_partitioned DEFAULT`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func expectCopy(mock sqlmock.Sqlmock, name string, from, to int64) {
+	mock.ExpectBegin()
+	mock.ExpectExec(`CREATE TABLE "` + name + `" PARTITION OF ticker_data_partitioned FOR VALUES FROM \(` + fmtInt(from) + `\) TO \(` + fmtInt(to) + `\)`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO "` + name + `" SELECT \* FROM ticker_data WHERE event_time >= ` + fmtInt(from) + ` AND event_time < ` + fmtInt(to)).
+		WillReturnResult(sqlmock.NewResult(0, 10))
+	mock.ExpectCommit()
+}
+
+func expectCounts(mock sqlmock.Sqlmock, original, copied int64) {
+	mock.ExpectBegin()
+	mock.ExpectExec(`LOCK TABLE ticker_data IN ACCESS EXCLUSIVE MODE`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT count\(\*\) FROM ticker_data$`).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(original))
+	mock.ExpectQuery(`SELECT count\(\*\) FROM ticker_data_partitioned`).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(copied))
+}
+
+func TestIsPartitioned(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	expectRelkind(mock, "p")
+	partitioned, err := IsPartitioned(context.Background(), db)
+	require.NoError(t, err)
+	assert.True(t, partitioned)
+
+	expectRelkind(mock, "r")
+	partitioned, err = IsPartitioned(context.Background(), db)
+	require.NoError(t, err)
+	assert.False(t, partitioned)
+
+	// ticker_data does not exist
+	mock.ExpectQuery(`SELECT relkind FROM pg_class`).WillReturnRows(sqlmock.NewRows([]string{"relkind"}))
+	partitioned, err = IsPartitioned(context.Background(), db)
+	require.NoError(t, err)
+	assert.False(t, partitioned)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestManager_ConvertCopiesPeriodsAndSwaps(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	manager, err := NewManager(db, Config{Premake: 1})
+	require.NoError(t, err)
+
+	expectRelkind(mock, "r")
+	expectStaging(mock)
+	mock.ExpectQuery(`SELECT min\(event_time\), max\(event_time\) FROM ticker_data`).
+		WillReturnRows(sqlmock.NewRows([]string{"min", "max"}).AddRow(day(1)+1000, day(3)+1000))
+	// A previous run copied the first day before it was interrupted
+	mock.ExpectQuery(`SELECT c.relname\s+FROM pg_inherits`).WithArgs("ticker_data_partitioned").
+		WillReturnRows(sqlmock.NewRows([]string{"relname"}).AddRow("ticker_data_partitioned_default").AddRow("ticker_data_d20240101"))
+	expectCopy(mock, "ticker_data_d20240102", day(2), day(3))
+	expectCopy(mock, "ticker_data_d20240103", day(3), day(4))
+	expectCounts(mock, 30, 30)
+	mock.ExpectExec(`ALTER SEQUENCE ticker_data_id_seq OWNED BY ticker_data_partitioned.id`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DROP TABLE ticker_data$`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`ALTER TABLE ticker_data_partitioned RENAME TO ticker_data`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`ALTER TABLE ticker_data RENAME CONSTRAINT ticker_data_partitioned_pkey TO ticker_data_pkey`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`ALTER INDEX ticker_data_partitioned_symbol_event_time_key RENAME TO ticker_data_symbol_event_time_key`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`ALTER INDEX ticker_data_partitioned_event_time_brin RENAME TO ticker_data_event_time_brin`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`ALTER TABLE ticker_data_partitioned_default RENAME TO ticker_data_default`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	expectPartitions(mock,
+		[2]string{"ticker_data_d20240101", rangeBound(day(1), day(2))},
+		[2]string{"ticker_data_d20240102", rangeBound(day(2), day(3))},
+		[2]string{"ticker_data_d20240103", rangeBound(day(3), day(4))},
+		[2]string{"ticker_data_default", "DEFAULT"},
+	)
+	expectCreate(mock, "ticker_data_d20240104", day(4), day(5))
+
+	require.NoError(t, manager.Convert(context.Background(), now))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestManager_ConvertRefusesMismatchedCounts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	manager, err := NewManager(db, Config{})
+	require.NoError(t, err)
+
+	expectRelkind(mock, "r")
+	expectStaging(mock)
+	mock.ExpectQuery(`SELECT min\(event_time\), max\(event_time\) FROM ticker_data`).
+		WillReturnRows(sqlmock.NewRows([]string{"min", "max"}).AddRow(day(3), day(3)+1000))
+	mock.ExpectQuery(`SELECT c.relname\s+FROM pg_inherits`).WillReturnRows(sqlmock.NewRows([]string{"relname"}))
+	expectCopy(mock, "ticker_data_d20240103", day(3), day(4))
+	expectCounts(mock, 11, 10)
+	mock.ExpectRollback()
+
+	err = manager.Convert(context.Background(), now)
+	assert.ErrorContains(t, err, "has 11 rows but its partitioned copy has 10")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestManager_ConvertAlreadyPartitioned(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	manager, err := NewManager(db, Config{Premake: 1})
+	require.NoError(t, err)
+
+	expectRelkind(mock, "p")
+	expectPartitions(mock,
+		[2]string{"ticker_data_d20240103", rangeBound(day(3), day(4))},
+		[2]string{"ticker_data_d20240104", rangeBound(day(4), day(5))},
+	)
+
+	require.NoError(t, manager.Convert(context.Background(), now))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,260 @@
+package partition
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+const (
+	table                = "ticker_data"
+	defaultInterval      = "day"
+	defaultPremake       = 7
+	defaultCheckInterval = time.Hour
+	minValue             = -1 << 63
+	maxValue             = 1<<63 - 1
+)
+
+// lowerBound and upperBound extract the bounds of a range partition; MINVALUE
+// and MAXVALUE do not match
+var (
+	lowerBound = regexp.MustCompile(`FROM \('?(-?\d+)'?\)`)
+	upperBound = regexp.MustCompile(`TO \('?(-?\d+)'?\)`)
+)
+
+// Config holds the ticker_data partitioning settings
+type Config struct {
+	Enabled bool
+	// Interval is the partition width, "day" or "week" (weeks start on Monday UTC)
+	Interval string
+	// Premake is how many partitions to create ahead of the current one
+	Premake int
+	// Retention drops partitions whose newest possible row is older than
+	// this; zero keeps all data
+	Retention time.Duration
+	// CheckInterval is how often partitions are created and dropped
+	CheckInterval time.Duration `mapstructure:"check_interval"`
+}
+
+// Partition is a child table of ticker_data covering [From, To) in event
+// time milliseconds. The default partition has neither bound.
+type Partition struct {
+	Name    string
+	From    int64
+	To      int64
+	Default bool
+}
+
+// Manager creates upcoming ticker_data partitions and drops expired ones
+type Manager struct {
+	db    *sql.DB
+	cfg   Config
+	guard func(ctx context.Context) (int64, error)
+}
+
+// NewManager creates a new Manager
+func NewManager(db *sql.DB, cfg Config) (*Manager, error) {
+	if cfg.Interval == "" {
+		cfg.Interval = defaultInterval
+	}
+	if cfg.Interval != "day" && cfg.Interval != "week" {
+		return nil, fmt.Errorf("invalid partition interval %q, expected day or week", cfg.Interval)
+	}
+	if cfg.Premake <= 0 {
+		cfg.Premake = defaultPremake
+	}
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = defaultCheckInterval
+	}
+	if cfg.Retention < 0 {
+		return nil, fmt.Errorf("partition retention must not be negative")
+	}
+
+	return &Manager{db: db, cfg: cfg}, nil
+}
+
+// SetRetentionGuard keeps partitions holding rows at or after the event time
+// returned by guard, e.g. ticks that have not been rolled up yet
+func (m *Manager) SetRetentionGuard(guard func(ctx context.Context) (int64, error)) {
+	m.guard = guard
+}
+
+// periodStart returns the start of the partition period containing t
+func (m *Manager) periodStart(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if m.cfg.Interval == "week" {
+		// Monday is the first day of the week
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	}
+	return day
+}
+
+func (m *Manager) nextPeriod(start time.Time) time.Time {
+	if m.cfg.Interval == "week" {
+		return start.AddDate(0, 0, 7)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// partitionName names a partition after its interval and start date, e.g.
+// ticker_data_d20240101 or ticker_data_w20240101
+func (m *Manager) partitionName(start time.Time) string {
+	return fmt.Sprintf("%s_%c%s", table, m.cfg.Interval[0], start.Format("20060102"))
+}
+
+// Partitions lists the partitions of ticker_data
+func (m *Manager) Partitions(ctx context.Context) ([]Partition, error) {
+	rows, err := m.db.QueryContext(ctx, `SELECT c.relname, pg_get_expr(c.relpartbound, c.oid)
+        FROM pg_inherits i
+        JOIN pg_class c ON c.oid = i.inhrelid
+        JOIN pg_class p ON p.oid = i.inhparent
+        WHERE p.relname = $1
+        ORDER BY c.relname`, table)
+	if err != nil {
+		return nil, fmt.Errorf("error listing partitions: %w", err)
+	}
+	defer rows.Close()
+
+	var partitions []Partition
+	for rows.Next() {
+		var name, bound string
+		if err := rows.Scan(&name, &bound); err != nil {
+			return nil, err
+		}
+		partitions = append(partitions, parseBound(name, bound))
+	}
+	return partitions, rows.Err()
+}
+
+// parseBound reads a partition bound such as
+// FOR VALUES FROM ('1704067200000') TO ('1704153600000')
+func parseBound(name, bound string) Partition {
+	p := Partition{Name: name}
+	if bound == "DEFAULT" {
+		p.Default = true
+		return p
+	}
+	p.From, p.To = minValue, maxValue
+	if match := lowerBound.FindStringSubmatch(bound); match != nil {
+		p.From, _ = strconv.ParseInt(match[1], 10, 64)
+	}
+	if match := upperBound.FindStringSubmatch(bound); match != nil {
+		p.To, _ = strconv.ParseInt(match[1], 10, 64)
+	}
+	return p
+}
+
+// Maintain creates the partitions for the current period and the next
+// Premake periods and drops partitions past the retention
+func (m *Manager) Maintain(ctx context.Context, now time.Time) error {
+	partitions, err := m.Partitions(ctx)
+	if err != nil {
+		return err
+	}
+
+	start := m.periodStart(now)
+	for i := 0; i <= m.cfg.Premake; i++ {
+		end := m.nextPeriod(start)
+		if from, to, ok := gap(partitions, start.UnixMilli(), end.UnixMilli()); ok {
+			name := m.partitionName(start)
+			if err := m.create(ctx, name, from, to); err != nil {
+				return fmt.Errorf("error creating partition %s: %w", name, err)
+			}
+			log.Printf("Created partition %s", name)
+			partitions = append(partitions, Partition{Name: name, From: from, To: to})
+		}
+		start = end
+	}
+
+	if m.cfg.Retention == 0 {
+		return nil
+	}
+	cutoff := now.Add(-m.cfg.Retention).UnixMilli()
+	if m.guard != nil {
+		guarded, err := m.guard(ctx)
+		if err != nil {
+			return fmt.Errorf("error checking retention guard: %w", err)
+		}
+		cutoff = min(cutoff, guarded)
+	}
+	for _, p := range partitions {
+		if p.Default || p.To > cutoff {
+			continue
+		}
+		if _, err := m.db.ExecContext(ctx, fmt.Sprintf(`DROP TABLE %s`, quoteIdent(p.Name))); err != nil {
+			return fmt.Errorf("error dropping partition %s: %w", p.Name, err)
+		}
+		log.Printf("Dropped partition %s past the %s retention", p.Name, m.cfg.Retention)
+	}
+	return nil
+}
+
+// gap returns the first part of [from, to) not covered by an existing
+// partition, e.g. the rest of a week partly covered by daily partitions
+func gap(partitions []Partition, from, to int64) (int64, int64, bool) {
+	for moved := true; moved; {
+		moved = false
+		for _, p := range partitions {
+			if !p.Default && p.From <= from && from < p.To {
+				from, moved = p.To, true
+			}
+		}
+	}
+	for _, p := range partitions {
+		if !p.Default && from < p.From && p.From < to {
+			to = p.From
+		}
+	}
+	return from, to, from < to
+}
+
+// create adds a partition, moving any rows in its range out of the default
+// partition first so that attaching it does not fail
+func (m *Manager) create(ctx context.Context, name string, from, to int64) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	ident := quoteIdent(name)
+	statements := []string{
+		fmt.Sprintf(`CREATE TABLE %s (LIKE %s INCLUDING DEFAULTS INCLUDING CONSTRAINTS)`, ident, table),
+		fmt.Sprintf(`WITH moved AS (
+            DELETE FROM %s_default WHERE event_time >= %d AND event_time < %d RETURNING *
+        ) INSERT INTO %s SELECT * FROM moved`, table, from, to, ident),
+		fmt.Sprintf(`ALTER TABLE %s ATTACH PARTITION %s FOR VALUES FROM (%d) TO (%d)`, table, ident, from, to),
+	}
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func quoteIdent(name string) string {
+	return `"` + name + `"`
+}
+
+// Run maintains the partitions every CheckInterval until stop is closed
+func (m *Manager) Run(stop chan struct{}) {
+	ticker := time.NewTicker(m.cfg.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			if err := m.Maintain(context.Background(), now); err != nil {
+				log.Printf("Error maintaining ticker_data partitions: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package partition
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 2024-01-03 12:00 UTC, a Wednesday
+var now = time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC)
+
+func day(d int) int64 {
+	return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC).UnixMilli()
+}
+
+func fmtInt(v int64) string {
+	return strconv.FormatInt(v, 10)
+}
+
+// rangeBound formats a bound the way pg_get_expr does
+func rangeBound(from, to int64) string {
+	return "FOR VALUES FROM ('" + fmtInt(from) + "') TO ('" + fmtInt(to) + "')"
+}
+
+func expectPartitions(mock sqlmock.Sqlmock, partitions ...[2]string) {
+	rows := sqlmock.NewRows([]string{"relname", "bound"})
+	for _, p := range partitions {
+		rows.AddRow(p[0], p[1])
+	}
+	mock.ExpectQuery(`SELECT c.relname, pg_get_expr`).WithArgs("ticker_data").WillReturnRows(rows)
+}
+
+func expectCreate(mock sqlmock.Sqlmock, name string, from, to int64) {
+	mock.ExpectBegin()
+	mock.ExpectExec(`CREATE TABLE "` + name + `" \(LIKE ticker_data`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM ticker_data_default WHERE event_time >= ` + fmtInt(from) + ` AND event_time < ` + fmtInt(to)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`ALTER TABLE ticker_data ATTACH PARTITION "` + name + `" FOR VALUES FROM \(` + fmtInt(from) + `\) TO \(` + fmtInt(to) + `\)`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+}
+
+func TestNewManager_Errors(t *testing.T) {
+	_, err := NewManager(nil, Config{Interval: "month"})
+	assert.Error(t, err)
+	_, err = NewManager(nil, Config{Retention: -time.Hour})
+	assert.Error(t, err)
+}
+
+func TestParseBound(t *testing.T) {
+	assert.Equal(t, Partition{Name: "ticker_data_default", Default: true}, parseBound("ticker_data_default", "DEFAULT"))
+	assert.Equal(t, Partition{Name: "ticker_data_legacy", From: minValue, To: day(2)},
+		parseBound("ticker_data_legacy", "FOR VALUES FROM (MINVALUE) TO ('"+fmtInt(day(2))+"')"))
+	assert.Equal(t, Partition{Name: "ticker_data_d20240102", From: day(2), To: day(3)},
+		parseBound("ticker_data_d20240102", rangeBound(day(2), day(3))))
+}
+
+func TestManager_MaintainCreatesDailyPartitions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	manager, err := NewManager(db, Config{Premake: 2})
+	require.NoError(t, err)
+
+	expectPartitions(mock,
+		[2]string{"ticker_data_d20240103", rangeBound(day(3), day(4))},
+		[2]string{"ticker_data_default", "DEFAULT"},
+	)
+	expectCreate(mock, "ticker_data_d20240104", day(4), day(5))
+	expectCreate(mock, "ticker_data_d20240105", day(5), day(6))
+
+	require.NoError(t, manager.Maintain(context.Background(), now))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestManager_MaintainCreatesWeeklyPartitions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	manager, err := NewManager(db, Config{Interval: "week", Premake: 1})
+	require.NoError(t, err)
+
+	// The legacy partition ends on Tuesday, so the current week's partition
+	// starts there
+	expectPartitions(mock,
+		[2]string{"ticker_data_legacy", "FOR VALUES FROM (MINVALUE) TO ('" + fmtInt(day(2)) + "')"},
+	)
+	expectCreate(mock, "ticker_data_w20240101", day(2), day(8))
+	expectCreate(mock, "ticker_data_w20240108", day(8), day(15))
+
+	require.NoError(t, manager.Maintain(context.Background(), now))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGap(t *testing.T) {
+	partitions := []Partition{
+		{Name: "ticker_data_legacy", From: minValue, To: day(2)},
+		{Name: "ticker_data_d20240102", From: day(2), To: day(3)},
+		{Name: "ticker_data_d20240105", From: day(5), To: day(6)},
+		{Name: "ticker_data_default", Default: true},
+	}
+
+	from, to, ok := gap(partitions, day(1), day(8))
+	assert.True(t, ok)
+	assert.Equal(t, day(3), from)
+	assert.Equal(t, day(5), to)
+
+	_, _, ok = gap(partitions, day(2), day(3))
+	assert.False(t, ok)
+}
+
+func TestManager_MaintainDropsExpiredPartitions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	manager, err := NewManager(db, Config{Premake: 1, Retention: 36 * time.Hour})
+	require.NoError(t, err)
+
+	expectPartitions(mock,
+		[2]string{"ticker_data_d20240101", rangeBound(day(1), day(2))},
+		[2]string{"ticker_data_d20240102", rangeBound(day(2), day(3))},
+		[2]string{"ticker_data_d20240103", rangeBound(day(3), day(4))},
+		[2]string{"ticker_data_d20240104", rangeBound(day(4), day(5))},
+		[2]string{"ticker_data_default", "DEFAULT"},
+	)
+	// The cutoff is 2024-01-02 00:00, so only the first day has fully expired
+	mock.ExpectExec(`DROP TABLE "ticker_data_d20240101"`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	require.NoError(t, manager.Maintain(context.Background(), now))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestManager_RetentionGuard(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	manager, err := NewManager(db, Config{Premake: 1, Retention: time.Hour})
+	require.NoError(t, err)
+	// Ticks are only rolled up until 2024-01-02 06:00
+	manager.SetRetentionGuard(func(ctx context.Context) (int64, error) {
+		return day(2) + 6*time.Hour.Milliseconds(), nil
+	})
+
+	expectPartitions(mock,
+		[2]string{"ticker_data_d20240101", rangeBound(day(1), day(2))},
+		[2]string{"ticker_data_d20240102", rangeBound(day(2), day(3))},
+		[2]string{"ticker_data_d20240103", rangeBound(day(3), day(4))},
+		[2]string{"ticker_data_d20240104", rangeBound(day(4), day(5))},
+	)
+	mock.ExpectExec(`DROP TABLE "ticker_data_d20240101"`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	require.NoError(t, manager.Maintain(context.Background(), now))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
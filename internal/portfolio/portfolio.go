@@ -0,0 +1,225 @@
+package portfolio
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+const (
+	defaultQuote        = "USDT"
+	defaultEmitInterval = time.Minute
+	dayMillis           = int64(24 * time.Hour / time.Millisecond)
+)
+
+// Config holds the portfolio holdings and valuation settings
+type Config struct {
+	Enabled bool
+	Persist bool
+	// Quote is the currency the portfolio is valued in
+	Quote string
+	// Holdings maps an asset to the quantity held, e.g. btc: 0.5
+	Holdings map[string]float64
+	// EmitInterval is the event-time spacing of valuations sent downstream
+	EmitInterval time.Duration `mapstructure:"emit_interval"`
+	// QuoteAssets is used to split symbols into base and quote assets
+	QuoteAssets []string `mapstructure:"quote_assets"`
+}
+
+// ValuationProcessor receives the portfolio valuation time series
+type ValuationProcessor interface {
+	ProcessValuation(valuation models.PortfolioValuation)
+}
+
+// edge is a conversion from one asset to another through a market
+type edge struct {
+	to     string
+	symbol string
+	invert bool
+}
+
+// Valuer implements DataProcessor interface and values the configured holdings
+// on every tick, converting through intermediate assets when there is no
+// direct market to the quote currency (e.g. LTC -> BTC -> USDT).
+type Valuer struct {
+	cfg            Config
+	holdings       map[string]float64
+	prices         map[string]float64
+	graph          map[string][]edge
+	day            int64
+	dayOpen        map[string]float64
+	latest         models.PortfolioValuation
+	lastEmit       int64
+	emitInterval   int64
+	downstream     []ValuationProcessor
+	mutex          sync.RWMutex
+	processedCount int
+}
+
+// NewValuer creates a new Valuer
+func NewValuer(cfg Config) *Valuer {
+	if cfg.Quote == "" {
+		cfg.Quote = defaultQuote
+	}
+	cfg.Quote = strings.ToUpper(cfg.Quote)
+	if cfg.EmitInterval <= 0 {
+		cfg.EmitInterval = defaultEmitInterval
+	}
+
+	holdings := make(map[string]float64, len(cfg.Holdings))
+	for asset, quantity := range cfg.Holdings {
+		holdings[strings.ToUpper(asset)] = quantity
+	}
+
+	return &Valuer{
+		cfg:          cfg,
+		holdings:     holdings,
+		prices:       make(map[string]float64),
+		graph:        make(map[string][]edge),
+		dayOpen:      make(map[string]float64),
+		emitInterval: cfg.EmitInterval.Milliseconds(),
+	}
+}
+
+// AddProcessor adds a downstream processor for the valuation time series
+func (v *Valuer) AddProcessor(proc ValuationProcessor) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	v.downstream = append(v.downstream, proc)
+}
+
+// Process implements the DataProcessor interface
+func (v *Valuer) Process(data models.FormattedData) {
+	if data.LastPrice <= 0 {
+		return
+	}
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	symbol := strings.ToUpper(data.Symbol)
+	if _, known := v.prices[symbol]; !known {
+		base, quote, ok := models.SplitSymbol(symbol, v.cfg.QuoteAssets)
+		if !ok {
+			return
+		}
+		v.graph[base] = append(v.graph[base], edge{to: quote, symbol: symbol})
+		v.graph[quote] = append(v.graph[quote], edge{to: base, symbol: symbol, invert: true})
+	}
+	v.prices[symbol] = data.LastPrice
+	v.processedCount++
+
+	v.latest = v.value(data.EventTime)
+
+	if data.EventTime-v.lastEmit >= v.emitInterval {
+		v.lastEmit = data.EventTime - data.EventTime%v.emitInterval
+		for _, proc := range v.downstream {
+			proc.ProcessValuation(v.latest)
+		}
+	}
+}
+
+// value computes the portfolio valuation. A tick from an earlier day, late
+// from another symbol, does not reset the day's open. The caller must hold
+// the mutex.
+func (v *Valuer) value(eventTime int64) models.PortfolioValuation {
+	if day := eventTime / dayMillis; day > v.day {
+		v.day = day
+		v.dayOpen = make(map[string]float64)
+	}
+
+	valuation := models.PortfolioValuation{
+		EventTime: eventTime,
+		Quote:     v.cfg.Quote,
+	}
+
+	for asset, quantity := range v.holdings {
+		price, ok := v.price(asset)
+		if !ok {
+			valuation.Unpriced = append(valuation.Unpriced, asset)
+			continue
+		}
+		value := price * quantity
+		open, ok := v.dayOpen[asset]
+		if !ok {
+			open = value
+			v.dayOpen[asset] = open
+		}
+		valuation.Assets = append(valuation.Assets, models.AssetValuation{
+			Asset:    asset,
+			Quantity: quantity,
+			Price:    price,
+			Value:    value,
+			DayPnL:   value - open,
+		})
+		valuation.TotalValue += value
+		valuation.DayOpenValue += open
+	}
+
+	for i := range valuation.Assets {
+		if valuation.TotalValue != 0 {
+			valuation.Assets[i].Weight = valuation.Assets[i].Value / valuation.TotalValue
+		}
+	}
+	valuation.DayPnL = valuation.TotalValue - valuation.DayOpenValue
+	if valuation.DayOpenValue != 0 {
+		valuation.DayPnLPercent = valuation.DayPnL / valuation.DayOpenValue * 100
+	}
+
+	sort.Slice(valuation.Assets, func(i, j int) bool { return valuation.Assets[i].Asset < valuation.Assets[j].Asset })
+	sort.Strings(valuation.Unpriced)
+
+	return valuation
+}
+
+// price finds the price of an asset in the quote currency using the route
+// with the fewest conversions. The caller must hold the mutex.
+func (v *Valuer) price(asset string) (float64, bool) {
+	if asset == v.cfg.Quote {
+		return 1, true
+	}
+
+	rates := map[string]float64{asset: 1}
+	queue := []string{asset}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, e := range v.graph[current] {
+			if _, seen := rates[e.to]; seen {
+				continue
+			}
+			rate := v.prices[e.symbol]
+			if e.invert {
+				rate = 1 / rate
+			}
+			rates[e.to] = rates[current] * rate
+			if e.to == v.cfg.Quote {
+				return rates[e.to], true
+			}
+			queue = append(queue, e.to)
+		}
+	}
+	return 0, false
+}
+
+// Latest returns the most recent valuation
+func (v *Valuer) Latest() models.PortfolioValuation {
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+	return v.latest
+}
+
+// GetProcessedCount returns the number of processed messages
+func (v *Valuer) GetProcessedCount() int {
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+	return v.processedCount
+}
+
+// GetBufferSize returns the current size of the buffer (always 0, valuations are computed in place)
+func (v *Valuer) GetBufferSize() int {
+	return 0
+}
@@ -0,0 +1,96 @@
+package portfolio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+type mockValuationProcessor struct {
+	valuations []models.PortfolioValuation
+}
+
+func (m *mockValuationProcessor) ProcessValuation(valuation models.PortfolioValuation) {
+	m.valuations = append(m.valuations, valuation)
+}
+
+func tick(eventTime int64, symbol string, price float64) models.FormattedData {
+	return models.FormattedData{EventTime: eventTime, Symbol: symbol, LastPrice: price}
+}
+
+func TestValuer_DirectAndRoutedPrices(t *testing.T) {
+	valuer := NewValuer(Config{Holdings: map[string]float64{"btc": 0.5, "ltc": 10, "usdt": 1000, "doge": 100}})
+
+	valuer.Process(tick(1000, "BTCUSDT", 60000))
+	valuer.Process(tick(1000, "LTCBTC", 0.001))
+
+	valuation := valuer.Latest()
+	assert.Equal(t, "USDT", valuation.Quote)
+	require.Len(t, valuation.Assets, 3)
+	assert.Equal(t, []string{"DOGE"}, valuation.Unpriced)
+
+	btc, ltc, usdt := valuation.Assets[0], valuation.Assets[1], valuation.Assets[2]
+	assert.Equal(t, "BTC", btc.Asset)
+	assert.Equal(t, 30000.0, btc.Value)
+	// LTC has no USDT market and is priced through BTC
+	assert.Equal(t, "LTC", ltc.Asset)
+	assert.InDelta(t, 60.0, ltc.Price, 1e-9)
+	assert.InDelta(t, 600.0, ltc.Value, 1e-9)
+	assert.Equal(t, 1.0, usdt.Price)
+	assert.InDelta(t, 31600.0, valuation.TotalValue, 1e-9)
+	assert.InDelta(t, 30000.0/31600.0, btc.Weight, 1e-9)
+}
+
+func TestValuer_InvertedMarket(t *testing.T) {
+	valuer := NewValuer(Config{Quote: "btc", Holdings: map[string]float64{"usdt": 6000}})
+
+	valuer.Process(tick(1000, "BTCUSDT", 60000))
+
+	assert.InDelta(t, 0.1, valuer.Latest().TotalValue, 1e-12)
+}
+
+func TestValuer_DayPnL(t *testing.T) {
+	valuer := NewValuer(Config{Holdings: map[string]float64{"btc": 1}})
+	day := int64(24 * time.Hour / time.Millisecond)
+
+	valuer.Process(tick(day+1000, "BTCUSDT", 60000))
+	valuer.Process(tick(day+2000, "BTCUSDT", 63000))
+
+	valuation := valuer.Latest()
+	assert.Equal(t, 60000.0, valuation.DayOpenValue)
+	assert.Equal(t, 3000.0, valuation.DayPnL)
+	assert.Equal(t, 5.0, valuation.DayPnLPercent)
+	assert.Equal(t, 3000.0, valuation.Assets[0].DayPnL)
+
+	// A new UTC day resets the opening value
+	valuer.Process(tick(2*day+1000, "BTCUSDT", 64000))
+	valuation = valuer.Latest()
+	assert.Equal(t, 64000.0, valuation.DayOpenValue)
+	assert.Equal(t, 0.0, valuation.DayPnL)
+
+	// A late tick from the previous day leaves the new day's open alone
+	valuer.Process(tick(2*day-1000, "BTCUSDT", 63500))
+	valuer.Process(tick(2*day+2000, "BTCUSDT", 65000))
+	valuation = valuer.Latest()
+	assert.Equal(t, 64000.0, valuation.DayOpenValue)
+	assert.Equal(t, 1000.0, valuation.DayPnL)
+}
+
+func TestValuer_EmitsTimeSeries(t *testing.T) {
+	valuer := NewValuer(Config{Holdings: map[string]float64{"btc": 1}, EmitInterval: time.Second})
+	sink := &mockValuationProcessor{}
+	valuer.AddProcessor(sink)
+
+	valuer.Process(tick(1000, "BTCUSDT", 60000))
+	valuer.Process(tick(1500, "BTCUSDT", 60100))
+	valuer.Process(tick(2100, "BTCUSDT", 60200))
+
+	require.Len(t, sink.valuations, 2)
+	assert.Equal(t, int64(1000), sink.valuations[0].EventTime)
+	assert.Equal(t, int64(2100), sink.valuations[1].EventTime)
+	assert.Equal(t, 3, valuer.GetProcessedCount())
+}
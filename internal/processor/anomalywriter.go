@@ -0,0 +1,50 @@
+package processor
+
+import (
+	"database/sql"
+	"log"
+	"sync"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+// AnomalyWriter persists detected anomalies to PostgreSQL
+type AnomalyWriter struct {
+	db             *sql.DB
+	mutex          sync.Mutex
+	processedCount int
+}
+
+// NewAnomalyWriter creates a new AnomalyWriter
+func NewAnomalyWriter(db *sql.DB) (*AnomalyWriter, error) {
+	writer := &AnomalyWriter{
+		db: db,
+	}
+
+	return writer, nil
+}
+
+// ProcessAnomaly stores a detected anomaly
+func (w *AnomalyWriter) ProcessAnomaly(anomaly models.Anomaly) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	_, err := w.db.Exec(`INSERT INTO anomalies (
+        symbol, kind, event_time, value, score, message
+    ) VALUES ($1, $2, $3, $4, $5, $6)`,
+		anomaly.Symbol, string(anomaly.Kind), anomaly.EventTime, anomaly.Value, anomaly.Score, anomaly.Message,
+	)
+	if err != nil {
+		log.Printf("Error inserting anomaly: %v", err)
+		return
+	}
+
+	w.processedCount++
+}
+
+// GetProcessedCount returns the number of stored anomalies
+func (w *AnomalyWriter) GetProcessedCount() int {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.processedCount
+}
@@ -0,0 +1,36 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnomalyWriter_ProcessAnomaly(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	writer, err := NewAnomalyWriter(db)
+	assert.NoError(t, err)
+
+	anomaly := models.Anomaly{
+		Symbol:    "BTCUSDT",
+		Kind:      models.VolumeSpike,
+		EventTime: 1625097600000,
+		Value:     500,
+		Score:     12.5,
+		Message:   "BTCUSDT volume delta 500 is a spike",
+	}
+
+	mock.ExpectExec(`INSERT INTO anomalies`).
+		WithArgs(anomaly.Symbol, "volume_spike", anomaly.EventTime, anomaly.Value, anomaly.Score, anomaly.Message).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	writer.ProcessAnomaly(anomaly)
+
+	assert.Equal(t, 1, writer.GetProcessedCount())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,52 @@
+package processor
+
+import (
+	"database/sql"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+// ArbitrageWriter persists closed arbitrage opportunities to PostgreSQL
+type ArbitrageWriter struct {
+	db             *sql.DB
+	mutex          sync.Mutex
+	processedCount int
+}
+
+// NewArbitrageWriter creates a new ArbitrageWriter
+func NewArbitrageWriter(db *sql.DB) (*ArbitrageWriter, error) {
+	writer := &ArbitrageWriter{
+		db: db,
+	}
+
+	return writer, nil
+}
+
+// ProcessOpportunity stores a closed opportunity
+func (w *ArbitrageWriter) ProcessOpportunity(opportunity models.ArbitrageOpportunity) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	_, err := w.db.Exec(`INSERT INTO arbitrage_opportunities (
+        path, symbols, start_time, end_time, duration_ms, max_return, last_return
+    ) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		strings.Join(opportunity.Path, ">"), strings.Join(opportunity.Symbols, ","), opportunity.StartTime,
+		opportunity.EndTime, opportunity.DurationMs, opportunity.MaxReturn, opportunity.LastReturn,
+	)
+	if err != nil {
+		log.Printf("Error inserting arbitrage opportunity: %v", err)
+		return
+	}
+
+	w.processedCount++
+}
+
+// GetProcessedCount returns the number of stored opportunities
+func (w *ArbitrageWriter) GetProcessedCount() int {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.processedCount
+}
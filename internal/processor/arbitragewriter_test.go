@@ -0,0 +1,37 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArbitrageWriter_ProcessOpportunity(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	writer, err := NewArbitrageWriter(db)
+	assert.NoError(t, err)
+
+	opportunity := models.ArbitrageOpportunity{
+		Path:       []string{"BTC", "USDT", "ETH", "BTC"},
+		Symbols:    []string{"BTCUSDT", "ETHUSDT", "ETHBTC"},
+		StartTime:  1000,
+		EndTime:    4000,
+		DurationMs: 3000,
+		MaxReturn:  0.015,
+		LastReturn: 0.012,
+	}
+
+	mock.ExpectExec(`INSERT INTO arbitrage_opportunities`).
+		WithArgs("BTC>USDT>ETH>BTC", "BTCUSDT,ETHUSDT,ETHBTC", int64(1000), int64(4000), int64(3000), 0.015, 0.012).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	writer.ProcessOpportunity(opportunity)
+
+	assert.Equal(t, 1, writer.GetProcessedCount())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
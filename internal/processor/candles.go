@@ -0,0 +1,327 @@
+package processor
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+// candleIdleGrace is how long a symbol may go without ticks before its event
+// time is advanced by the wall clock, which absorbs network jitter
+const candleIdleGrace = time.Second
+
+// CandleConfig holds the settings for candle aggregation
+type CandleConfig struct {
+	Enabled   bool
+	Intervals []string
+	// AllowedLateness is how far behind the newest event a tick may arrive
+	// and still be folded into its candle
+	AllowedLateness time.Duration `mapstructure:"allowed_lateness"`
+}
+
+// CandleProcessor receives closed candles from a CandleAggregator
+type CandleProcessor interface {
+	ProcessCandle(candle models.Candle)
+}
+
+type candleInterval struct {
+	name     string
+	duration int64
+}
+
+type candleKey struct {
+	interval int
+	openTime int64
+}
+
+type openCandle struct {
+	candle     models.Candle
+	firstEvent int64
+	lastEvent  int64
+}
+
+// CandleAggregator implements DataProcessor interface and builds OHLCV
+// candles per symbol and interval. Ticker volumes and trade counts are 24h
+// rolling totals, so a candle's volume is the sum of their positive deltas.
+// A candle is closed once the symbol's watermark (newest event time minus the
+// allowed lateness) passes its close time; ticks arriving after that are
+// counted as late and dropped. While a symbol is quiet its event time is
+// advanced by the wall clock, so its candles still close on time.
+type CandleAggregator struct {
+	intervals      []candleInterval
+	lateness       int64
+	downstream     []CandleProcessor
+	open           map[string]map[candleKey]*openCandle
+	lastTick       map[string]models.FormattedData
+	maxEventTime   map[string]int64
+	lastSeen       map[string]time.Time
+	now            func() time.Time
+	mutex          sync.Mutex
+	processedCount int
+	lateCount      int
+}
+
+// NewCandleAggregator creates a new CandleAggregator for the given intervals
+// (e.g. "1s", "1m", "5m", "1h")
+func NewCandleAggregator(intervals []string, allowedLateness time.Duration) (*CandleAggregator, error) {
+	if len(intervals) == 0 {
+		return nil, fmt.Errorf("at least one candle interval is required")
+	}
+
+	aggregator := &CandleAggregator{
+		lateness:     allowedLateness.Milliseconds(),
+		open:         make(map[string]map[candleKey]*openCandle),
+		lastTick:     make(map[string]models.FormattedData),
+		maxEventTime: make(map[string]int64),
+		lastSeen:     make(map[string]time.Time),
+		now:          time.Now,
+	}
+
+	for _, name := range intervals {
+		d, err := time.ParseDuration(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid candle interval %q: %w", name, err)
+		}
+		if d < time.Second || d%time.Second != 0 {
+			return nil, fmt.Errorf("candle interval %q must be a whole number of seconds", name)
+		}
+		aggregator.intervals = append(aggregator.intervals, candleInterval{name: name, duration: d.Milliseconds()})
+	}
+
+	return aggregator, nil
+}
+
+// AddProcessor adds a downstream processor for closed candles
+func (a *CandleAggregator) AddProcessor(proc CandleProcessor) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.downstream = append(a.downstream, proc)
+}
+
+// Process implements the DataProcessor interface
+func (a *CandleAggregator) Process(data models.FormattedData) {
+	a.mutex.Lock()
+
+	var volume, quoteVolume float64
+	var trades int
+	prev, seen := a.lastTick[data.Symbol]
+	if !seen || data.EventTime > prev.EventTime {
+		if seen {
+			volume = positiveDelta(data.Volume, prev.Volume)
+			quoteVolume = positiveDelta(data.QuoteVolume, prev.QuoteVolume)
+			if data.TradeCount > prev.TradeCount {
+				trades = data.TradeCount - prev.TradeCount
+			}
+		}
+		a.lastTick[data.Symbol] = data
+	}
+
+	closed := a.observe(data.Symbol, data.EventTime, data.LastPrice, volume, quoteVolume, trades)
+	a.processedCount++
+	downstream := a.downstream
+	a.mutex.Unlock()
+
+	emitCandles(downstream, closed)
+}
+
+// ProcessTrade folds a single trade into the candles, which gives exact
+// volumes and trade counts when a trade stream is available
+func (a *CandleAggregator) ProcessTrade(trade models.Trade) {
+	a.mutex.Lock()
+	closed := a.observe(trade.Symbol, trade.EventTime, trade.Price, trade.Quantity, trade.Price*trade.Quantity, 1)
+	a.processedCount++
+	downstream := a.downstream
+	a.mutex.Unlock()
+
+	emitCandles(downstream, closed)
+}
+
+// observe updates the open candles for an event and returns the candles closed
+// by the advancing watermark. The caller must hold the mutex.
+func (a *CandleAggregator) observe(symbol string, eventTime int64, price, volume, quoteVolume float64, trades int) []models.Candle {
+	if eventTime > a.maxEventTime[symbol] {
+		a.maxEventTime[symbol] = eventTime
+	}
+	a.lastSeen[symbol] = a.now()
+	watermark := a.maxEventTime[symbol] - a.lateness
+
+	open := a.open[symbol]
+	if open == nil {
+		open = make(map[candleKey]*openCandle)
+		a.open[symbol] = open
+	}
+
+	for i, interval := range a.intervals {
+		openTime := eventTime - eventTime%interval.duration
+		if openTime+interval.duration <= watermark {
+			a.lateCount++
+			continue
+		}
+
+		key := candleKey{interval: i, openTime: openTime}
+		oc, ok := open[key]
+		if !ok {
+			oc = &openCandle{
+				candle: models.Candle{
+					Symbol:    symbol,
+					Interval:  interval.name,
+					OpenTime:  openTime,
+					CloseTime: openTime + interval.duration - 1,
+					Open:      price,
+					High:      price,
+					Low:       price,
+					Close:     price,
+				},
+				firstEvent: eventTime,
+				lastEvent:  eventTime,
+			}
+			open[key] = oc
+		}
+
+		if eventTime < oc.firstEvent {
+			oc.firstEvent = eventTime
+			oc.candle.Open = price
+		}
+		if eventTime >= oc.lastEvent {
+			oc.lastEvent = eventTime
+			oc.candle.Close = price
+		}
+		if price > oc.candle.High {
+			oc.candle.High = price
+		}
+		if price < oc.candle.Low {
+			oc.candle.Low = price
+		}
+		oc.candle.Volume += volume
+		oc.candle.QuoteVolume += quoteVolume
+		oc.candle.TradeCount += trades
+	}
+
+	return a.closeBefore(symbol, watermark)
+}
+
+// closeBefore removes and returns the open candles of a symbol that close
+// before watermark, oldest first. The caller must hold the mutex.
+func (a *CandleAggregator) closeBefore(symbol string, watermark int64) []models.Candle {
+	var closed []models.Candle
+	for key, oc := range a.open[symbol] {
+		if oc.candle.CloseTime < watermark {
+			closed = append(closed, oc.candle)
+			delete(a.open[symbol], key)
+		}
+	}
+	sortCandles(closed)
+	return closed
+}
+
+// FlushIdle closes the candles of symbols that have gone quiet. A symbol's
+// event time is assumed to advance with the wall clock since its last tick;
+// ticks that arrive later for a candle closed this way are counted as late.
+func (a *CandleAggregator) FlushIdle(now time.Time) {
+	a.mutex.Lock()
+	var closed []models.Candle
+	for symbol, seen := range a.lastSeen {
+		idle := (now.Sub(seen) - candleIdleGrace).Milliseconds()
+		if idle <= 0 || len(a.open[symbol]) == 0 {
+			continue
+		}
+		a.maxEventTime[symbol] += idle
+		a.lastSeen[symbol] = now.Add(-candleIdleGrace)
+		closed = append(closed, a.closeBefore(symbol, a.maxEventTime[symbol]-a.lateness)...)
+	}
+	downstream := a.downstream
+	a.mutex.Unlock()
+
+	emitCandles(downstream, closed)
+}
+
+// Run flushes idle symbols every second until stop is closed
+func (a *CandleAggregator) Run(stop chan struct{}) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			a.FlushIdle(now)
+		}
+	}
+}
+
+// Close emits every candle still open, marked partial, so that the last
+// candles are not lost at shutdown
+func (a *CandleAggregator) Close() error {
+	a.mutex.Lock()
+	var closed []models.Candle
+	for symbol, open := range a.open {
+		for _, oc := range open {
+			candle := oc.candle
+			candle.Partial = true
+			closed = append(closed, candle)
+		}
+		delete(a.open, symbol)
+	}
+	sortCandles(closed)
+	downstream := a.downstream
+	a.mutex.Unlock()
+
+	emitCandles(downstream, closed)
+	return nil
+}
+
+func sortCandles(candles []models.Candle) {
+	sort.Slice(candles, func(i, j int) bool {
+		if candles[i].OpenTime != candles[j].OpenTime {
+			return candles[i].OpenTime < candles[j].OpenTime
+		}
+		if candles[i].CloseTime != candles[j].CloseTime {
+			return candles[i].CloseTime < candles[j].CloseTime
+		}
+		return candles[i].Symbol < candles[j].Symbol
+	})
+}
+
+// GetProcessedCount returns the number of processed messages
+func (a *CandleAggregator) GetProcessedCount() int {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.processedCount
+}
+
+// GetBufferSize returns the number of candles still open
+func (a *CandleAggregator) GetBufferSize() int {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	size := 0
+	for _, open := range a.open {
+		size += len(open)
+	}
+	return size
+}
+
+// GetLateCount returns the number of (event, interval) pairs dropped as late
+func (a *CandleAggregator) GetLateCount() int {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.lateCount
+}
+
+func emitCandles(downstream []CandleProcessor, candles []models.Candle) {
+	for _, candle := range candles {
+		for _, proc := range downstream {
+			proc.ProcessCandle(candle)
+		}
+	}
+}
+
+func positiveDelta(current, previous float64) float64 {
+	if current > previous {
+		return current - previous
+	}
+	return 0
+}
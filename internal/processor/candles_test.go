@@ -0,0 +1,185 @@
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+type mockCandleProcessor struct {
+	candles []models.Candle
+}
+
+func (m *mockCandleProcessor) ProcessCandle(candle models.Candle) {
+	m.candles = append(m.candles, candle)
+}
+
+func tick(eventTime int64, price, volume float64, trades int) models.FormattedData {
+	return models.FormattedData{
+		EventTime:   eventTime,
+		Symbol:      "BTCUSDT",
+		LastPrice:   price,
+		Volume:      volume,
+		QuoteVolume: volume * price,
+		TradeCount:  trades,
+	}
+}
+
+func TestNewCandleAggregator_InvalidIntervals(t *testing.T) {
+	_, err := NewCandleAggregator(nil, 0)
+	assert.Error(t, err)
+
+	_, err = NewCandleAggregator([]string{"abc"}, 0)
+	assert.Error(t, err)
+
+	_, err = NewCandleAggregator([]string{"500ms"}, 0)
+	assert.Error(t, err)
+}
+
+func TestCandleAggregator_BuildsCandles(t *testing.T) {
+	aggregator, err := NewCandleAggregator([]string{"1m"}, 0)
+	require.NoError(t, err)
+	sink := &mockCandleProcessor{}
+	aggregator.AddProcessor(sink)
+
+	aggregator.Process(tick(60_000, 100, 1000, 10))
+	aggregator.Process(tick(70_000, 105, 1002, 12))
+	aggregator.Process(tick(80_000, 95, 1005, 15))
+	aggregator.Process(tick(110_000, 101, 1006, 16))
+	assert.Empty(t, sink.candles, "candle should stay open until the next interval starts")
+	assert.Equal(t, 1, aggregator.GetBufferSize())
+
+	aggregator.Process(tick(120_000, 102, 1010, 20))
+	require.Len(t, sink.candles, 1)
+
+	candle := sink.candles[0]
+	assert.Equal(t, "BTCUSDT", candle.Symbol)
+	assert.Equal(t, "1m", candle.Interval)
+	assert.Equal(t, int64(60_000), candle.OpenTime)
+	assert.Equal(t, int64(119_999), candle.CloseTime)
+	assert.Equal(t, 100.0, candle.Open)
+	assert.Equal(t, 105.0, candle.High)
+	assert.Equal(t, 95.0, candle.Low)
+	assert.Equal(t, 101.0, candle.Close)
+	assert.InDelta(t, 6.0, candle.Volume, 1e-9)
+	assert.Equal(t, 6, candle.TradeCount)
+	assert.Equal(t, 5, aggregator.GetProcessedCount())
+}
+
+func TestCandleAggregator_MultipleIntervals(t *testing.T) {
+	aggregator, err := NewCandleAggregator([]string{"1s", "1m"}, 0)
+	require.NoError(t, err)
+	sink := &mockCandleProcessor{}
+	aggregator.AddProcessor(sink)
+
+	for ts := int64(0); ts <= 60_000; ts += 1000 {
+		aggregator.Process(tick(ts, float64(ts), 0, 0))
+	}
+
+	var seconds, minutes int
+	for _, candle := range sink.candles {
+		switch candle.Interval {
+		case "1s":
+			seconds++
+		case "1m":
+			minutes++
+		}
+	}
+	assert.Equal(t, 60, seconds)
+	assert.Equal(t, 1, minutes)
+}
+
+func TestCandleAggregator_Watermark(t *testing.T) {
+	aggregator, err := NewCandleAggregator([]string{"1m"}, 10*time.Second)
+	require.NoError(t, err)
+	sink := &mockCandleProcessor{}
+	aggregator.AddProcessor(sink)
+
+	aggregator.Process(tick(100_000, 100, 0, 0))
+	aggregator.Process(tick(125_000, 110, 0, 0))
+	assert.Empty(t, sink.candles, "candle should wait for late events")
+
+	// Late but within the allowed lateness, so it still lands in the first candle
+	aggregator.Process(tick(61_000, 90, 0, 0))
+	aggregator.Process(tick(131_000, 111, 0, 0))
+	require.Len(t, sink.candles, 1)
+	assert.Equal(t, 90.0, sink.candles[0].Open)
+	assert.Equal(t, 90.0, sink.candles[0].Low)
+	assert.Equal(t, 100.0, sink.candles[0].Close)
+
+	// Too late: the first candle has already been emitted
+	aggregator.Process(tick(62_000, 50, 0, 0))
+	assert.Len(t, sink.candles, 1)
+	assert.Equal(t, 1, aggregator.GetLateCount())
+}
+
+func TestCandleAggregator_ProcessTrade(t *testing.T) {
+	aggregator, err := NewCandleAggregator([]string{"1s"}, 0)
+	require.NoError(t, err)
+	sink := &mockCandleProcessor{}
+	aggregator.AddProcessor(sink)
+
+	aggregator.ProcessTrade(models.Trade{EventTime: 1000, Symbol: "ETHUSDT", Price: 10, Quantity: 2})
+	aggregator.ProcessTrade(models.Trade{EventTime: 1500, Symbol: "ETHUSDT", Price: 12, Quantity: 1})
+	aggregator.ProcessTrade(models.Trade{EventTime: 2000, Symbol: "ETHUSDT", Price: 11, Quantity: 1})
+
+	require.Len(t, sink.candles, 1)
+	assert.Equal(t, 3.0, sink.candles[0].Volume)
+	assert.Equal(t, 32.0, sink.candles[0].QuoteVolume)
+	assert.Equal(t, 2, sink.candles[0].TradeCount)
+}
+
+func TestCandleAggregator_FlushIdle(t *testing.T) {
+	aggregator, err := NewCandleAggregator([]string{"1m"}, 0)
+	require.NoError(t, err)
+	now := time.Unix(1000, 0)
+	aggregator.now = func() time.Time { return now }
+	downstream := &mockCandleProcessor{}
+	aggregator.AddProcessor(downstream)
+
+	aggregator.Process(tick(30000, 100, 10, 1))
+	aggregator.Process(tick(40000, 101, 11, 2))
+
+	// Within the minute the candle stays open
+	aggregator.FlushIdle(now.Add(20 * time.Second))
+	assert.Empty(t, downstream.candles)
+
+	// Once the symbol's event time would have passed the close, it is emitted
+	aggregator.FlushIdle(now.Add(22 * time.Second))
+	require.Len(t, downstream.candles, 1)
+	assert.Equal(t, int64(0), downstream.candles[0].OpenTime)
+	assert.Equal(t, 101.0, downstream.candles[0].Close)
+	assert.Equal(t, 0, aggregator.GetBufferSize())
+
+	// A tick for the flushed candle is late rather than reopening it
+	aggregator.Process(tick(59000, 102, 12, 3))
+	assert.Equal(t, 1, aggregator.GetLateCount())
+	assert.Len(t, downstream.candles, 1)
+}
+
+func TestCandleAggregator_Close(t *testing.T) {
+	aggregator, err := NewCandleAggregator([]string{"1m", "5m"}, 0)
+	require.NoError(t, err)
+	downstream := &mockCandleProcessor{}
+	aggregator.AddProcessor(downstream)
+
+	aggregator.Process(tick(30000, 100, 10, 1))
+	eth := tick(30000, 3000, 5, 1)
+	eth.Symbol = "ETHUSDT"
+	aggregator.Process(eth)
+	assert.Equal(t, 4, aggregator.GetBufferSize())
+
+	require.NoError(t, aggregator.Close())
+	require.Len(t, downstream.candles, 4)
+	assert.Equal(t, "1m", downstream.candles[0].Interval)
+	assert.Equal(t, "BTCUSDT", downstream.candles[0].Symbol)
+	assert.Equal(t, "ETHUSDT", downstream.candles[1].Symbol)
+	for _, candle := range downstream.candles {
+		assert.True(t, candle.Partial)
+	}
+	assert.Equal(t, 0, aggregator.GetBufferSize())
+}
@@ -0,0 +1,64 @@
+package processor
+
+import (
+	"database/sql"
+	"log"
+	"sync"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+// CandleWriter implements CandleProcessor interface for PostgreSQL
+type CandleWriter struct {
+	db             *sql.DB
+	mutex          sync.Mutex
+	processedCount int
+}
+
+// NewCandleWriter creates a new CandleWriter
+func NewCandleWriter(db *sql.DB) (*CandleWriter, error) {
+	writer := &CandleWriter{
+		db: db,
+	}
+
+	return writer, nil
+}
+
+// ProcessCandle implements the CandleProcessor interface. Re-emitted candles
+// overwrite the stored row, except a partial one stored at shutdown: the
+// candle for the rest of its interval is merged into it, keeping the
+// earlier open and adding the volumes.
+func (w *CandleWriter) ProcessCandle(candle models.Candle) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	_, err := w.db.Exec(`INSERT INTO candles (
+        symbol, interval, open_time, close_time, open, high, low, close, volume, quote_volume, trade_count, partial
+    ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+    ON CONFLICT (symbol, interval, open_time) DO UPDATE SET
+        close_time = EXCLUDED.close_time,
+        open = CASE WHEN candles.partial THEN candles.open ELSE EXCLUDED.open END,
+        high = CASE WHEN candles.partial THEN GREATEST(candles.high, EXCLUDED.high) ELSE EXCLUDED.high END,
+        low = CASE WHEN candles.partial THEN LEAST(candles.low, EXCLUDED.low) ELSE EXCLUDED.low END,
+        close = EXCLUDED.close,
+        volume = CASE WHEN candles.partial THEN candles.volume + EXCLUDED.volume ELSE EXCLUDED.volume END,
+        quote_volume = CASE WHEN candles.partial THEN candles.quote_volume + EXCLUDED.quote_volume ELSE EXCLUDED.quote_volume END,
+        trade_count = CASE WHEN candles.partial THEN candles.trade_count + EXCLUDED.trade_count ELSE EXCLUDED.trade_count END,
+        partial = EXCLUDED.partial`,
+		candle.Symbol, candle.Interval, candle.OpenTime, candle.CloseTime, candle.Open, candle.High, candle.Low,
+		candle.Close, candle.Volume, candle.QuoteVolume, candle.TradeCount, candle.Partial,
+	)
+	if err != nil {
+		log.Printf("Error inserting candle: %v", err)
+		return
+	}
+
+	w.processedCount++
+}
+
+// GetProcessedCount returns the number of stored candles
+func (w *CandleWriter) GetProcessedCount() int {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.processedCount
+}
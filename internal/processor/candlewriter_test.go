@@ -0,0 +1,86 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCandleWriter_ProcessCandle(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	writer, err := NewCandleWriter(db)
+	assert.NoError(t, err)
+
+	candle := models.Candle{
+		Symbol:      "BTCUSDT",
+		Interval:    "1m",
+		OpenTime:    60000,
+		CloseTime:   119999,
+		Open:        100,
+		High:        105,
+		Low:         95,
+		Close:       101,
+		Volume:      6,
+		QuoteVolume: 600,
+		TradeCount:  6,
+	}
+
+	mock.ExpectExec(`INSERT INTO candles`).
+		WithArgs(
+			candle.Symbol, candle.Interval, candle.OpenTime, candle.CloseTime, candle.Open, candle.High, candle.Low,
+			candle.Close, candle.Volume, candle.QuoteVolume, candle.TradeCount, false,
+		).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	writer.ProcessCandle(candle)
+
+	assert.Equal(t, 1, writer.GetProcessedCount())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCandleWriter_MergesIntoPartialCandle(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	writer, err := NewCandleWriter(db)
+	assert.NoError(t, err)
+
+	// The partial candle stored at shutdown is kept open for the candle of
+	// the rest of its interval after a restart
+	mock.ExpectExec(`ON CONFLICT \(symbol, interval, open_time\) DO UPDATE SET.*`+
+		`open = CASE WHEN candles.partial THEN candles.open ELSE EXCLUDED.open END.*`+
+		`high = CASE WHEN candles.partial THEN GREATEST\(candles.high, EXCLUDED.high\).*`+
+		`low = CASE WHEN candles.partial THEN LEAST\(candles.low, EXCLUDED.low\).*`+
+		`volume = CASE WHEN candles.partial THEN candles.volume \+ EXCLUDED.volume.*`+
+		`partial = EXCLUDED.partial`).
+		WithArgs("BTCUSDT", "1m", int64(60000), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), true).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	writer.ProcessCandle(models.Candle{Symbol: "BTCUSDT", Interval: "1m", OpenTime: 60000, Partial: true})
+
+	assert.Equal(t, 1, writer.GetProcessedCount())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCandleWriter_ProcessCandleError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	writer, err := NewCandleWriter(db)
+	assert.NoError(t, err)
+
+	mock.ExpectExec(`INSERT INTO candles`).WillReturnError(assert.AnError)
+
+	writer.ProcessCandle(models.Candle{Symbol: "BTCUSDT", Interval: "1m"})
+
+	assert.Equal(t, 0, writer.GetProcessedCount())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
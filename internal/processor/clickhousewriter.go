@@ -0,0 +1,306 @@
+package processor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+const (
+	defaultClickHouseURL       = "http://localhost:8123"
+	defaultClickHouseDatabase  = "default"
+	defaultClickHouseTable     = "ticker_data"
+	defaultClickHouseBatchSize = 1000
+	defaultClickHouseFlush     = time.Second
+	defaultClickHouseTimeout   = 10 * time.Second
+)
+
+// ClickHouseConfig holds the settings for storing ticks in ClickHouse over
+// its HTTP interface
+type ClickHouseConfig struct {
+	URL      string
+	Database string
+	Table    string
+	Username string
+	Password string
+	// BatchSize is how many ticks are buffered before they are inserted
+	BatchSize int `mapstructure:"batch_size"`
+	// FlushInterval is the longest a tick stays buffered
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+}
+
+// The ReplacingMergeTree collapses ticks with the same (symbol, event_time)
+// that slipped past the writer's cache when parts are merged
+const clickHouseSchema = `CREATE TABLE IF NOT EXISTS %s
+(
+    event_time   Int64,
+    symbol       LowCardinality(String),
+    last_price   Float64,
+    price_change Float64,
+    high_price   Float64,
+    low_price    Float64,
+    volume       Float64,
+    quote_volume Float64,
+    open_time    Int64,
+    close_time   Int64,
+    trade_count  Int64,
+    latency      Int64,
+    inserted_at  DateTime DEFAULT now()
+)
+ENGINE = ReplacingMergeTree
+PARTITION BY toYYYYMMDD(fromUnixTimestamp64Milli(event_time))
+ORDER BY (symbol, event_time)`
+
+// clickHouseRow is a ticker_data row in JSONEachRow format
+type clickHouseRow struct {
+	EventTime   int64   `json:"event_time"`
+	Symbol      string  `json:"symbol"`
+	LastPrice   float64 `json:"last_price"`
+	PriceChange float64 `json:"price_change"`
+	HighPrice   float64 `json:"high_price"`
+	LowPrice    float64 `json:"low_price"`
+	Volume      float64 `json:"volume"`
+	QuoteVolume float64 `json:"quote_volume"`
+	OpenTime    int64   `json:"open_time"`
+	CloseTime   int64   `json:"close_time"`
+	TradeCount  int     `json:"trade_count"`
+	Latency     int64   `json:"latency"`
+}
+
+// ClickHouseWriter implements DataProcessor interface for ClickHouse. Ticks
+// are buffered and inserted in batches when the buffer is full or the flush
+// interval elapses; a failed batch is retried like a PGWriter insert. Keys
+// are cached from when a tick is buffered so that duplicates of pending
+// ticks are skipped, and forgotten if their batch fails.
+type ClickHouseWriter struct {
+	client         *http.Client
+	cfg            ClickHouseConfig
+	table          string
+	maxAttempts    int
+	retryDelay     time.Duration
+	buffer         []clickHouseRow
+	recent         *recentKeys
+	mutex          sync.Mutex
+	flushMutex     sync.Mutex
+	processedCount int
+	failedCount    int
+	duplicateCount int
+	stop           chan struct{}
+	done           chan struct{}
+	closeOnce      sync.Once
+}
+
+// NewClickHouseWriter creates a new ClickHouseWriter, creating the table if
+// it does not exist, and starts flushing it periodically
+func NewClickHouseWriter(client *http.Client, cfg ClickHouseConfig) (*ClickHouseWriter, error) {
+	if cfg.URL == "" {
+		cfg.URL = defaultClickHouseURL
+	}
+	if cfg.Database == "" {
+		cfg.Database = defaultClickHouseDatabase
+	}
+	if cfg.Table == "" {
+		cfg.Table = defaultClickHouseTable
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultClickHouseBatchSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = defaultClickHouseFlush
+	}
+
+	writer := &ClickHouseWriter{
+		client:      client,
+		cfg:         cfg,
+		table:       cfg.Database + "." + cfg.Table,
+		maxAttempts: defaultMaxAttempts,
+		retryDelay:  defaultRetryDelay,
+		buffer:      make([]clickHouseRow, 0, cfg.BatchSize),
+		recent:      newRecentKeys(defaultDedupeCapacity),
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+
+	if err := writer.exec(fmt.Sprintf(clickHouseSchema, writer.table), nil); err != nil {
+		return nil, fmt.Errorf("error creating ClickHouse table %s: %w", writer.table, err)
+	}
+
+	go writer.run()
+	return writer, nil
+}
+
+// SetRetryPolicy sets how many times a batch is attempted and the delay
+// before each retry (multiplied by the attempt number)
+func (w *ClickHouseWriter) SetRetryPolicy(maxAttempts int, retryDelay time.Duration) {
+	w.flushMutex.Lock()
+	defer w.flushMutex.Unlock()
+	w.maxAttempts = maxAttempts
+	w.retryDelay = retryDelay
+}
+
+// Process implements the DataProcessor interface
+func (w *ClickHouseWriter) Process(data models.FormattedData) {
+	w.mutex.Lock()
+	key := tickKey{symbol: data.Symbol, eventTime: data.EventTime}
+	if w.recent.contains(key) {
+		w.duplicateCount++
+		w.mutex.Unlock()
+		return
+	}
+	w.recent.add(key)
+
+	w.buffer = append(w.buffer, clickHouseRow{
+		EventTime:   data.EventTime,
+		Symbol:      data.Symbol,
+		LastPrice:   data.LastPrice,
+		PriceChange: data.PriceChange,
+		HighPrice:   data.HighPrice,
+		LowPrice:    data.LowPrice,
+		Volume:      data.Volume,
+		QuoteVolume: data.QuoteVolume,
+		OpenTime:    data.OpenTime,
+		CloseTime:   data.CloseTime,
+		TradeCount:  data.TradeCount,
+		Latency:     data.Latency,
+	})
+	full := len(w.buffer) >= w.cfg.BatchSize
+	w.mutex.Unlock()
+
+	if full {
+		w.Flush()
+	}
+}
+
+// Flush inserts the buffered ticks
+func (w *ClickHouseWriter) Flush() {
+	w.flushMutex.Lock()
+	defer w.flushMutex.Unlock()
+
+	w.mutex.Lock()
+	rows := w.buffer
+	w.buffer = make([]clickHouseRow, 0, w.cfg.BatchSize)
+	w.mutex.Unlock()
+
+	if len(rows) == 0 {
+		return
+	}
+
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, row := range rows {
+		if err := encoder.Encode(row); err != nil {
+			log.Printf("Error encoding ClickHouse row: %v", err)
+		}
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s FORMAT JSONEachRow", w.table)
+	for attempt := 1; attempt <= w.maxAttempts; attempt++ {
+		err := w.exec(query, body.Bytes())
+		if err == nil {
+			w.mutex.Lock()
+			w.processedCount += len(rows)
+			w.mutex.Unlock()
+			return
+		}
+		log.Printf("Error inserting %d rows into ClickHouse (attempt %d/%d): %v", len(rows), attempt, w.maxAttempts, err)
+		if attempt < w.maxAttempts {
+			time.Sleep(w.retryDelay * time.Duration(attempt))
+		}
+	}
+
+	w.mutex.Lock()
+	w.failedCount += len(rows)
+	for _, row := range rows {
+		w.recent.remove(tickKey{symbol: row.Symbol, eventTime: row.EventTime})
+	}
+	w.mutex.Unlock()
+}
+
+// exec runs a statement over the HTTP interface with data as its input
+func (w *ClickHouseWriter) exec(query string, data []byte) error {
+	params := url.Values{"query": {query}}
+	req, err := http.NewRequest(http.MethodPost, w.cfg.URL+"/?"+params.Encode(), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	if w.cfg.Username != "" {
+		req.Header.Set("X-ClickHouse-User", w.cfg.Username)
+		req.Header.Set("X-ClickHouse-Key", w.cfg.Password)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status %s: %s", resp.Status, bytes.TrimSpace(message))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+func (w *ClickHouseWriter) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.Flush()
+		}
+	}
+}
+
+// GetDuplicateCount returns the number of ticks skipped because they were
+// recently stored
+func (w *ClickHouseWriter) GetDuplicateCount() int {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.duplicateCount
+}
+
+// GetFailedCount returns the number of ticks that could not be stored
+func (w *ClickHouseWriter) GetFailedCount() int {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.failedCount
+}
+
+// GetProcessedCount returns the number of inserted ticks
+func (w *ClickHouseWriter) GetProcessedCount() int {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.processedCount
+}
+
+// GetBufferSize returns the number of ticks waiting to be inserted
+func (w *ClickHouseWriter) GetBufferSize() int {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return len(w.buffer)
+}
+
+// Close stops the periodic flush and inserts the remaining ticks
+func (w *ClickHouseWriter) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.stop)
+		<-w.done
+		w.Flush()
+	})
+	return nil
+}
@@ -0,0 +1,153 @@
+package processor
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clickHouseServer records the statements posted to a fake HTTP interface
+type clickHouseServer struct {
+	*httptest.Server
+	mutex    sync.Mutex
+	queries  []string
+	rows     []clickHouseRow
+	failNext int
+	user     string
+}
+
+func newClickHouseServer(t *testing.T) *clickHouseServer {
+	s := &clickHouseServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		s.user = r.Header.Get("X-ClickHouse-User")
+		if s.failNext > 0 {
+			s.failNext--
+			http.Error(w, "Code: 252. DB::Exception: Too many parts", http.StatusInternalServerError)
+			return
+		}
+
+		query := r.URL.Query().Get("query")
+		s.queries = append(s.queries, query)
+		if strings.HasPrefix(query, "INSERT") {
+			scanner := bufio.NewScanner(r.Body)
+			for scanner.Scan() {
+				var row clickHouseRow
+				assert.NoError(t, json.Unmarshal(scanner.Bytes(), &row))
+				s.rows = append(s.rows, row)
+			}
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *clickHouseServer) stored() ([]string, []clickHouseRow) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]string(nil), s.queries...), append([]clickHouseRow(nil), s.rows...)
+}
+
+func TestClickHouseWriter_BatchesInserts(t *testing.T) {
+	server := newClickHouseServer(t)
+	writer, err := NewClickHouseWriter(server.Client(), ClickHouseConfig{
+		URL:           server.URL,
+		Database:      "market",
+		Username:      "monitor",
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+	})
+	require.NoError(t, err)
+
+	writer.Process(tick(1000, 34000, 10, 5))
+	assert.Equal(t, 1, writer.GetBufferSize())
+	writer.Process(tick(2000, 34100, 12, 6))
+	assert.Zero(t, writer.GetBufferSize())
+
+	queries, rows := server.stored()
+	require.Len(t, queries, 2)
+	assert.Contains(t, queries[0], "CREATE TABLE IF NOT EXISTS market.ticker_data")
+	assert.Contains(t, queries[0], "ENGINE = ReplacingMergeTree")
+	assert.Equal(t, "INSERT INTO market.ticker_data FORMAT JSONEachRow", queries[1])
+	require.Len(t, rows, 2)
+	assert.Equal(t, int64(2000), rows[1].EventTime)
+	assert.Equal(t, 34100.0, rows[1].LastPrice)
+	assert.Equal(t, "monitor", server.user)
+	assert.Equal(t, 2, writer.GetProcessedCount())
+
+	// Close flushes what is left
+	writer.Process(tick(3000, 34200, 12, 6))
+	require.NoError(t, writer.Close())
+	_, rows = server.stored()
+	assert.Len(t, rows, 3)
+	assert.Equal(t, 3, writer.GetProcessedCount())
+}
+
+func TestClickHouseWriter_FlushInterval(t *testing.T) {
+	server := newClickHouseServer(t)
+	writer, err := NewClickHouseWriter(server.Client(), ClickHouseConfig{
+		URL:           server.URL,
+		FlushInterval: 20 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	defer writer.Close()
+
+	writer.Process(tick(1000, 34000, 10, 5))
+	assert.Eventually(t, func() bool {
+		return writer.GetProcessedCount() == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Zero(t, writer.GetBufferSize())
+}
+
+func TestClickHouseWriter_RetriesAndDeduplicates(t *testing.T) {
+	server := newClickHouseServer(t)
+	writer, err := NewClickHouseWriter(server.Client(), ClickHouseConfig{URL: server.URL, FlushInterval: time.Hour})
+	require.NoError(t, err)
+	writer.SetRetryPolicy(3, time.Millisecond)
+
+	writer.Process(tick(1000, 34000, 10, 5))
+	writer.Process(tick(1000, 34000, 10, 5))
+	assert.Equal(t, 1, writer.GetDuplicateCount())
+
+	server.mutex.Lock()
+	server.failNext = 2
+	server.mutex.Unlock()
+	writer.Flush()
+	assert.Equal(t, 1, writer.GetProcessedCount())
+	assert.Zero(t, writer.GetFailedCount())
+
+	writer.Process(tick(2000, 34000, 10, 5))
+	server.mutex.Lock()
+	server.failNext = 3
+	server.mutex.Unlock()
+	writer.Flush()
+	assert.Equal(t, 1, writer.GetFailedCount())
+
+	// A failed tick is stored when it is delivered again
+	writer.Process(tick(2000, 34000, 10, 5))
+	assert.Equal(t, 1, writer.GetDuplicateCount())
+	require.NoError(t, writer.Close())
+	assert.Equal(t, 2, writer.GetProcessedCount())
+	_, rows := server.stored()
+	require.Len(t, rows, 2)
+	assert.Equal(t, int64(2000), rows[1].EventTime)
+}
+
+func TestNewClickHouseWriter_Error(t *testing.T) {
+	server := newClickHouseServer(t)
+	server.failNext = 1
+
+	_, err := NewClickHouseWriter(server.Client(), ClickHouseConfig{URL: server.URL})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Too many parts")
+}
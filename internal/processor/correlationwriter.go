@@ -0,0 +1,80 @@
+package processor
+
+import (
+	"database/sql"
+	"log"
+	"sync"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+// CorrelationWriter persists correlation snapshots to PostgreSQL. Each pair is
+// stored once; pairs involving the benchmark also carry the symbol's beta.
+type CorrelationWriter struct {
+	db             *sql.DB
+	mutex          sync.Mutex
+	processedCount int
+}
+
+// NewCorrelationWriter creates a new CorrelationWriter
+func NewCorrelationWriter(db *sql.DB) (*CorrelationWriter, error) {
+	writer := &CorrelationWriter{
+		db: db,
+	}
+
+	return writer, nil
+}
+
+// ProcessCorrelations stores a correlation snapshot in a single transaction
+func (w *CorrelationWriter) ProcessCorrelations(matrix models.CorrelationMatrix) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	tx, err := w.db.Begin()
+	if err != nil {
+		log.Printf("Error starting correlation snapshot transaction: %v", err)
+		return
+	}
+
+	for i, a := range matrix.Symbols {
+		for _, b := range matrix.Symbols[i+1:] {
+			corr, ok := matrix.Correlations[a][b]
+			if !ok {
+				continue
+			}
+			symbol, other := a, b
+			if symbol == matrix.Benchmark {
+				symbol, other = other, symbol
+			}
+			var beta sql.NullFloat64
+			if other == matrix.Benchmark {
+				beta.Float64, beta.Valid = matrix.Betas[symbol]
+			}
+
+			_, err := tx.Exec(`INSERT INTO correlation_snapshots (
+            snapshot_time, window_name, symbol, other_symbol, correlation, beta
+        ) VALUES ($1, $2, $3, $4, $5, $6)`,
+				matrix.SnapshotTime, matrix.Window, symbol, other, corr, beta,
+			)
+			if err != nil {
+				log.Printf("Error inserting correlation snapshot: %v", err)
+				_ = tx.Rollback()
+				return
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing correlation snapshot: %v", err)
+		return
+	}
+
+	w.processedCount++
+}
+
+// GetProcessedCount returns the number of stored snapshots
+func (w *CorrelationWriter) GetProcessedCount() int {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.processedCount
+}
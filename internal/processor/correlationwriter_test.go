@@ -0,0 +1,46 @@
+package processor
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCorrelationWriter_ProcessCorrelations(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	writer, err := NewCorrelationWriter(db)
+	assert.NoError(t, err)
+
+	matrix := models.CorrelationMatrix{
+		Window:       "1h",
+		SnapshotTime: 60000,
+		Benchmark:    "BTCUSDT",
+		Symbols:      []string{"BTCUSDT", "ETHUSDT", "LTCUSDT"},
+		Correlations: map[string]map[string]float64{
+			"BTCUSDT": {"ETHUSDT": 0.8},
+			"ETHUSDT": {"BTCUSDT": 0.8, "LTCUSDT": 0.5},
+			"LTCUSDT": {"ETHUSDT": 0.5},
+		},
+		Betas: map[string]float64{"ETHUSDT": 1.2},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO correlation_snapshots`).
+		WithArgs(int64(60000), "1h", "ETHUSDT", "BTCUSDT", 0.8, sql.NullFloat64{Float64: 1.2, Valid: true}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`INSERT INTO correlation_snapshots`).
+		WithArgs(int64(60000), "1h", "ETHUSDT", "LTCUSDT", 0.5, sql.NullFloat64{}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	writer.ProcessCorrelations(matrix)
+
+	assert.Equal(t, 1, writer.GetProcessedCount())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,173 @@
+package processor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+const (
+	defaultGraphitePrefix = "binance.ticker"
+	graphiteDialTimeout   = 5 * time.Second
+	graphiteWriteTimeout  = 10 * time.Second
+)
+
+// GraphiteConfig holds the settings for sending ticks to Graphite (carbon)
+// in the plaintext protocol
+type GraphiteConfig struct {
+	Addr string
+	// Protocol is tcp (the default) or udp
+	Protocol    string
+	Prefix      string
+	BatchConfig `mapstructure:",squash"`
+}
+
+// GraphiteWriter implements DataProcessor interface for Graphite. Every
+// field of a tick becomes a metric named <prefix>.<symbol>.<field> at the
+// event time in seconds.
+type GraphiteWriter struct {
+	cfg       GraphiteConfig
+	conn      net.Conn
+	connMutex sync.Mutex
+	batcher   *lineBatcher
+}
+
+// NewGraphiteWriter creates a new GraphiteWriter and starts flushing it
+// periodically. TCP connections are opened on the first send and reopened
+// after errors.
+func NewGraphiteWriter(cfg GraphiteConfig) (*GraphiteWriter, error) {
+	if cfg.Addr == "" {
+		return nil, errors.New("graphite needs an addr")
+	}
+	if cfg.Protocol == "" {
+		cfg.Protocol = "tcp"
+	}
+	if cfg.Protocol != "tcp" && cfg.Protocol != "udp" {
+		return nil, fmt.Errorf("unknown graphite protocol %q", cfg.Protocol)
+	}
+	if cfg.Prefix == "" {
+		cfg.Prefix = defaultGraphitePrefix
+	}
+
+	writer := &GraphiteWriter{cfg: cfg}
+	writer.batcher = newLineBatcher("Graphite", cfg.BatchConfig, writer.send)
+	return writer, nil
+}
+
+// Process implements the DataProcessor interface
+func (w *GraphiteWriter) Process(data models.FormattedData) {
+	w.batcher.add(graphiteLines(w.cfg.Prefix, data))
+}
+
+// Flush sends the buffered metrics
+func (w *GraphiteWriter) Flush() {
+	w.batcher.flush()
+}
+
+func (w *GraphiteWriter) send(payload []byte) (bool, error) {
+	w.connMutex.Lock()
+	defer w.connMutex.Unlock()
+
+	if w.conn == nil {
+		conn, err := net.DialTimeout(w.cfg.Protocol, w.cfg.Addr, graphiteDialTimeout)
+		if err != nil {
+			return true, err
+		}
+		w.conn = conn
+	}
+
+	// A stalled carbon server must not hold the connection forever
+	err := w.conn.SetWriteDeadline(time.Now().Add(graphiteWriteTimeout))
+	if err != nil {
+		w.conn.Close()
+		w.conn = nil
+		return true, err
+	}
+	if w.cfg.Protocol == "udp" {
+		err = writeDatagrams(w.conn, payload)
+	} else {
+		_, err = w.conn.Write(payload)
+	}
+	if err != nil {
+		// Reconnect on the next attempt
+		w.conn.Close()
+		w.conn = nil
+		return true, err
+	}
+	return false, nil
+}
+
+// graphiteLines encodes a tick as one plaintext line per field
+func graphiteLines(prefix string, data models.FormattedData) []byte {
+	var lines bytes.Buffer
+	path := prefix + "." + graphiteEscaper.Replace(strings.ToLower(data.Symbol)) + "."
+	timestamp := strconv.FormatInt(data.EventTime/1000, 10)
+
+	write := func(name, value string) {
+		lines.WriteString(path)
+		lines.WriteString(name)
+		lines.WriteByte(' ')
+		lines.WriteString(value)
+		lines.WriteByte(' ')
+		lines.WriteString(timestamp)
+		lines.WriteByte('\n')
+	}
+	writeFloat := func(name string, value float64) {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return
+		}
+		write(name, strconv.FormatFloat(value, 'f', -1, 64))
+	}
+
+	writeFloat("last_price", data.LastPrice)
+	writeFloat("bid_price", data.BidPrice)
+	writeFloat("ask_price", data.AskPrice)
+	writeFloat("price_change", data.PriceChange)
+	writeFloat("high_price", data.HighPrice)
+	writeFloat("low_price", data.LowPrice)
+	writeFloat("volume", data.Volume)
+	writeFloat("quote_volume", data.QuoteVolume)
+	write("trade_count", strconv.Itoa(data.TradeCount))
+	write("latency", strconv.FormatInt(data.Latency, 10))
+	return lines.Bytes()
+}
+
+// graphiteEscaper keeps symbols a single path segment
+var graphiteEscaper = strings.NewReplacer(".", "_", " ", "_")
+
+// GetFailedCount returns the number of ticks dropped after failed sends
+func (w *GraphiteWriter) GetFailedCount() int {
+	return w.batcher.failed()
+}
+
+// GetProcessedCount returns the number of ticks sent
+func (w *GraphiteWriter) GetProcessedCount() int {
+	return w.batcher.processed()
+}
+
+// GetBufferSize returns the number of ticks waiting to be sent
+func (w *GraphiteWriter) GetBufferSize() int {
+	return w.batcher.size()
+}
+
+// Close sends the remaining metrics and closes the connection
+func (w *GraphiteWriter) Close() error {
+	w.batcher.close()
+
+	w.connMutex.Lock()
+	defer w.connMutex.Unlock()
+	if w.conn != nil {
+		err := w.conn.Close()
+		w.conn = nil
+		return err
+	}
+	return nil
+}
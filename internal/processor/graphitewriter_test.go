@@ -0,0 +1,119 @@
+package processor
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraphiteLines(t *testing.T) {
+	data := tick(1625097600500, 34000.5, 10, 5)
+	data.Symbol = "BTC.USDT"
+
+	lines := strings.Split(strings.TrimSpace(string(graphiteLines("binance", data))), "\n")
+	assert.Contains(t, lines, "binance.btc_usdt.last_price 34000.5 1625097600")
+	assert.Contains(t, lines, "binance.btc_usdt.volume 10 1625097600")
+	assert.Contains(t, lines, "binance.btc_usdt.trade_count 5 1625097600")
+	assert.Len(t, lines, 10)
+}
+
+func TestGraphiteWriter_TCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	received := make(chan string, 100)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					received <- scanner.Text()
+				}
+			}()
+		}
+	}()
+
+	writer, err := NewGraphiteWriter(GraphiteConfig{
+		Addr:        listener.Addr().String(),
+		BatchConfig: BatchConfig{BatchSize: 2, FlushInterval: time.Hour},
+	})
+	require.NoError(t, err)
+
+	writer.Process(tick(1000, 34000, 10, 5))
+	writer.Process(tick(2000, 34100, 12, 6))
+	assert.Eventually(t, func() bool { return writer.GetProcessedCount() == 2 }, time.Second, 5*time.Millisecond)
+	require.NoError(t, writer.Close())
+
+	var lines []string
+	for len(lines) < 20 {
+		select {
+		case line := <-received:
+			lines = append(lines, line)
+		case <-time.After(time.Second):
+			t.Fatalf("received %d of 20 lines", len(lines))
+		}
+	}
+	assert.Equal(t, "binance.ticker.btcusdt.last_price 34000 1", lines[0])
+	assert.Contains(t, lines, "binance.ticker.btcusdt.last_price 34100 2")
+}
+
+func TestGraphiteWriter_UDP(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	writer, err := NewGraphiteWriter(GraphiteConfig{
+		Addr:        listener.LocalAddr().String(),
+		Protocol:    "udp",
+		Prefix:      "crypto",
+		BatchConfig: BatchConfig{FlushInterval: 10 * time.Millisecond},
+	})
+	require.NoError(t, err)
+	defer writer.Close()
+
+	writer.Process(tick(1000, 34000, 10, 5))
+
+	buf := make([]byte, 65536)
+	require.NoError(t, listener.SetReadDeadline(time.Now().Add(time.Second)))
+	n, _, err := listener.ReadFrom(buf)
+	require.NoError(t, err)
+	assert.Equal(t, string(graphiteLines("crypto", tick(1000, 34000, 10, 5))), string(buf[:n]))
+}
+
+func TestGraphiteWriter_Unreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+
+	writer, err := NewGraphiteWriter(GraphiteConfig{
+		Addr:        addr,
+		BatchConfig: BatchConfig{FlushInterval: time.Hour, MaxAttempts: 2, RetryDelay: time.Millisecond},
+	})
+	require.NoError(t, err)
+
+	writer.Process(tick(1000, 34000, 10, 5))
+	writer.Flush()
+	assert.Equal(t, 1, writer.GetFailedCount())
+	assert.Zero(t, writer.GetProcessedCount())
+	assert.NoError(t, writer.Close())
+}
+
+func TestNewGraphiteWriter_Errors(t *testing.T) {
+	_, err := NewGraphiteWriter(GraphiteConfig{})
+	assert.Error(t, err)
+
+	_, err = NewGraphiteWriter(GraphiteConfig{Addr: "localhost:2003", Protocol: "http"})
+	assert.EqualError(t, err, `unknown graphite protocol "http"`)
+}
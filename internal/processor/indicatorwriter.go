@@ -0,0 +1,54 @@
+package processor
+
+import (
+	"database/sql"
+	"log"
+	"sync"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+// IndicatorWriter persists indicator values to PostgreSQL
+type IndicatorWriter struct {
+	db             *sql.DB
+	mutex          sync.Mutex
+	processedCount int
+}
+
+// NewIndicatorWriter creates a new IndicatorWriter
+func NewIndicatorWriter(db *sql.DB) (*IndicatorWriter, error) {
+	writer := &IndicatorWriter{
+		db: db,
+	}
+
+	return writer, nil
+}
+
+// ProcessIndicators stores the indicator values of a closed candle
+func (w *IndicatorWriter) ProcessIndicators(values models.IndicatorValues) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	_, err := w.db.Exec(`INSERT INTO indicator_values (
+        symbol, interval, open_time, sma, ema, rsi, macd, macd_signal, macd_histogram,
+        bollinger_upper, bollinger_middle, bollinger_lower, atr, vwap
+    ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+    ON CONFLICT (symbol, interval, open_time) DO NOTHING`,
+		values.Symbol, values.Interval, values.OpenTime, values.SMA, values.EMA, values.RSI, values.MACD,
+		values.MACDSignal, values.MACDHistogram, values.BollingerUpper, values.BollingerMiddle,
+		values.BollingerLower, values.ATR, values.VWAP,
+	)
+	if err != nil {
+		log.Printf("Error inserting indicator values: %v", err)
+		return
+	}
+
+	w.processedCount++
+}
+
+// GetProcessedCount returns the number of stored indicator rows
+func (w *IndicatorWriter) GetProcessedCount() int {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.processedCount
+}
@@ -0,0 +1,34 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndicatorWriter_ProcessIndicators(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	writer, err := NewIndicatorWriter(db)
+	assert.NoError(t, err)
+
+	sma := 101.5
+	values := models.IndicatorValues{Symbol: "BTCUSDT", Interval: "1m", OpenTime: 60000, SMA: &sma}
+
+	mock.ExpectExec(`INSERT INTO indicator_values`).
+		WithArgs(
+			"BTCUSDT", "1m", int64(60000), &sma, nil, nil, nil,
+			nil, nil, nil, nil,
+			nil, nil, nil,
+		).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	writer.ProcessIndicators(values)
+
+	assert.Equal(t, 1, writer.GetProcessedCount())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,224 @@
+package processor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+const (
+	defaultInfluxMeasurement = "ticker"
+	// maxUDPPayload keeps datagrams below a typical MTU so they are not
+	// fragmented
+	maxUDPPayload = 1400
+)
+
+// InfluxConfig holds the settings for writing ticks to InfluxDB or Telegraf
+// in line protocol. Lines are sent over UDP when UDPAddr is set and to the
+// HTTP v2 write API at URL otherwise.
+type InfluxConfig struct {
+	URL         string
+	Org         string
+	Bucket      string
+	Token       string
+	UDPAddr     string `mapstructure:"udp_addr"`
+	Measurement string
+	BatchConfig `mapstructure:",squash"`
+}
+
+// InfluxWriter implements DataProcessor interface for InfluxDB. Each tick is
+// a point tagged with its symbol, with prices, volumes and counts as fields
+// and the event time as its timestamp.
+type InfluxWriter struct {
+	client      *http.Client
+	cfg         InfluxConfig
+	conn        net.Conn
+	measurement string
+	batcher     *lineBatcher
+}
+
+// NewInfluxWriter creates a new InfluxWriter and starts flushing it
+// periodically
+func NewInfluxWriter(client *http.Client, cfg InfluxConfig) (*InfluxWriter, error) {
+	writer := &InfluxWriter{
+		client:      client,
+		cfg:         cfg,
+		measurement: cfg.Measurement,
+	}
+	if writer.measurement == "" {
+		writer.measurement = defaultInfluxMeasurement
+	}
+
+	var send sendFunc
+	switch {
+	case cfg.UDPAddr != "":
+		conn, err := net.Dial("udp", cfg.UDPAddr)
+		if err != nil {
+			return nil, fmt.Errorf("error dialing InfluxDB UDP listener %s: %w", cfg.UDPAddr, err)
+		}
+		writer.conn = conn
+		send = writer.sendUDP
+	case cfg.URL != "":
+		if cfg.Bucket == "" {
+			return nil, errors.New("influx bucket is required for the HTTP write API")
+		}
+		send = writer.sendHTTP
+	default:
+		return nil, errors.New("influx needs a url or udp_addr")
+	}
+
+	writer.batcher = newLineBatcher("InfluxDB", cfg.BatchConfig, send)
+	return writer, nil
+}
+
+// Process implements the DataProcessor interface
+func (w *InfluxWriter) Process(data models.FormattedData) {
+	w.batcher.add(influxLine(w.measurement, data))
+}
+
+// Flush sends the buffered points
+func (w *InfluxWriter) Flush() {
+	w.batcher.flush()
+}
+
+// sendHTTP posts a batch to the v2 write API. Client errors such as a bad
+// token or malformed points are not retried.
+func (w *InfluxWriter) sendHTTP(payload []byte) (bool, error) {
+	params := url.Values{
+		"org":       {w.cfg.Org},
+		"bucket":    {w.cfg.Bucket},
+		"precision": {"ns"},
+	}
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(w.cfg.URL, "/")+"/api/v2/write?"+params.Encode(), bytes.NewReader(payload))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if w.cfg.Token != "" {
+		req.Header.Set("Authorization", "Token "+w.cfg.Token)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return false, nil
+	}
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("unexpected status %s: %s", resp.Status, bytes.TrimSpace(message))
+}
+
+// sendUDP writes a batch as datagrams of whole lines
+func (w *InfluxWriter) sendUDP(payload []byte) (bool, error) {
+	return true, writeDatagrams(w.conn, payload)
+}
+
+// writeDatagrams splits payload at line boundaries into datagrams of at most
+// maxUDPPayload bytes; longer lines get a datagram of their own
+func writeDatagrams(conn net.Conn, payload []byte) error {
+	for len(payload) > 0 {
+		end := len(payload)
+		if end > maxUDPPayload {
+			end = bytes.LastIndexByte(payload[:maxUDPPayload], '\n') + 1
+			if end == 0 {
+				end = bytes.IndexByte(payload, '\n') + 1
+			}
+			if end == 0 {
+				end = len(payload)
+			}
+		}
+		if _, err := conn.Write(payload[:end]); err != nil {
+			return err
+		}
+		payload = payload[end:]
+	}
+	return nil
+}
+
+// influxLine encodes a tick as a newline-terminated line protocol point with
+// a nanosecond timestamp
+func influxLine(measurement string, data models.FormattedData) []byte {
+	var line bytes.Buffer
+	line.WriteString(influxEscaper.Replace(measurement))
+	line.WriteString(",symbol=")
+	line.WriteString(influxTagEscaper.Replace(data.Symbol))
+
+	separator := byte(' ')
+	writeFloat := func(name string, value float64) {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return
+		}
+		line.WriteByte(separator)
+		line.WriteString(name)
+		line.WriteByte('=')
+		line.WriteString(strconv.FormatFloat(value, 'f', -1, 64))
+		separator = ','
+	}
+	writeInt := func(name string, value int64) {
+		line.WriteByte(separator)
+		line.WriteString(name)
+		line.WriteByte('=')
+		line.WriteString(strconv.FormatInt(value, 10))
+		line.WriteByte('i')
+		separator = ','
+	}
+
+	writeFloat("last_price", data.LastPrice)
+	writeFloat("bid_price", data.BidPrice)
+	writeFloat("ask_price", data.AskPrice)
+	writeFloat("price_change", data.PriceChange)
+	writeFloat("high_price", data.HighPrice)
+	writeFloat("low_price", data.LowPrice)
+	writeFloat("volume", data.Volume)
+	writeFloat("quote_volume", data.QuoteVolume)
+	writeInt("trade_count", int64(data.TradeCount))
+	writeInt("latency", data.Latency)
+
+	line.WriteByte(' ')
+	line.WriteString(strconv.FormatInt(data.EventTime*1_000_000, 10))
+	line.WriteByte('\n')
+	return line.Bytes()
+}
+
+var (
+	influxEscaper    = strings.NewReplacer(",", `\,`, " ", `\ `)
+	influxTagEscaper = strings.NewReplacer(",", `\,`, " ", `\ `, "=", `\=`)
+)
+
+// GetFailedCount returns the number of ticks dropped after failed sends
+func (w *InfluxWriter) GetFailedCount() int {
+	return w.batcher.failed()
+}
+
+// GetProcessedCount returns the number of ticks sent
+func (w *InfluxWriter) GetProcessedCount() int {
+	return w.batcher.processed()
+}
+
+// GetBufferSize returns the number of ticks waiting to be sent
+func (w *InfluxWriter) GetBufferSize() int {
+	return w.batcher.size()
+}
+
+// Close sends the remaining ticks and closes the UDP socket
+func (w *InfluxWriter) Close() error {
+	w.batcher.close()
+	if w.conn != nil {
+		return w.conn.Close()
+	}
+	return nil
+}
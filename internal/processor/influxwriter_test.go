@@ -0,0 +1,181 @@
+package processor
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInfluxLine(t *testing.T) {
+	data := models.FormattedData{
+		EventTime:   1625097600000,
+		Symbol:      "BTCUSDT",
+		LastPrice:   34000.5,
+		BidPrice:    34000,
+		AskPrice:    34001,
+		PriceChange: -100,
+		HighPrice:   34500,
+		LowPrice:    33500,
+		Volume:      100.25,
+		QuoteVolume: 3400000,
+		TradeCount:  1000,
+		Latency:     100,
+	}
+
+	assert.Equal(t, "ticker,symbol=BTCUSDT last_price=34000.5,bid_price=34000,ask_price=34001,"+
+		"price_change=-100,high_price=34500,low_price=33500,volume=100.25,quote_volume=3400000,"+
+		"trade_count=1000i,latency=100i 1625097600000000000\n", string(influxLine("ticker", data)))
+
+	data.Symbol = "odd sym,bol=x"
+	line := string(influxLine("my ticks", data))
+	assert.True(t, strings.HasPrefix(line, `my\ ticks,symbol=odd\ sym\,bol\=x last_price=`), line)
+}
+
+// influxServer records the batches posted to a fake v2 write API
+type influxServer struct {
+	*httptest.Server
+	mutex    sync.Mutex
+	bodies   []string
+	requests []*http.Request
+	statuses []int
+}
+
+func newInfluxServer(t *testing.T, statuses ...int) *influxServer {
+	s := &influxServer{statuses: statuses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		s.requests = append(s.requests, r)
+		s.bodies = append(s.bodies, string(body))
+		if len(s.statuses) > 0 {
+			status := s.statuses[0]
+			s.statuses = s.statuses[1:]
+			http.Error(w, `{"code":"error"}`, status)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestInfluxWriter_HTTP(t *testing.T) {
+	server := newInfluxServer(t)
+	writer, err := NewInfluxWriter(server.Client(), InfluxConfig{
+		URL:         server.URL,
+		Org:         "ops",
+		Bucket:      "binance",
+		Token:       "secret",
+		BatchConfig: BatchConfig{BatchSize: 2, FlushInterval: time.Hour},
+	})
+	require.NoError(t, err)
+
+	writer.Process(tick(1000, 34000, 10, 5))
+	assert.Equal(t, 1, writer.GetBufferSize())
+	writer.Process(tick(2000, 34100, 12, 6))
+	assert.Eventually(t, func() bool { return writer.GetProcessedCount() == 2 }, time.Second, 5*time.Millisecond)
+	assert.Zero(t, writer.GetBufferSize())
+
+	server.mutex.Lock()
+	require.Len(t, server.requests, 1)
+	req := server.requests[0]
+	assert.Equal(t, "/api/v2/write", req.URL.Path)
+	assert.Equal(t, "ops", req.URL.Query().Get("org"))
+	assert.Equal(t, "binance", req.URL.Query().Get("bucket"))
+	assert.Equal(t, "ns", req.URL.Query().Get("precision"))
+	assert.Equal(t, "Token secret", req.Header.Get("Authorization"))
+	lines := strings.Split(strings.TrimSpace(server.bodies[0]), "\n")
+	server.mutex.Unlock()
+	require.Len(t, lines, 2)
+	assert.True(t, strings.HasSuffix(lines[1], " 2000000000"), lines[1])
+
+	require.NoError(t, writer.Close())
+}
+
+func TestInfluxWriter_HTTPRetries(t *testing.T) {
+	server := newInfluxServer(t, http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusBadRequest)
+	writer, err := NewInfluxWriter(server.Client(), InfluxConfig{
+		URL:         server.URL,
+		Bucket:      "binance",
+		BatchConfig: BatchConfig{FlushInterval: time.Hour, RetryDelay: time.Millisecond},
+	})
+	require.NoError(t, err)
+	defer writer.Close()
+
+	// Retried until the third attempt, which is rejected and not retried
+	writer.Process(tick(1000, 34000, 10, 5))
+	writer.Flush()
+	assert.Equal(t, 1, writer.GetFailedCount())
+
+	writer.Process(tick(2000, 34000, 10, 5))
+	writer.Flush()
+	assert.Equal(t, 1, writer.GetProcessedCount())
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	assert.Len(t, server.requests, 4)
+}
+
+func TestInfluxWriter_UDP(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	writer, err := NewInfluxWriter(nil, InfluxConfig{
+		UDPAddr:     listener.LocalAddr().String(),
+		Measurement: "binance",
+		BatchConfig: BatchConfig{FlushInterval: 10 * time.Millisecond},
+	})
+	require.NoError(t, err)
+	defer writer.Close()
+
+	writer.Process(tick(1000, 34000, 10, 5))
+
+	buf := make([]byte, 65536)
+	require.NoError(t, listener.SetReadDeadline(time.Now().Add(time.Second)))
+	n, _, err := listener.ReadFrom(buf)
+	require.NoError(t, err)
+	assert.Equal(t, string(influxLine("binance", tick(1000, 34000, 10, 5))), string(buf[:n]))
+}
+
+func TestNewInfluxWriter_Errors(t *testing.T) {
+	_, err := NewInfluxWriter(nil, InfluxConfig{})
+	assert.Error(t, err)
+
+	_, err = NewInfluxWriter(nil, InfluxConfig{URL: "http://localhost:8086"})
+	assert.Error(t, err)
+}
+
+func TestWriteDatagrams(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	conn, err := net.Dial("udp", listener.LocalAddr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	line := strings.Repeat("x", 599) + "\n"
+	require.NoError(t, writeDatagrams(conn, []byte(strings.Repeat(line, 5))))
+
+	// Two lines fit in a datagram
+	buf := make([]byte, 65536)
+	var sizes []int
+	for i := 0; i < 3; i++ {
+		require.NoError(t, listener.SetReadDeadline(time.Now().Add(time.Second)))
+		n, _, err := listener.ReadFrom(buf)
+		require.NoError(t, err)
+		sizes = append(sizes, n)
+	}
+	assert.Equal(t, []int{1200, 1200, 600}, sizes)
+}
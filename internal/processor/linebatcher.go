@@ -0,0 +1,185 @@
+package processor
+
+import (
+	"bytes"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	defaultLineBatchSize     = 500
+	defaultLineFlushInterval = time.Second
+	// lineBufferBatches is how many batches may wait while the sink is down
+	// before new ticks are dropped
+	lineBufferBatches = 10
+)
+
+// BatchConfig holds the batching and retry settings of the line protocol
+// exporters
+type BatchConfig struct {
+	// BatchSize is how many ticks are buffered before they are sent
+	BatchSize int `mapstructure:"batch_size"`
+	// FlushInterval is the longest a tick stays buffered
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+	// MaxAttempts is how many times a batch is sent before it is dropped
+	MaxAttempts int `mapstructure:"max_attempts"`
+	// RetryDelay is the delay before a retry, multiplied by the attempt number
+	RetryDelay time.Duration `mapstructure:"retry_delay"`
+}
+
+// sendFunc delivers a batch of newline-terminated lines and reports whether
+// a failure is worth retrying
+type sendFunc func(payload []byte) (retry bool, err error)
+
+// lineBatcher buffers the lines of ticks and sends them in batches when the
+// buffer is full or the flush interval elapses. Batches are sent by a
+// background goroutine so that a slow or unreachable sink never blocks the
+// caller; while it is down at most lineBufferBatches batches are kept and
+// later ticks are dropped.
+type lineBatcher struct {
+	name           string
+	cfg            BatchConfig
+	send           sendFunc
+	buffer         bytes.Buffer
+	buffered       int
+	mutex          sync.Mutex
+	flushMutex     sync.Mutex
+	processedCount int
+	failedCount    int
+	full           chan struct{}
+	stop           chan struct{}
+	done           chan struct{}
+	closeOnce      sync.Once
+}
+
+func newLineBatcher(name string, cfg BatchConfig, send sendFunc) *lineBatcher {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultLineBatchSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = defaultLineFlushInterval
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultMaxAttempts
+	}
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = defaultRetryDelay
+	}
+
+	b := &lineBatcher{
+		name: name,
+		cfg:  cfg,
+		send: send,
+		full: make(chan struct{}, 1),
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+// add buffers the lines of one tick, asking for the batch to be sent once it
+// is full
+func (b *lineBatcher) add(lines []byte) {
+	b.mutex.Lock()
+	if b.buffered >= b.cfg.BatchSize*lineBufferBatches {
+		b.failedCount++
+		b.mutex.Unlock()
+		return
+	}
+	b.buffer.Write(lines)
+	b.buffered++
+	full := b.buffered >= b.cfg.BatchSize
+	b.mutex.Unlock()
+
+	if full {
+		select {
+		case b.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// flush sends the buffered ticks, retrying failures that may be transient
+func (b *lineBatcher) flush() {
+	b.flushMutex.Lock()
+	defer b.flushMutex.Unlock()
+
+	b.mutex.Lock()
+	payload := bytes.Clone(b.buffer.Bytes())
+	count := b.buffered
+	b.buffer.Reset()
+	b.buffered = 0
+	b.mutex.Unlock()
+
+	if count == 0 {
+		return
+	}
+
+	for attempt := 1; attempt <= b.cfg.MaxAttempts; attempt++ {
+		retry, err := b.send(payload)
+		if err == nil {
+			b.mutex.Lock()
+			b.processedCount += count
+			b.mutex.Unlock()
+			return
+		}
+		log.Printf("Error sending %d ticks to %s (attempt %d/%d): %v", count, b.name, attempt, b.cfg.MaxAttempts, err)
+		if !retry {
+			break
+		}
+		if attempt < b.cfg.MaxAttempts {
+			time.Sleep(b.cfg.RetryDelay * time.Duration(attempt))
+		}
+	}
+
+	b.mutex.Lock()
+	b.failedCount += count
+	b.mutex.Unlock()
+}
+
+func (b *lineBatcher) run() {
+	defer close(b.done)
+
+	ticker := time.NewTicker(b.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-ticker.C:
+			b.flush()
+		case <-b.full:
+			b.flush()
+		}
+	}
+}
+
+// close stops the periodic flush and sends the remaining ticks
+func (b *lineBatcher) close() {
+	b.closeOnce.Do(func() {
+		close(b.stop)
+		<-b.done
+		b.flush()
+	})
+}
+
+func (b *lineBatcher) processed() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.processedCount
+}
+
+func (b *lineBatcher) failed() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.failedCount
+}
+
+func (b *lineBatcher) size() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buffered
+}
@@ -0,0 +1,107 @@
+package processor
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingSender collects sent payloads and fails the first failNext sends
+type recordingSender struct {
+	mutex    sync.Mutex
+	payloads []string
+	failNext int
+	retry    bool
+}
+
+func (s *recordingSender) send(payload []byte) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.failNext > 0 {
+		s.failNext--
+		return s.retry, errors.New("unavailable")
+	}
+	s.payloads = append(s.payloads, string(payload))
+	return false, nil
+}
+
+func (s *recordingSender) sent() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]string(nil), s.payloads...)
+}
+
+func TestLineBatcher_FlushesOnIntervalAndClose(t *testing.T) {
+	sender := &recordingSender{}
+	batcher := newLineBatcher("test", BatchConfig{BatchSize: 10, FlushInterval: 20 * time.Millisecond}, sender.send)
+
+	batcher.add([]byte("a\n"))
+	batcher.add([]byte("b\nc\n"))
+	assert.Equal(t, 2, batcher.size())
+	assert.Eventually(t, func() bool { return len(sender.sent()) == 1 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, "a\nb\nc\n", sender.sent()[0])
+	assert.Equal(t, 2, batcher.processed())
+
+	batcher.add([]byte("d\n"))
+	batcher.close()
+	assert.Equal(t, []string{"a\nb\nc\n", "d\n"}, sender.sent())
+	assert.Equal(t, 3, batcher.processed())
+}
+
+func TestLineBatcher_Retries(t *testing.T) {
+	sender := &recordingSender{failNext: 2, retry: true}
+	batcher := newLineBatcher("test", BatchConfig{BatchSize: 1, FlushInterval: time.Hour, RetryDelay: time.Millisecond}, sender.send)
+	defer batcher.close()
+
+	batcher.add([]byte("a\n"))
+	assert.Eventually(t, func() bool { return len(sender.sent()) == 1 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"a\n"}, sender.sent())
+	assert.Zero(t, batcher.failed())
+
+	// Permanent errors are not retried
+	sender.mutex.Lock()
+	sender.failNext, sender.retry = 1, false
+	sender.mutex.Unlock()
+	batcher.add([]byte("b\n"))
+	assert.Eventually(t, func() bool { return batcher.failed() == 1 }, time.Second, 5*time.Millisecond)
+	assert.Len(t, sender.sent(), 1)
+}
+
+func TestLineBatcher_SlowSinkDoesNotBlock(t *testing.T) {
+	release := make(chan struct{})
+	var sends sync.WaitGroup
+	sends.Add(1)
+	sender := func([]byte) (bool, error) {
+		sends.Done()
+		<-release
+		return false, nil
+	}
+	batcher := newLineBatcher("test", BatchConfig{BatchSize: 1, FlushInterval: time.Hour}, sender)
+
+	// The first batch is stuck in the sink
+	batcher.add([]byte("a\n"))
+	sends.Wait()
+	sends.Add(1)
+
+	added := make(chan struct{})
+	go func() {
+		for i := 0; i < 20; i++ {
+			batcher.add([]byte("b\n"))
+		}
+		close(added)
+	}()
+	select {
+	case <-added:
+	case <-time.After(time.Second):
+		t.Fatal("add blocked on a slow sink")
+	}
+	assert.Equal(t, lineBufferBatches, batcher.size())
+	assert.Equal(t, 20-lineBufferBatches, batcher.failed())
+
+	close(release)
+	batcher.close()
+	assert.Equal(t, 1+lineBufferBatches, batcher.processed())
+}
@@ -0,0 +1,133 @@
+package processor
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+const defaultNATSSubjectPrefix = "binance.ticker"
+
+// NATSConfig holds the settings for publishing ticker data to NATS
+type NATSConfig struct {
+	URL           string
+	SubjectPrefix string `mapstructure:"subject_prefix"`
+	JetStream     bool
+	Stream        string
+	// DedupWindow is how long JetStream remembers message IDs for deduplication
+	DedupWindow time.Duration `mapstructure:"dedup_window"`
+}
+
+// NATSPublisher implements DataProcessor interface for NATS
+type NATSPublisher struct {
+	conn           *nats.Conn
+	js             nats.JetStreamContext
+	subjectPrefix  string
+	mutex          sync.Mutex
+	processedCount int
+}
+
+// NewNATSPublisher creates a new NATSPublisher. When JetStream is enabled the
+// configured stream is created if it does not exist yet.
+func NewNATSPublisher(conn *nats.Conn, cfg NATSConfig) (*NATSPublisher, error) {
+	prefix := cfg.SubjectPrefix
+	if prefix == "" {
+		prefix = defaultNATSSubjectPrefix
+	}
+
+	publisher := &NATSPublisher{
+		conn:          conn,
+		subjectPrefix: prefix,
+	}
+
+	if !cfg.JetStream {
+		return publisher, nil
+	}
+
+	js, err := conn.JetStream()
+	if err != nil {
+		return nil, fmt.Errorf("creating JetStream context: %w", err)
+	}
+
+	stream := cfg.Stream
+	if stream == "" {
+		stream = "BINANCE_TICKER"
+	}
+	if _, err := js.StreamInfo(stream); err != nil {
+		if _, err := js.AddStream(&nats.StreamConfig{
+			Name:       stream,
+			Subjects:   []string{prefix + ".>"},
+			Duplicates: cfg.DedupWindow,
+		}); err != nil {
+			return nil, fmt.Errorf("creating JetStream stream %s: %w", stream, err)
+		}
+	}
+	publisher.js = js
+
+	return publisher, nil
+}
+
+// Subject returns the subject a symbol's ticks are published on
+func (p *NATSPublisher) Subject(symbol string) string {
+	return p.subjectPrefix + "." + strings.ToLower(symbol)
+}
+
+// Process implements the DataProcessor interface
+func (p *NATSPublisher) Process(data models.FormattedData) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Error encoding data for NATS: %v", err)
+		return
+	}
+
+	subject := p.Subject(data.Symbol)
+	if p.js != nil {
+		_, err = p.js.Publish(subject, payload, nats.MsgId(messageID(data)))
+	} else {
+		err = p.conn.Publish(subject, payload)
+	}
+	if err != nil {
+		log.Printf("Error publishing to NATS subject %s: %v", subject, err)
+		return
+	}
+
+	p.mutex.Lock()
+	p.processedCount++
+	p.mutex.Unlock()
+}
+
+// GetProcessedCount returns the number of published messages
+func (p *NATSPublisher) GetProcessedCount() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.processedCount
+}
+
+// GetBufferSize returns the number of messages not yet flushed to the server
+func (p *NATSPublisher) GetBufferSize() int {
+	if p.js != nil {
+		return 0
+	}
+	buffered, err := p.conn.Buffered()
+	if err != nil {
+		return 0
+	}
+	return buffered
+}
+
+// Close drains and closes the NATS connection
+func (p *NATSPublisher) Close() error {
+	return p.conn.Drain()
+}
+
+// messageID derives the JetStream deduplication ID for a tick
+func messageID(data models.FormattedData) string {
+	return fmt.Sprintf("%s-%d", strings.ToLower(data.Symbol), data.EventTime)
+}
@@ -0,0 +1,89 @@
+package processor
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+func runNATSServer(t *testing.T) *server.Server {
+	t.Helper()
+	srv, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      -1,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	require.NoError(t, err)
+	go srv.Start()
+	require.True(t, srv.ReadyForConnections(5*time.Second), "NATS server did not start")
+	t.Cleanup(srv.Shutdown)
+	return srv
+}
+
+func TestNATSPublisher_Process(t *testing.T) {
+	srv := runNATSServer(t)
+	conn, err := nats.Connect(srv.ClientURL())
+	require.NoError(t, err)
+
+	publisher, err := NewNATSPublisher(conn, NATSConfig{})
+	require.NoError(t, err)
+	defer publisher.Close()
+
+	sub, err := conn.SubscribeSync("binance.ticker.btcusdt")
+	require.NoError(t, err)
+
+	data := models.FormattedData{EventTime: 1625097600000, Symbol: "BTCUSDT", LastPrice: 34000.0}
+	publisher.Process(data)
+
+	msg, err := sub.NextMsg(2 * time.Second)
+	require.NoError(t, err)
+
+	var received models.FormattedData
+	require.NoError(t, json.Unmarshal(msg.Data, &received))
+	assert.Equal(t, data, received)
+	assert.Equal(t, 1, publisher.GetProcessedCount())
+}
+
+func TestNATSPublisher_JetStreamDeduplication(t *testing.T) {
+	srv := runNATSServer(t)
+	conn, err := nats.Connect(srv.ClientURL())
+	require.NoError(t, err)
+
+	publisher, err := NewNATSPublisher(conn, NATSConfig{
+		JetStream:   true,
+		Stream:      "TICKS",
+		DedupWindow: time.Minute,
+	})
+	require.NoError(t, err)
+	defer publisher.Close()
+
+	data := models.FormattedData{EventTime: 1625097600000, Symbol: "ETHUSDT", LastPrice: 2000.0}
+	publisher.Process(data)
+	publisher.Process(data)
+	data.EventTime++
+	publisher.Process(data)
+
+	js, err := conn.JetStream()
+	require.NoError(t, err)
+	info, err := js.StreamInfo("TICKS")
+	require.NoError(t, err)
+
+	assert.Equal(t, uint64(2), info.State.Msgs, "duplicate message should be dropped by JetStream")
+	assert.Equal(t, 3, publisher.GetProcessedCount())
+	assert.Equal(t, 0, publisher.GetBufferSize())
+}
+
+func TestNATSPublisher_Subject(t *testing.T) {
+	publisher := &NATSPublisher{subjectPrefix: "custom.prefix"}
+	assert.Equal(t, "custom.prefix.btcusdt", publisher.Subject("BTCUSDT"))
+}
@@ -0,0 +1,76 @@
+package processor
+
+import (
+	"database/sql"
+	"log"
+	"sync"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+// PaperTradeWriter persists paper trading orders and fills to PostgreSQL.
+// Order IDs restart with every run, so rows are keyed by a session name.
+type PaperTradeWriter struct {
+	db             *sql.DB
+	session        string
+	mutex          sync.Mutex
+	processedCount int
+}
+
+// NewPaperTradeWriter creates a new PaperTradeWriter for a session
+func NewPaperTradeWriter(db *sql.DB, session string) (*PaperTradeWriter, error) {
+	writer := &PaperTradeWriter{
+		db:      db,
+		session: session,
+	}
+
+	return writer, nil
+}
+
+// ProcessOrder inserts or updates an order
+func (w *PaperTradeWriter) ProcessOrder(order models.Order) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	_, err := w.db.Exec(`INSERT INTO paper_orders (
+        session, id, symbol, side, type, quantity, price, stop_price, status, filled_price, reason, created_time, updated_time
+    ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+    ON CONFLICT (session, id) DO UPDATE SET
+        status = EXCLUDED.status, filled_price = EXCLUDED.filled_price,
+        reason = EXCLUDED.reason, updated_time = EXCLUDED.updated_time`,
+		w.session, order.ID, order.Symbol, string(order.Side), string(order.Type), order.Quantity, order.Price,
+		order.StopPrice, string(order.Status), order.FilledPrice, order.Reason, order.CreatedTime, order.UpdatedTime,
+	)
+	if err != nil {
+		log.Printf("Error upserting paper order: %v", err)
+		return
+	}
+
+	w.processedCount++
+}
+
+// ProcessFill inserts a fill
+func (w *PaperTradeWriter) ProcessFill(fill models.Fill) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	_, err := w.db.Exec(`INSERT INTO paper_fills (
+        session, order_id, symbol, side, price, quantity, fee, fee_asset, maker, event_time
+    ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		w.session, fill.OrderID, fill.Symbol, string(fill.Side), fill.Price, fill.Quantity, fill.Fee,
+		fill.FeeAsset, fill.Maker, fill.EventTime,
+	)
+	if err != nil {
+		log.Printf("Error inserting paper fill: %v", err)
+		return
+	}
+
+	w.processedCount++
+}
+
+// GetProcessedCount returns the number of stored orders and fills
+func (w *PaperTradeWriter) GetProcessedCount() int {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.processedCount
+}
@@ -0,0 +1,45 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaperTradeWriter_ProcessOrderAndFill(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	writer, err := NewPaperTradeWriter(db, "run-1")
+	assert.NoError(t, err)
+
+	order := models.Order{
+		ID:          1,
+		Symbol:      "BTCUSDT",
+		Side:        models.SideBuy,
+		Type:        models.OrderLimit,
+		Quantity:    0.1,
+		Price:       49000,
+		Status:      models.StatusFilled,
+		FilledPrice: 49000,
+		CreatedTime: 1000,
+		UpdatedTime: 2000,
+	}
+	fill := models.Fill{OrderID: 1, Symbol: "BTCUSDT", Side: models.SideBuy, Price: 49000, Quantity: 0.1, Fee: 4.9, FeeAsset: "USDT", Maker: true, EventTime: 2000}
+
+	mock.ExpectExec(`INSERT INTO paper_orders .* ON CONFLICT \(session, id\) DO UPDATE`).
+		WithArgs("run-1", int64(1), "BTCUSDT", "BUY", "LIMIT", 0.1, 49000.0, 0.0, "FILLED", 49000.0, "", int64(1000), int64(2000)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`INSERT INTO paper_fills`).
+		WithArgs("run-1", int64(1), "BTCUSDT", "BUY", 49000.0, 0.1, 4.9, "USDT", true, int64(2000)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	writer.ProcessOrder(order)
+	writer.ProcessFill(fill)
+
+	assert.Equal(t, 2, writer.GetProcessedCount())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,46 +1,198 @@
 package processor
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
 )
 
-// PGWriter implements DataProcessor interface for PostgreSQL
+const (
+	defaultMaxAttempts    = 3
+	defaultRetryDelay     = 100 * time.Millisecond
+	defaultExecTimeout    = 5 * time.Second
+	defaultDedupeCapacity = 10000
+)
+
+// tickKey is the natural key of a ticker_data row
+type tickKey struct {
+	symbol    string
+	eventTime int64
+}
+
+// recentKeys remembers the most recently stored keys, forgetting the oldest
+// once full
+type recentKeys struct {
+	keys  map[tickKey]struct{}
+	order []tickKey
+	next  int
+}
+
+func newRecentKeys(capacity int) *recentKeys {
+	return &recentKeys{
+		keys:  make(map[tickKey]struct{}, capacity),
+		order: make([]tickKey, 0, capacity),
+	}
+}
+
+func (r *recentKeys) contains(key tickKey) bool {
+	_, ok := r.keys[key]
+	return ok
+}
+
+func (r *recentKeys) add(key tickKey) {
+	if r.contains(key) || cap(r.order) == 0 {
+		return
+	}
+	if len(r.order) < cap(r.order) {
+		r.order = append(r.order, key)
+	} else {
+		delete(r.keys, r.order[r.next])
+		r.order[r.next] = key
+		r.next = (r.next + 1) % len(r.order)
+	}
+	r.keys[key] = struct{}{}
+}
+
+// remove forgets a key, e.g. one whose insert failed, so that a re-delivery
+// is stored. Its slot is reused when it comes up for eviction.
+func (r *recentKeys) remove(key tickKey) {
+	delete(r.keys, key)
+}
+
+// PGWriter implements DataProcessor interface for PostgreSQL. Ticks are
+// unique on (symbol, event_time): recently stored keys are skipped without a
+// round trip and older duplicates are ignored by the database. Writers are
+// safe to share between symbols: a retrying insert does not hold up others.
 type PGWriter struct {
-	db             *sql.DB
+	db *sql.DB
+	// borrowed leaves db open on Close for the caller that owns it
+	borrowed       bool
+	maxAttempts    int
+	retryDelay     time.Duration
+	execTimeout    time.Duration
+	recent         *recentKeys
 	mutex          sync.Mutex
 	processedCount int
+	failedCount    int
+	cacheHits      int
+	conflicts      int
 }
 
 // NewPGWriter creates a new PGWriter
 func NewPGWriter(db *sql.DB) (*PGWriter, error) {
 	writer := &PGWriter{
-		db: db,
+		db:          db,
+		maxAttempts: defaultMaxAttempts,
+		retryDelay:  defaultRetryDelay,
+		execTimeout: defaultExecTimeout,
+		recent:      newRecentKeys(defaultDedupeCapacity),
 	}
 
 	return writer, nil
 }
 
-// Process implements the DataProcessor interface
-func (w *PGWriter) Process(data models.FormattedData) {
+// SetDedupeCapacity sets how many recently stored keys are remembered; 0
+// leaves all deduplication to the database
+func (w *PGWriter) SetDedupeCapacity(capacity int) {
 	w.mutex.Lock()
 	defer w.mutex.Unlock()
+	w.recent = newRecentKeys(capacity)
+}
+
+// SetRetryPolicy sets how many times an insert is attempted, the delay before
+// each retry (multiplied by the attempt number) and the timeout of each attempt
+func (w *PGWriter) SetRetryPolicy(maxAttempts int, retryDelay, execTimeout time.Duration) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.maxAttempts = maxAttempts
+	w.retryDelay = retryDelay
+	w.execTimeout = execTimeout
+}
+
+// Process implements the DataProcessor interface. Failed or timed out inserts
+// are retried; a tick that still cannot be stored is counted as failed.
+func (w *PGWriter) Process(data models.FormattedData) {
+	w.mutex.Lock()
+	key := tickKey{symbol: data.Symbol, eventTime: data.EventTime}
+	if w.recent.contains(key) {
+		w.cacheHits++
+		w.mutex.Unlock()
+		return
+	}
+	maxAttempts, retryDelay, execTimeout := w.maxAttempts, w.retryDelay, w.execTimeout
+	w.mutex.Unlock()
 
-	_, err := w.db.Exec(`INSERT INTO ticker_data (
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		inserted, err := w.insert(data, execTimeout)
+		if err == nil {
+			w.mutex.Lock()
+			if inserted {
+				w.processedCount++
+			} else {
+				w.conflicts++
+			}
+			w.recent.add(key)
+			w.mutex.Unlock()
+			return
+		}
+		fmt.Printf("Error inserting data (attempt %d/%d): %v\n", attempt, maxAttempts, err)
+		if attempt < maxAttempts {
+			time.Sleep(retryDelay * time.Duration(attempt))
+		}
+	}
+
+	w.mutex.Lock()
+	w.failedCount++
+	w.mutex.Unlock()
+}
+
+// insert stores a tick and reports whether it was new
+func (w *PGWriter) insert(data models.FormattedData, timeout time.Duration) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	result, err := w.db.ExecContext(ctx, `INSERT INTO ticker_data (
         event_time, symbol, last_price, price_change, high_price, low_price, volume, quote_volume, open_time, close_time, trade_count, latency
-    ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+    ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+    ON CONFLICT (symbol, event_time) DO NOTHING`,
 		data.EventTime, data.Symbol, data.LastPrice, data.PriceChange, data.HighPrice, data.LowPrice,
 		data.Volume, data.QuoteVolume, data.OpenTime, data.CloseTime, data.TradeCount, data.Latency,
 	)
 	if err != nil {
-		fmt.Printf("Error inserting data: %v\n", err)
-		return
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
 	}
+	return rows > 0, nil
+}
+
+// GetDuplicateCount returns the number of ticks skipped because they were
+// already stored, whether found in the recent-key cache or by the database
+func (w *PGWriter) GetDuplicateCount() int {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.cacheHits + w.conflicts
+}
+
+// GetDedupeCacheHits returns the number of duplicates skipped without a
+// round trip to the database
+func (w *PGWriter) GetDedupeCacheHits() int {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.cacheHits
+}
 
-	w.processedCount++
+// GetFailedCount returns the number of ticks that could not be stored
+func (w *PGWriter) GetFailedCount() int {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.failedCount
 }
 
 // GetProcessedCount returns the number of processed messages
@@ -55,7 +207,11 @@ func (w *PGWriter) GetBufferSize() int {
 	return 0
 }
 
-// Close closes the PostgreSQL connection
+// Close closes the PostgreSQL connection unless it is borrowed from the
+// caller
 func (w *PGWriter) Close() error {
+	if w.borrowed {
+		return nil
+	}
 	return w.db.Close()
 }
@@ -1,7 +1,9 @@
 package processor
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
@@ -115,3 +117,130 @@ func TestPGWriter_Close(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestPGWriter_ProcessRetries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	writer, err := NewPGWriter(db)
+	assert.NoError(t, err)
+	writer.SetRetryPolicy(3, time.Millisecond, time.Second)
+
+	data := models.FormattedData{EventTime: 1625097600000, Symbol: "btcusdt", LastPrice: 34000.0}
+
+	// Succeeds on the second attempt
+	mock.ExpectExec(`INSERT INTO ticker_data`).WillReturnError(errors.New("connection reset"))
+	mock.ExpectExec(`INSERT INTO ticker_data`).WillReturnResult(sqlmock.NewResult(1, 1))
+	writer.Process(data)
+	assert.Equal(t, 1, writer.GetProcessedCount())
+	assert.Equal(t, 0, writer.GetFailedCount())
+
+	// Fails every attempt
+	for i := 0; i < 3; i++ {
+		mock.ExpectExec(`INSERT INTO ticker_data`).WillReturnError(errors.New("connection refused"))
+	}
+	data.EventTime++
+	writer.Process(data)
+	assert.Equal(t, 1, writer.GetProcessedCount())
+	assert.Equal(t, 1, writer.GetFailedCount())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGWriter_ProcessTimeout(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	writer, err := NewPGWriter(db)
+	assert.NoError(t, err)
+	writer.SetRetryPolicy(1, 0, 10*time.Millisecond)
+
+	mock.ExpectExec(`INSERT INTO ticker_data`).WillDelayFor(time.Second).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	started := time.Now()
+	writer.Process(models.FormattedData{EventTime: 1, Symbol: "btcusdt"})
+	assert.Less(t, time.Since(started), 500*time.Millisecond)
+	assert.Equal(t, 1, writer.GetFailedCount())
+}
+
+func TestPGWriter_ProcessDeduplicates(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	writer, err := NewPGWriter(db)
+	assert.NoError(t, err)
+
+	data := models.FormattedData{EventTime: 1625097600000, Symbol: "BTCUSDT", LastPrice: 34000.0}
+
+	// The second copy is skipped by the recent-key cache
+	mock.ExpectExec(`INSERT INTO ticker_data .* ON CONFLICT \(symbol, event_time\) DO NOTHING`).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	writer.Process(data)
+	writer.Process(data)
+
+	// A duplicate the cache does not know about is ignored by the database
+	writer.SetDedupeCapacity(0)
+	mock.ExpectExec(`INSERT INTO ticker_data`).WillReturnResult(sqlmock.NewResult(0, 0))
+	writer.Process(data)
+
+	assert.Equal(t, 1, writer.GetProcessedCount())
+	assert.Equal(t, 2, writer.GetDuplicateCount())
+	assert.Equal(t, 1, writer.GetDedupeCacheHits())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGWriter_RetryDoesNotBlockOtherSymbols(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	mock.MatchExpectationsInOrder(false)
+
+	writer, err := NewPGWriter(db)
+	assert.NoError(t, err)
+	writer.SetRetryPolicy(2, 500*time.Millisecond, time.Second)
+
+	mock.ExpectExec(`INSERT INTO ticker_data`).WithArgs(int64(1000), "BTCUSDT", sqlmock.AnyArg(), sqlmock.AnyArg(),
+		sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+		sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnError(errors.New("connection reset"))
+	mock.ExpectExec(`INSERT INTO ticker_data`).WithArgs(int64(1000), "BTCUSDT", sqlmock.AnyArg(), sqlmock.AnyArg(),
+		sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+		sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`INSERT INTO ticker_data`).WithArgs(int64(1000), "ETHUSDT", sqlmock.AnyArg(), sqlmock.AnyArg(),
+		sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+		sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		writer.Process(models.FormattedData{EventTime: 1000, Symbol: "BTCUSDT"})
+	}()
+	// Let the BTCUSDT insert fail and start waiting for its retry
+	time.Sleep(50 * time.Millisecond)
+
+	start := time.Now()
+	writer.Process(models.FormattedData{EventTime: 1000, Symbol: "ETHUSDT"})
+	assert.Less(t, time.Since(start), 250*time.Millisecond, "Another symbol should not wait for the retry")
+
+	<-done
+	assert.Equal(t, 2, writer.GetProcessedCount())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRecentKeys_EvictsOldest(t *testing.T) {
+	recent := newRecentKeys(2)
+	a := tickKey{symbol: "BTCUSDT", eventTime: 1}
+	b := tickKey{symbol: "BTCUSDT", eventTime: 2}
+	c := tickKey{symbol: "ETHUSDT", eventTime: 2}
+
+	recent.add(a)
+	recent.add(b)
+	recent.add(b)
+	assert.True(t, recent.contains(a))
+
+	recent.add(c)
+	assert.False(t, recent.contains(a))
+	assert.True(t, recent.contains(b))
+	assert.True(t, recent.contains(c))
+}
@@ -0,0 +1,57 @@
+package processor
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"sync"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+// PortfolioWriter persists the portfolio valuation time series to PostgreSQL
+type PortfolioWriter struct {
+	db             *sql.DB
+	mutex          sync.Mutex
+	processedCount int
+}
+
+// NewPortfolioWriter creates a new PortfolioWriter
+func NewPortfolioWriter(db *sql.DB) (*PortfolioWriter, error) {
+	writer := &PortfolioWriter{
+		db: db,
+	}
+
+	return writer, nil
+}
+
+// ProcessValuation stores a valuation with its per-asset breakdown as JSON
+func (w *PortfolioWriter) ProcessValuation(valuation models.PortfolioValuation) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	assets, err := json.Marshal(valuation.Assets)
+	if err != nil {
+		log.Printf("Error encoding portfolio assets: %v", err)
+		return
+	}
+
+	_, err = w.db.Exec(`INSERT INTO portfolio_valuations (
+        event_time, quote, total_value, day_open_value, day_pnl, assets
+    ) VALUES ($1, $2, $3, $4, $5, $6)`,
+		valuation.EventTime, valuation.Quote, valuation.TotalValue, valuation.DayOpenValue, valuation.DayPnL, string(assets),
+	)
+	if err != nil {
+		log.Printf("Error inserting portfolio valuation: %v", err)
+		return
+	}
+
+	w.processedCount++
+}
+
+// GetProcessedCount returns the number of stored valuations
+func (w *PortfolioWriter) GetProcessedCount() int {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.processedCount
+}
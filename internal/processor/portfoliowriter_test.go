@@ -0,0 +1,39 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPortfolioWriter_ProcessValuation(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	writer, err := NewPortfolioWriter(db)
+	assert.NoError(t, err)
+
+	valuation := models.PortfolioValuation{
+		EventTime:    60000,
+		Quote:        "USDT",
+		TotalValue:   31000,
+		DayOpenValue: 30000,
+		DayPnL:       1000,
+		Assets: []models.AssetValuation{
+			{Asset: "BTC", Quantity: 0.5, Price: 62000, Value: 31000, Weight: 1, DayPnL: 1000},
+		},
+	}
+
+	mock.ExpectExec(`INSERT INTO portfolio_valuations`).
+		WithArgs(int64(60000), "USDT", 31000.0, 30000.0, 1000.0,
+			`[{"asset":"BTC","quantity":0.5,"price":62000,"value":31000,"weight":1,"day_pnl":1000}]`).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	writer.ProcessValuation(valuation)
+
+	assert.Equal(t, 1, writer.GetProcessedCount())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
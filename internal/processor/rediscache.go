@@ -0,0 +1,206 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+const (
+	defaultRedisKeyPrefix = "binance"
+	redisWriteTimeout     = 2 * time.Second
+)
+
+// RedisConfig holds the settings for the Redis latest-price cache
+type RedisConfig struct {
+	Addr      string
+	Password  string
+	DB        int
+	KeyPrefix string `mapstructure:"key_prefix"`
+	// TTL expires a symbol's hash when no updates arrive, signalling staleness
+	TTL time.Duration
+	// StreamMaxLen caps the tick stream; zero disables the stream
+	StreamMaxLen int64 `mapstructure:"stream_max_len"`
+}
+
+// RedisCache implements DataProcessor interface for Redis. It keeps a hash of
+// the latest data per symbol, publishes every update on a channel and
+// optionally appends it to a capped stream.
+type RedisCache struct {
+	client         *redis.Client
+	keyPrefix      string
+	ttl            time.Duration
+	streamMaxLen   int64
+	mutex          sync.Mutex
+	processedCount int
+}
+
+// NewRedisCache creates a new RedisCache
+func NewRedisCache(client *redis.Client, cfg RedisConfig) (*RedisCache, error) {
+	prefix := cfg.KeyPrefix
+	if prefix == "" {
+		prefix = defaultRedisKeyPrefix
+	}
+
+	cache := &RedisCache{
+		client:       client,
+		keyPrefix:    prefix,
+		ttl:          cfg.TTL,
+		streamMaxLen: cfg.StreamMaxLen,
+	}
+
+	return cache, nil
+}
+
+// LatestKey returns the hash key holding the latest data for a symbol
+func (c *RedisCache) LatestKey(symbol string) string {
+	return fmt.Sprintf("%s:latest:%s", c.keyPrefix, strings.ToLower(symbol))
+}
+
+// Channel returns the pub/sub channel updates for a symbol are published on
+func (c *RedisCache) Channel(symbol string) string {
+	return fmt.Sprintf("%s:ticker:%s", c.keyPrefix, strings.ToLower(symbol))
+}
+
+// StreamKey returns the key of the capped tick stream
+func (c *RedisCache) StreamKey() string {
+	return c.keyPrefix + ":ticks"
+}
+
+// Process implements the DataProcessor interface
+func (c *RedisCache) Process(data models.FormattedData) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Error encoding data for Redis: %v", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisWriteTimeout)
+	defer cancel()
+
+	key := c.LatestKey(data.Symbol)
+	pipe := c.client.TxPipeline()
+	pipe.HSet(ctx, key, redisFields(data))
+	if c.ttl > 0 {
+		pipe.Expire(ctx, key, c.ttl)
+	}
+	pipe.Publish(ctx, c.Channel(data.Symbol), payload)
+	if c.streamMaxLen > 0 {
+		pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream: c.StreamKey(),
+			MaxLen: c.streamMaxLen,
+			Approx: true,
+			Values: map[string]interface{}{"symbol": data.Symbol, "data": payload},
+		})
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("Error writing data to Redis: %v", err)
+		return
+	}
+
+	c.mutex.Lock()
+	c.processedCount++
+	c.mutex.Unlock()
+}
+
+// Latest reads the cached data for a symbol. It returns redis.Nil when the
+// symbol has no entry, either because it was never seen or because it expired.
+func (c *RedisCache) Latest(ctx context.Context, symbol string) (models.FormattedData, error) {
+	fields, err := c.client.HGetAll(ctx, c.LatestKey(symbol)).Result()
+	if err != nil {
+		return models.FormattedData{}, err
+	}
+	if len(fields) == 0 {
+		return models.FormattedData{}, redis.Nil
+	}
+
+	return parseRedisFields(fields)
+}
+
+// GetProcessedCount returns the number of processed messages
+func (c *RedisCache) GetProcessedCount() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.processedCount
+}
+
+// GetBufferSize returns the current size of the buffer (always 0 for immediate writes)
+func (c *RedisCache) GetBufferSize() int {
+	return 0
+}
+
+// Close closes the Redis client
+func (c *RedisCache) Close() error {
+	return c.client.Close()
+}
+
+func redisFields(data models.FormattedData) map[string]interface{} {
+	return map[string]interface{}{
+		"event_time":   data.EventTime,
+		"symbol":       data.Symbol,
+		"last_price":   data.LastPrice,
+		"bid_price":    data.BidPrice,
+		"ask_price":    data.AskPrice,
+		"price_change": data.PriceChange,
+		"high_price":   data.HighPrice,
+		"low_price":    data.LowPrice,
+		"volume":       data.Volume,
+		"quote_volume": data.QuoteVolume,
+		"open_time":    data.OpenTime,
+		"close_time":   data.CloseTime,
+		"trade_count":  data.TradeCount,
+		"latency":      data.Latency,
+	}
+}
+
+func parseRedisFields(fields map[string]string) (models.FormattedData, error) {
+	var data models.FormattedData
+	var err error
+
+	parseInt := func(name string) int64 {
+		if err != nil {
+			return 0
+		}
+		var v int64
+		v, err = strconv.ParseInt(fields[name], 10, 64)
+		return v
+	}
+	parseFloat := func(name string) float64 {
+		if err != nil {
+			return 0
+		}
+		var v float64
+		v, err = strconv.ParseFloat(fields[name], 64)
+		return v
+	}
+
+	data.Symbol = fields["symbol"]
+	data.EventTime = parseInt("event_time")
+	data.LastPrice = parseFloat("last_price")
+	data.BidPrice = parseFloat("bid_price")
+	data.AskPrice = parseFloat("ask_price")
+	data.PriceChange = parseFloat("price_change")
+	data.HighPrice = parseFloat("high_price")
+	data.LowPrice = parseFloat("low_price")
+	data.Volume = parseFloat("volume")
+	data.QuoteVolume = parseFloat("quote_volume")
+	data.OpenTime = parseInt("open_time")
+	data.CloseTime = parseInt("close_time")
+	data.TradeCount = int(parseInt("trade_count"))
+	data.Latency = parseInt("latency")
+
+	if err != nil {
+		return models.FormattedData{}, fmt.Errorf("parsing cached data: %w", err)
+	}
+	return data, nil
+}
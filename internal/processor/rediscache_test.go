@@ -0,0 +1,101 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+func newTestRedisCache(t *testing.T, cfg RedisConfig) (*RedisCache, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	cache, err := NewRedisCache(client, cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = cache.Close() })
+	return cache, mr
+}
+
+func TestRedisCache_ProcessAndLatest(t *testing.T) {
+	cache, mr := newTestRedisCache(t, RedisConfig{TTL: 30 * time.Second})
+
+	data := models.FormattedData{
+		EventTime:   1625097600000,
+		Symbol:      "BTCUSDT",
+		LastPrice:   34000.5,
+		BidPrice:    34000.0,
+		AskPrice:    34001.0,
+		PriceChange: 100.0,
+		HighPrice:   34500.0,
+		LowPrice:    33500.0,
+		Volume:      100.0,
+		QuoteVolume: 3400000.0,
+		OpenTime:    1625094000000,
+		CloseTime:   1625097600000,
+		TradeCount:  1000,
+		Latency:     100,
+	}
+	cache.Process(data)
+
+	assert.Equal(t, 1, cache.GetProcessedCount())
+	assert.Equal(t, "34000.5", mr.HGet("binance:latest:btcusdt", "last_price"))
+
+	latest, err := cache.Latest(context.Background(), "btcusdt")
+	require.NoError(t, err)
+	assert.Equal(t, data, latest)
+
+	mr.FastForward(31 * time.Second)
+	_, err = cache.Latest(context.Background(), "btcusdt")
+	assert.ErrorIs(t, err, redis.Nil, "entry should expire after the TTL")
+}
+
+func TestRedisCache_Publish(t *testing.T) {
+	cache, mr := newTestRedisCache(t, RedisConfig{})
+	subscriber := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer subscriber.Close()
+
+	ctx := context.Background()
+	pubsub := subscriber.Subscribe(ctx, cache.Channel("ETHUSDT"))
+	defer pubsub.Close()
+	_, err := pubsub.Receive(ctx)
+	require.NoError(t, err)
+
+	data := models.FormattedData{EventTime: 1, Symbol: "ETHUSDT", LastPrice: 2000.0}
+	cache.Process(data)
+
+	msg, err := pubsub.ReceiveMessage(ctx)
+	require.NoError(t, err)
+
+	var received models.FormattedData
+	require.NoError(t, json.Unmarshal([]byte(msg.Payload), &received))
+	assert.Equal(t, data, received)
+}
+
+func TestRedisCache_Stream(t *testing.T) {
+	cache, mr := newTestRedisCache(t, RedisConfig{KeyPrefix: "test", StreamMaxLen: 100})
+
+	for i := 0; i < 3; i++ {
+		cache.Process(models.FormattedData{EventTime: int64(i), Symbol: "LTCUSDT"})
+	}
+
+	entries, err := mr.Stream("test:ticks")
+	require.NoError(t, err)
+	assert.Len(t, entries, 3)
+	assert.Equal(t, 0, cache.GetBufferSize())
+}
+
+func TestRedisCache_LatestMissing(t *testing.T) {
+	cache, _ := newTestRedisCache(t, RedisConfig{})
+
+	_, err := cache.Latest(context.Background(), "BTCUSDT")
+	assert.ErrorIs(t, err, redis.Nil)
+}
@@ -0,0 +1,76 @@
+package processor
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "modernc.org/sqlite"
+)
+
+const defaultSQLitePath = "ticker_data.db"
+
+// SQLiteConfig holds the settings for storing ticks in a SQLite file
+type SQLiteConfig struct {
+	// Path is the database file, created if it does not exist
+	Path string
+}
+
+const sqliteSchema = `CREATE TABLE IF NOT EXISTS ticker_data
+(
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    event_time   INTEGER NOT NULL,
+    symbol       TEXT    NOT NULL,
+    last_price   REAL,
+    price_change REAL,
+    high_price   REAL,
+    low_price    REAL,
+    volume       REAL,
+    quote_volume REAL,
+    open_time    INTEGER,
+    close_time   INTEGER,
+    trade_count  INTEGER,
+    latency      INTEGER,
+    UNIQUE (symbol, event_time)
+)`
+
+// SQLiteWriter implements DataProcessor interface for SQLite. SQLite accepts
+// the same insert as PostgreSQL, so it behaves like a PGWriter, including
+// deduplication and retries.
+type SQLiteWriter struct {
+	*PGWriter
+}
+
+// NewSQLiteWriter creates a new SQLiteWriter, creating the ticker_data table
+// if it does not exist
+func NewSQLiteWriter(db *sql.DB) (*SQLiteWriter, error) {
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		return nil, fmt.Errorf("error creating SQLite ticker_data table: %w", err)
+	}
+
+	writer, err := NewPGWriter(db)
+	if err != nil {
+		return nil, err
+	}
+	return &SQLiteWriter{PGWriter: writer}, nil
+}
+
+// OpenSQLiteWriter opens the configured database file and creates a
+// SQLiteWriter on it. Writers of several symbols may share the file: writes
+// wait for each other instead of failing.
+func OpenSQLiteWriter(cfg SQLiteConfig) (*SQLiteWriter, error) {
+	path := cfg.Path
+	if path == "" {
+		path = defaultSQLitePath
+	}
+
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("error opening SQLite database %s: %w", path, err)
+	}
+	writer, err := NewSQLiteWriter(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return writer, nil
+}
@@ -0,0 +1,48 @@
+package processor
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLiteWriter_Process(t *testing.T) {
+	writer, err := OpenSQLiteWriter(SQLiteConfig{Path: filepath.Join(t.TempDir(), "ticks.db")})
+	require.NoError(t, err)
+	defer writer.Close()
+
+	writer.Process(tick(1000, 34000, 10, 5))
+	writer.Process(tick(2000, 34100, 12, 6))
+
+	var count int
+	var lastPrice float64
+	require.NoError(t, writer.db.QueryRow(`SELECT count(*), max(last_price) FROM ticker_data`).Scan(&count, &lastPrice))
+	assert.Equal(t, 2, count)
+	assert.Equal(t, 34100.0, lastPrice)
+	assert.Equal(t, 2, writer.GetProcessedCount())
+}
+
+func TestSQLiteWriter_ProcessDeduplicates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ticks.db")
+	writer, err := OpenSQLiteWriter(SQLiteConfig{Path: path})
+	require.NoError(t, err)
+
+	writer.Process(tick(1000, 34000, 10, 5))
+	writer.Process(tick(1000, 34000, 10, 5))
+	assert.Equal(t, 1, writer.GetDedupeCacheHits())
+	require.NoError(t, writer.Close())
+
+	// A new writer does not remember the key; the unique constraint skips it
+	writer, err = OpenSQLiteWriter(SQLiteConfig{Path: path})
+	require.NoError(t, err)
+	defer writer.Close()
+	writer.Process(tick(1000, 34000, 10, 5))
+
+	var count int
+	require.NoError(t, writer.db.QueryRow(`SELECT count(*) FROM ticker_data`).Scan(&count))
+	assert.Equal(t, 1, count)
+	assert.Equal(t, 1, writer.GetDuplicateCount())
+	assert.Zero(t, writer.GetProcessedCount())
+}
@@ -0,0 +1,95 @@
+package processor
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Storage backends for ticker data
+const (
+	BackendPostgres    = "postgres"
+	BackendSQLite      = "sqlite"
+	BackendTimescaleDB = "timescaledb"
+	BackendClickHouse  = "clickhouse"
+)
+
+// StorageConfig selects and configures the backend ticks are stored in. The
+// other tables (candles, anomalies, ...) stay in PostgreSQL.
+type StorageConfig struct {
+	// Backend is postgres (the default), sqlite, timescaledb or clickhouse
+	Backend     string
+	SQLite      SQLiteConfig
+	TimescaleDB TimescaleConfig
+	ClickHouse  ClickHouseConfig
+}
+
+// TickStore is a DataProcessor storing ticks in a database. Closing it
+// releases the backend's connections.
+type TickStore interface {
+	DataProcessor
+	Close() error
+}
+
+// SharesDB reports whether ticks are stored in the PostgreSQL database
+// passed to NewTickStore: always with the postgres backend, and with
+// timescaledb when it has no dsn of its own
+func (cfg StorageConfig) SharesDB() bool {
+	switch cfg.Backend {
+	case "", BackendPostgres:
+		return true
+	case BackendTimescaleDB:
+		return cfg.TimescaleDB.DSN == ""
+	default:
+		return false
+	}
+}
+
+// NewTickStore creates a store for the configured backend. db is the
+// PostgreSQL connection used by the backends sharing it (see SharesDB),
+// which leave it open on Close; the other backends open their own
+// connections.
+func NewTickStore(cfg StorageConfig, db *sql.DB) (TickStore, error) {
+	switch cfg.Backend {
+	case "", BackendPostgres:
+		writer, err := NewPGWriter(db)
+		if err != nil {
+			return nil, err
+		}
+		// db belongs to the caller, who closes it after the store
+		writer.borrowed = true
+		return writer, nil
+	case BackendSQLite:
+		writer, err := OpenSQLiteWriter(cfg.SQLite)
+		if err != nil {
+			return nil, err
+		}
+		return writer, nil
+	case BackendTimescaleDB:
+		if cfg.TimescaleDB.DSN != "" {
+			writer, err := OpenTimescaleWriter(cfg.TimescaleDB)
+			if err != nil {
+				return nil, err
+			}
+			return writer, nil
+		}
+		if db == nil {
+			return nil, errors.New("timescaledb storage needs a dsn or a db")
+		}
+		writer, err := NewTimescaleWriter(db, cfg.TimescaleDB)
+		if err != nil {
+			return nil, err
+		}
+		writer.borrowed = true
+		return writer, nil
+	case BackendClickHouse:
+		writer, err := NewClickHouseWriter(&http.Client{Timeout: defaultClickHouseTimeout}, cfg.ClickHouse)
+		if err != nil {
+			return nil, err
+		}
+		return writer, nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Backend)
+	}
+}
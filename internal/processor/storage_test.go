@@ -0,0 +1,62 @@
+package processor
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTickStore(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	store, err := NewTickStore(StorageConfig{}, db)
+	require.NoError(t, err)
+	assert.IsType(t, &PGWriter{}, store)
+	// The connection is the caller's; closing it here would be unexpected
+	assert.NoError(t, store.Close())
+
+	store, err = NewTickStore(StorageConfig{
+		Backend: BackendSQLite,
+		SQLite:  SQLiteConfig{Path: filepath.Join(t.TempDir(), "ticks.db")},
+	}, db)
+	require.NoError(t, err)
+	assert.IsType(t, &SQLiteWriter{}, store)
+	assert.NoError(t, store.Close())
+}
+
+func TestNewTickStore_TimescaleSharesDB(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	cfg := StorageConfig{Backend: BackendTimescaleDB, TimescaleDB: TimescaleConfig{ChunkInterval: time.Hour}}
+	assert.True(t, cfg.SharesDB())
+	expectHypertableSetup(mock, true)
+	store, err := NewTickStore(cfg, db)
+	require.NoError(t, err)
+	assert.IsType(t, &TimescaleWriter{}, store)
+	// db stays open for the other tables
+	assert.NoError(t, store.Close())
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	cfg.TimescaleDB.DSN = "postgres://localhost/ticks"
+	assert.False(t, cfg.SharesDB())
+	assert.False(t, StorageConfig{Backend: BackendSQLite}.SharesDB())
+	assert.True(t, StorageConfig{}.SharesDB())
+}
+
+func TestNewTickStore_Errors(t *testing.T) {
+	store, err := NewTickStore(StorageConfig{Backend: "mongodb"}, nil)
+	assert.EqualError(t, err, `unknown storage backend "mongodb"`)
+	assert.Nil(t, store)
+
+	store, err = NewTickStore(StorageConfig{Backend: BackendTimescaleDB}, nil)
+	assert.Error(t, err)
+	assert.Nil(t, store)
+}
@@ -0,0 +1,149 @@
+package processor
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	_ "github.com/lib/pq"
+)
+
+const (
+	defaultChunkInterval = 24 * time.Hour
+	defaultCompressAfter = 7 * 24 * time.Hour
+	// timescaleLockID serialises the schema setup of writers starting together
+	timescaleLockID = 7264839202
+)
+
+// TimescaleConfig holds the settings for storing ticks in a TimescaleDB
+// hypertable
+type TimescaleConfig struct {
+	// DSN is the connection string of a separate TimescaleDB database. When
+	// empty the hypertable is created in db, which must not have a
+	// ticker_data table yet.
+	DSN string
+	// ChunkInterval is the event time range of each hypertable chunk
+	ChunkInterval time.Duration `mapstructure:"chunk_interval"`
+	// CompressAfter is how old chunks are before they are compressed
+	CompressAfter time.Duration `mapstructure:"compress_after"`
+}
+
+const timescaleSchema = `CREATE TABLE IF NOT EXISTS ticker_data
+(
+    id           BIGSERIAL,
+    event_time   BIGINT NOT NULL,
+    symbol       TEXT   NOT NULL,
+    last_price   DOUBLE PRECISION,
+    price_change DOUBLE PRECISION,
+    high_price   DOUBLE PRECISION,
+    low_price    DOUBLE PRECISION,
+    volume       DOUBLE PRECISION,
+    quote_volume DOUBLE PRECISION,
+    open_time    BIGINT,
+    close_time   BIGINT,
+    trade_count  INT,
+    latency      BIGINT,
+    UNIQUE (symbol, event_time)
+)`
+
+// event_time is in milliseconds, so policies need to know the current time
+// in the same unit
+const timescaleNowFunc = `CREATE OR REPLACE FUNCTION ticker_data_now() RETURNS BIGINT
+    LANGUAGE SQL STABLE AS $$ SELECT (extract(epoch FROM now()) * 1000)::BIGINT $$`
+
+// TimescaleWriter implements DataProcessor interface for TimescaleDB. Ticks
+// are inserted like with a PGWriter into a hypertable chunked by event time,
+// whose older chunks are compressed by a background policy.
+type TimescaleWriter struct {
+	*PGWriter
+}
+
+// NewTimescaleWriter creates a new TimescaleWriter, creating the ticker_data
+// hypertable and its compression policy if they do not exist
+func NewTimescaleWriter(db *sql.DB, cfg TimescaleConfig) (*TimescaleWriter, error) {
+	if cfg.ChunkInterval <= 0 {
+		cfg.ChunkInterval = defaultChunkInterval
+	}
+	if cfg.CompressAfter <= 0 {
+		cfg.CompressAfter = defaultCompressAfter
+	}
+
+	if err := setupHypertable(context.Background(), db, cfg); err != nil {
+		return nil, fmt.Errorf("error creating TimescaleDB hypertable: %w", err)
+	}
+
+	writer, err := NewPGWriter(db)
+	if err != nil {
+		return nil, err
+	}
+	return &TimescaleWriter{PGWriter: writer}, nil
+}
+
+// OpenTimescaleWriter connects to the configured database and creates a
+// TimescaleWriter on it
+func OpenTimescaleWriter(cfg TimescaleConfig) (*TimescaleWriter, error) {
+	if cfg.DSN == "" {
+		return nil, errors.New("timescaledb storage needs a dsn")
+	}
+
+	db, err := sql.Open("postgres", cfg.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to TimescaleDB: %w", err)
+	}
+	writer, err := NewTimescaleWriter(db, cfg)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return writer, nil
+}
+
+func setupHypertable(ctx context.Context, db *sql.DB, cfg TimescaleConfig) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, timescaleLockID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `CREATE EXTENSION IF NOT EXISTS timescaledb`); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, timescaleSchema); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `SELECT create_hypertable('ticker_data', 'event_time',
+        chunk_time_interval => $1::BIGINT, if_not_exists => TRUE)`, cfg.ChunkInterval.Milliseconds()); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, timescaleNowFunc); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `SELECT set_integer_now_func('ticker_data', 'ticker_data_now', replace_if_exists => TRUE)`); err != nil {
+		return err
+	}
+
+	// Compression settings cannot change once chunks are compressed
+	var compressed bool
+	err = tx.QueryRowContext(ctx, `SELECT compression_enabled FROM timescaledb_information.hypertables
+        WHERE hypertable_name = 'ticker_data'`).Scan(&compressed)
+	if err != nil {
+		return err
+	}
+	if !compressed {
+		if _, err := tx.ExecContext(ctx, `ALTER TABLE ticker_data SET (timescaledb.compress,
+            timescaledb.compress_segmentby = 'symbol', timescaledb.compress_orderby = 'event_time DESC')`); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `SELECT add_compression_policy('ticker_data',
+        compress_after => $1::BIGINT, if_not_exists => TRUE)`, cfg.CompressAfter.Milliseconds()); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,70 @@
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func expectHypertableSetup(mock sqlmock.Sqlmock, compressed bool) {
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT pg_advisory_xact_lock`).WithArgs(timescaleLockID).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`CREATE EXTENSION IF NOT EXISTS timescaledb`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS ticker_data`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`SELECT create_hypertable\('ticker_data', 'event_time'`).
+		WithArgs(time.Hour.Milliseconds()).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`CREATE OR REPLACE FUNCTION ticker_data_now`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`SELECT set_integer_now_func\('ticker_data', 'ticker_data_now'`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT compression_enabled FROM timescaledb_information.hypertables`).
+		WillReturnRows(sqlmock.NewRows([]string{"compression_enabled"}).AddRow(compressed))
+	if !compressed {
+		mock.ExpectExec(`ALTER TABLE ticker_data SET \(timescaledb.compress`).WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+	mock.ExpectExec(`SELECT add_compression_policy\('ticker_data'`).
+		WithArgs(defaultCompressAfter.Milliseconds()).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+}
+
+func TestNewTimescaleWriter(t *testing.T) {
+	for _, compressed := range []bool{false, true} {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+
+		expectHypertableSetup(mock, compressed)
+		writer, err := NewTimescaleWriter(db, TimescaleConfig{ChunkInterval: time.Hour})
+		require.NoError(t, err)
+
+		data := tick(1000, 34000, 10, 5)
+		mock.ExpectExec(`INSERT INTO ticker_data .* ON CONFLICT \(symbol, event_time\) DO NOTHING`).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		writer.Process(data)
+		assert.Equal(t, 1, writer.GetProcessedCount())
+
+		mock.ExpectClose()
+		require.NoError(t, writer.Close())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestNewTimescaleWriter_SetupError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT pg_advisory_xact_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`CREATE EXTENSION IF NOT EXISTS timescaledb`).WillReturnError(assert.AnError)
+	mock.ExpectRollback()
+
+	_, err = NewTimescaleWriter(db, TimescaleConfig{})
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOpenTimescaleWriter_RequiresDSN(t *testing.T) {
+	_, err := OpenTimescaleWriter(TimescaleConfig{})
+	assert.Error(t, err)
+}
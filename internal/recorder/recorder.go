@@ -0,0 +1,183 @@
+package recorder
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	defaultSegmentDuration = time.Hour
+	defaultFlushInterval   = time.Second
+	segmentPrefix          = "frames-"
+	segmentSuffix          = ".bin.gz"
+	headerSize             = 12
+)
+
+// Config holds the raw frame recorder settings
+type Config struct {
+	Enabled bool
+	// Dir is the directory segment files are written to
+	Dir string
+	// SegmentDuration is how long a segment file is written before rotating
+	SegmentDuration time.Duration `mapstructure:"segment_duration"`
+	// FlushInterval is how often buffered frames are written out, which
+	// bounds what a crash loses
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+}
+
+// Recorder writes raw websocket frames with their receive time to gzip
+// compressed segment files. Each record is an 8 byte big-endian receive time
+// in Unix nanoseconds, a 4 byte frame length and the frame bytes, so frames
+// that are not valid JSON are kept as received. Segment names sort in time
+// order.
+type Recorder struct {
+	dir             string
+	segmentDuration time.Duration
+	flushInterval   time.Duration
+	file            *os.File
+	gzip            *gzip.Writer
+	buf             *bufio.Writer
+	segmentStart    time.Time
+	mutex           sync.Mutex
+	recordedCount   int
+}
+
+// NewRecorder creates a new Recorder, creating the directory if needed
+func NewRecorder(cfg Config) (*Recorder, error) {
+	if cfg.Dir == "" {
+		return nil, fmt.Errorf("recorder directory is not set")
+	}
+	if cfg.SegmentDuration <= 0 {
+		cfg.SegmentDuration = defaultSegmentDuration
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = defaultFlushInterval
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("error creating recorder directory: %w", err)
+	}
+
+	return &Recorder{
+		dir:             cfg.Dir,
+		segmentDuration: cfg.SegmentDuration,
+		flushInterval:   cfg.FlushInterval,
+	}, nil
+}
+
+// RecordFrame appends a raw frame to the current segment
+func (r *Recorder) RecordFrame(receivedAt time.Time, frame []byte) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.file == nil || receivedAt.Sub(r.segmentStart) >= r.segmentDuration {
+		if err := r.rotate(receivedAt); err != nil {
+			log.Printf("Error rotating recorder segment: %v", err)
+			return
+		}
+	}
+
+	var header [headerSize]byte
+	binary.BigEndian.PutUint64(header[:8], uint64(receivedAt.UnixNano()))
+	binary.BigEndian.PutUint32(header[8:], uint32(len(frame)))
+	if _, err := r.buf.Write(header[:]); err != nil {
+		log.Printf("Error recording frame: %v", err)
+		return
+	}
+	if _, err := r.buf.Write(frame); err != nil {
+		log.Printf("Error recording frame: %v", err)
+		return
+	}
+	r.recordedCount++
+}
+
+// rotate closes the current segment and opens a new one. The caller must hold
+// the mutex.
+func (r *Recorder) rotate(start time.Time) error {
+	if err := r.closeSegment(); err != nil {
+		return err
+	}
+
+	name := filepath.Join(r.dir, fmt.Sprintf("%s%020d%s", segmentPrefix, start.UnixNano(), segmentSuffix))
+	file, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	r.file = file
+	r.gzip = gzip.NewWriter(file)
+	r.buf = bufio.NewWriter(r.gzip)
+	r.segmentStart = start
+	return nil
+}
+
+// closeSegment flushes and closes the current segment. The caller must hold
+// the mutex.
+func (r *Recorder) closeSegment() error {
+	if r.file == nil {
+		return nil
+	}
+	defer func() { r.file, r.gzip, r.buf = nil, nil, nil }()
+
+	if err := r.buf.Flush(); err != nil {
+		r.file.Close()
+		return err
+	}
+	if err := r.gzip.Close(); err != nil {
+		r.file.Close()
+		return err
+	}
+	return r.file.Close()
+}
+
+// Flush writes the buffered frames of the current segment to its file as a
+// complete gzip block, so they can be replayed even if the process dies
+// before the segment is closed
+func (r *Recorder) Flush() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.file == nil {
+		return nil
+	}
+	if err := r.buf.Flush(); err != nil {
+		return err
+	}
+	return r.gzip.Flush()
+}
+
+// Run flushes the current segment every FlushInterval until stop is closed
+func (r *Recorder) Run(stop chan struct{}) {
+	ticker := time.NewTicker(r.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := r.Flush(); err != nil {
+				log.Printf("Error flushing recorder segment: %v", err)
+			}
+		}
+	}
+}
+
+// GetRecordedCount returns the number of recorded frames
+func (r *Recorder) GetRecordedCount() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.recordedCount
+}
+
+// Close flushes and closes the current segment
+func (r *Recorder) Close() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.closeSegment()
+}
@@ -0,0 +1,154 @@
+package recorder
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func record(t *testing.T, dir string, segment time.Duration, frames map[time.Duration]string) {
+	t.Helper()
+	rec, err := NewRecorder(Config{Dir: dir, SegmentDuration: segment})
+	require.NoError(t, err)
+
+	start := time.Unix(1700000000, 0)
+	offsets := []time.Duration{0, 10 * time.Millisecond, 20 * time.Millisecond, 2 * time.Second}
+	for _, offset := range offsets {
+		if frame, ok := frames[offset]; ok {
+			rec.RecordFrame(start.Add(offset), []byte(frame))
+		}
+	}
+	assert.Equal(t, len(frames), rec.GetRecordedCount())
+	require.NoError(t, rec.Close())
+}
+
+func TestRecorder_RotatesAndReadsBack(t *testing.T) {
+	dir := t.TempDir()
+	record(t, dir, time.Second, map[time.Duration]string{
+		0:                     `{"s":"BTCUSDT"}`,
+		10 * time.Millisecond: `not json`,
+		2 * time.Second:       `{"s":"ETHUSDT"}`,
+	})
+
+	segments, err := Segments(dir)
+	require.NoError(t, err)
+	require.Len(t, segments, 2)
+
+	var frames []Frame
+	for _, segment := range segments {
+		require.NoError(t, ReadSegment(segment, func(f Frame) error {
+			frames = append(frames, f)
+			return nil
+		}))
+	}
+	require.Len(t, frames, 3)
+	assert.Equal(t, "not json", string(frames[1].Data))
+	assert.Equal(t, int64(1700000002000), frames[2].ReceivedAt.UnixMilli())
+}
+
+func TestReadSegment_Truncated(t *testing.T) {
+	dir := t.TempDir()
+	rec, err := NewRecorder(Config{Dir: dir})
+	require.NoError(t, err)
+	rec.RecordFrame(time.Unix(1700000000, 0), []byte(`{"s":"BTCUSDT"}`))
+	rec.RecordFrame(time.Unix(1700000001, 0), []byte(`{"s":"ETHUSDT"}`))
+
+	// Append a partial header to simulate a crash in the middle of a frame
+	rec.mutex.Lock()
+	_, err = rec.buf.Write([]byte{0, 0, 0})
+	rec.mutex.Unlock()
+	require.NoError(t, err)
+	require.NoError(t, rec.Close())
+
+	segments, err := Segments(dir)
+	require.NoError(t, err)
+	require.Len(t, segments, 1)
+
+	var count int
+	assert.NoError(t, ReadSegment(segments[0], func(f Frame) error {
+		count++
+		return nil
+	}))
+	assert.Equal(t, 2, count)
+}
+
+func TestRecorder_FlushSurvivesCrash(t *testing.T) {
+	dir := t.TempDir()
+	rec, err := NewRecorder(Config{Dir: dir})
+	require.NoError(t, err)
+	rec.RecordFrame(time.Unix(1700000000, 0), []byte(`{"s":"BTCUSDT"}`))
+	require.NoError(t, rec.Flush())
+	rec.RecordFrame(time.Unix(1700000001, 0), []byte(`{"s":"ETHUSDT"}`))
+
+	// The segment is read without being closed, as after a crash, and only
+	// the unflushed frame is lost
+	segments, err := Segments(dir)
+	require.NoError(t, err)
+	require.Len(t, segments, 1)
+	var frames []string
+	assert.NoError(t, ReadSegment(segments[0], func(f Frame) error {
+		frames = append(frames, string(f.Data))
+		return nil
+	}))
+	assert.Equal(t, []string{`{"s":"BTCUSDT"}`}, frames)
+	require.NoError(t, rec.Close())
+}
+
+func TestReplay_SkipsEmptySegment(t *testing.T) {
+	dir := t.TempDir()
+	record(t, dir, time.Hour, map[time.Duration]string{0: `a`})
+	// A segment created just before a crash has nothing in it yet
+	require.NoError(t, os.WriteFile(filepath.Join(dir, segmentPrefix+"99999999999999999999"+segmentSuffix), nil, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, segmentPrefix+"99999999999999999998"+segmentSuffix), []byte{0x1f, 0x8b}, 0o644))
+
+	var replayed []string
+	count, err := Replay(dir, 0, make(chan struct{}), func(frame []byte) {
+		replayed = append(replayed, string(frame))
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, []string{"a"}, replayed)
+}
+
+func TestReplay(t *testing.T) {
+	dir := t.TempDir()
+	record(t, dir, time.Hour, map[time.Duration]string{
+		0:                     `a`,
+		10 * time.Millisecond: `b`,
+		20 * time.Millisecond: `c`,
+	})
+
+	var replayed []string
+	count, err := Replay(dir, 0, make(chan struct{}), func(frame []byte) {
+		replayed = append(replayed, string(frame))
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+	assert.Equal(t, []string{"a", "b", "c"}, replayed)
+
+	// At original speed the 20ms of recording takes at least 20ms to replay
+	started := time.Now()
+	_, err = Replay(dir, 1, make(chan struct{}), func([]byte) {})
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(started), 20*time.Millisecond)
+}
+
+func TestReplay_Stop(t *testing.T) {
+	dir := t.TempDir()
+	record(t, dir, time.Hour, map[time.Duration]string{
+		0:               `a`,
+		2 * time.Second: `b`,
+	})
+
+	stop := make(chan struct{})
+	count, err := Replay(dir, 1, stop, func([]byte) { close(stop) })
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	_, err = Replay(t.TempDir(), 0, make(chan struct{}), func([]byte) {})
+	assert.Error(t, err)
+}
@@ -0,0 +1,155 @@
+package recorder
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Frame is a recorded raw websocket frame
+type Frame struct {
+	ReceivedAt time.Time
+	Data       []byte
+}
+
+// Segments returns the segment files in a directory in recording order
+func Segments(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var segments []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && strings.HasPrefix(name, segmentPrefix) && strings.HasSuffix(name, segmentSuffix) {
+			segments = append(segments, filepath.Join(dir, name))
+		}
+	}
+	sort.Strings(segments)
+	return segments, nil
+}
+
+// ReadSegment calls fn for every frame in a segment file. A segment cut short
+// by a crash is read up to its last complete frame, and one left empty is
+// skipped.
+func ReadSegment(path string, fn func(Frame) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		// Nothing reached the file before a crash
+		log.Printf("Segment %s is empty or has no complete gzip header, skipping it", path)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error opening segment %s: %w", path, err)
+	}
+	defer gz.Close()
+	reader := bufio.NewReader(gz)
+
+	var header [headerSize]byte
+	for {
+		if _, err := io.ReadFull(reader, header[:]); err != nil {
+			return truncated(path, err)
+		}
+		frame := Frame{
+			ReceivedAt: time.Unix(0, int64(binary.BigEndian.Uint64(header[:8]))),
+			Data:       make([]byte, binary.BigEndian.Uint32(header[8:])),
+		}
+		if _, err := io.ReadFull(reader, frame.Data); err != nil {
+			return truncated(path, err)
+		}
+		if err := fn(frame); err != nil {
+			return err
+		}
+	}
+}
+
+// truncated turns the end of a segment into nil, logging segments that end
+// mid-frame
+func truncated(path string, err error) error {
+	if err == io.EOF {
+		return nil
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		log.Printf("Segment %s is truncated, replaying up to its last complete frame", path)
+		return nil
+	}
+	return err
+}
+
+// errStopped ends a replay early
+var errStopped = errors.New("replay stopped")
+
+// Replay feeds the data of every recorded frame in dir to handle in
+// recording order. See ReplayFrames for speed.
+func Replay(dir string, speed float64, stop chan struct{}, handle func([]byte)) (int, error) {
+	return ReplayFrames(dir, speed, stop, func(frame Frame) { handle(frame.Data) })
+}
+
+// ReplayFrames feeds every recorded frame in dir to handle in recording
+// order, with the time it was originally received. A speed of 1 reproduces
+// the original timing, higher values replay faster and 0 replays as fast as
+// possible. It returns the number of frames replayed.
+func ReplayFrames(dir string, speed float64, stop chan struct{}, handle func(Frame)) (int, error) {
+	segments, err := Segments(dir)
+	if err != nil {
+		return 0, err
+	}
+	if len(segments) == 0 {
+		return 0, fmt.Errorf("no recorded segments in %s", dir)
+	}
+
+	var count int
+	var first time.Time
+	started := time.Now()
+
+	for _, segment := range segments {
+		err := ReadSegment(segment, func(frame Frame) error {
+			if first.IsZero() {
+				first = frame.ReceivedAt
+			}
+			if speed > 0 {
+				due := started.Add(time.Duration(float64(frame.ReceivedAt.Sub(first)) / speed))
+				if wait := time.Until(due); wait > 0 {
+					select {
+					case <-stop:
+						return errStopped
+					case <-time.After(wait):
+					}
+				}
+			}
+			select {
+			case <-stop:
+				return errStopped
+			default:
+			}
+
+			handle(frame)
+			count++
+			return nil
+		})
+		if errors.Is(err, errStopped) {
+			return count, nil
+		}
+		if err != nil {
+			return count, err
+		}
+	}
+
+	return count, nil
+}
@@ -0,0 +1,271 @@
+package rollup
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+const (
+	defaultRunInterval = time.Minute
+	defaultLag         = time.Minute
+	// chunkBuckets is how many buckets are rolled up per transaction
+	chunkBuckets = 1440
+)
+
+// Config holds the rollup settings
+type Config struct {
+	Enabled bool
+	// RunInterval is how often the rollups are brought up to date
+	RunInterval time.Duration `mapstructure:"run_interval"`
+	// Lag is how long after a bucket ends it is rolled up, giving late ticks
+	// time to arrive
+	Lag time.Duration
+}
+
+// Job rolls one source table up into buckets of Width
+type Job struct {
+	Name   string
+	Table  string
+	Width  time.Duration
+	source string
+	// upstream is the job whose watermark limits this one; empty for the
+	// job reading raw ticks
+	upstream string
+}
+
+// Jobs are the rollups in the order they run. Each job reads the table of
+// the one before, so hours and days survive the retention of raw ticks.
+var Jobs = []Job{
+	{Name: "1m", Table: "ticker_rollups_1m", Width: time.Minute, source: "ticker_data"},
+	{Name: "1h", Table: "ticker_rollups_1h", Width: time.Hour, source: "ticker_rollups_1m", upstream: "1m"},
+	{Name: "1d", Table: "ticker_rollups_1d", Width: 24 * time.Hour, source: "ticker_rollups_1h", upstream: "1h"},
+}
+
+// rawSelect aggregates ticks into buckets. The first and last values of a
+// bucket are taken in event time order.
+const rawSelect = `SELECT symbol, event_time / %[1]d * %[1]d AS bucket,
+        (array_agg(last_price ORDER BY event_time, id))[1],
+        max(last_price),
+        min(last_price),
+        (array_agg(last_price ORDER BY event_time DESC, id DESC))[1],
+        (array_agg(volume ORDER BY event_time, id))[1],
+        (array_agg(volume ORDER BY event_time DESC, id DESC))[1],
+        (array_agg(quote_volume ORDER BY event_time, id))[1],
+        (array_agg(quote_volume ORDER BY event_time DESC, id DESC))[1],
+        avg(latency),
+        count(*)
+    FROM ticker_data
+    WHERE event_time >= $1 AND event_time < $2 AND symbol IS NOT NULL
+    GROUP BY symbol, bucket`
+
+// rawEarliest finds the oldest tick through the (symbol, event_time) index,
+// one symbol at a time, instead of scanning ticker_data for min(event_time)
+const rawEarliest = `WITH RECURSIVE symbols AS (
+        (SELECT symbol FROM ticker_data WHERE symbol IS NOT NULL ORDER BY symbol LIMIT 1)
+        UNION ALL
+        SELECT (SELECT t.symbol FROM ticker_data t WHERE t.symbol > s.symbol ORDER BY t.symbol LIMIT 1)
+        FROM symbols s
+        WHERE s.symbol IS NOT NULL
+    )
+    SELECT min((SELECT min(t.event_time) FROM ticker_data t WHERE t.symbol = s.symbol))
+    FROM symbols s
+    WHERE s.symbol IS NOT NULL`
+
+// rollupSelect merges finer rollups into coarser buckets
+const rollupSelect = `SELECT symbol, bucket_start / %[1]d * %[1]d AS bucket,
+        (array_agg(open ORDER BY bucket_start))[1],
+        max(high),
+        min(low),
+        (array_agg(close ORDER BY bucket_start DESC))[1],
+        (array_agg(open_volume ORDER BY bucket_start))[1],
+        (array_agg(close_volume ORDER BY bucket_start DESC))[1],
+        (array_agg(open_quote_volume ORDER BY bucket_start))[1],
+        (array_agg(close_quote_volume ORDER BY bucket_start DESC))[1],
+        sum(avg_latency * message_count) / sum(message_count),
+        sum(message_count)
+    FROM %[2]s
+    WHERE bucket_start >= $1 AND bucket_start < $2
+    GROUP BY symbol, bucket`
+
+// upsert makes re-running a range overwrite its buckets with the same values
+const upsert = `INSERT INTO %s (
+        symbol, bucket_start, open, high, low, close, open_volume, close_volume,
+        open_quote_volume, close_quote_volume, avg_latency, message_count
+    ) %s
+    ON CONFLICT (symbol, bucket_start) DO UPDATE SET
+        open = EXCLUDED.open,
+        high = EXCLUDED.high,
+        low = EXCLUDED.low,
+        close = EXCLUDED.close,
+        open_volume = EXCLUDED.open_volume,
+        close_volume = EXCLUDED.close_volume,
+        open_quote_volume = EXCLUDED.open_quote_volume,
+        close_quote_volume = EXCLUDED.close_quote_volume,
+        avg_latency = EXCLUDED.avg_latency,
+        message_count = EXCLUDED.message_count`
+
+func (j Job) query() string {
+	width := j.Width.Milliseconds()
+	var selectSQL string
+	if j.upstream == "" {
+		selectSQL = fmt.Sprintf(rawSelect, width)
+	} else {
+		selectSQL = fmt.Sprintf(rollupSelect, width, j.source)
+	}
+	return fmt.Sprintf(upsert, j.Table, selectSQL)
+}
+
+// Scheduler periodically brings every rollup up to date, resuming each from
+// its checkpoint in rollup_checkpoints
+type Scheduler struct {
+	db  *sql.DB
+	cfg Config
+}
+
+// NewScheduler creates a new Scheduler
+func NewScheduler(db *sql.DB, cfg Config) *Scheduler {
+	if cfg.RunInterval <= 0 {
+		cfg.RunInterval = defaultRunInterval
+	}
+	if cfg.Lag <= 0 {
+		cfg.Lag = defaultLag
+	}
+	return &Scheduler{db: db, cfg: cfg}
+}
+
+// Watermark returns the checkpoint of a rollup, or false if it has not run
+func (s *Scheduler) Watermark(ctx context.Context, name string) (int64, bool, error) {
+	var watermark int64
+	err := s.db.QueryRowContext(ctx, `SELECT watermark FROM rollup_checkpoints WHERE name = $1`, name).Scan(&watermark)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("error reading rollup checkpoint %s: %w", name, err)
+	}
+	return watermark, true, nil
+}
+
+// RawWatermark returns the event time before which every raw tick has been
+// rolled up, so that retention can drop older ticks
+func (s *Scheduler) RawWatermark(ctx context.Context) (int64, error) {
+	watermark, _, err := s.Watermark(ctx, Jobs[0].Name)
+	return watermark, err
+}
+
+// RunOnce rolls up every complete bucket ending at least Lag before now
+func (s *Scheduler) RunOnce(ctx context.Context, now time.Time) error {
+	for _, job := range Jobs {
+		if err := s.runJob(ctx, job, now); err != nil {
+			return fmt.Errorf("error running %s rollup: %w", job.Name, err)
+		}
+	}
+	return nil
+}
+
+func (s *Scheduler) runJob(ctx context.Context, job Job, now time.Time) error {
+	width := job.Width.Milliseconds()
+
+	end := now.Add(-s.cfg.Lag).UnixMilli() / width * width
+	if job.upstream != "" {
+		upstream, ok, err := s.Watermark(ctx, job.upstream)
+		if err != nil || !ok {
+			return err
+		}
+		end = min(end, upstream/width*width)
+	}
+
+	start, ok, err := s.Watermark(ctx, job.Name)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if start, ok, err = s.earliest(ctx, job); err != nil || !ok {
+			return err
+		}
+	}
+
+	query := job.query()
+	for start < end {
+		chunkEnd := min(start+chunkBuckets*width, end)
+		if err := s.rollup(ctx, job, query, start, chunkEnd); err != nil {
+			return err
+		}
+		start = chunkEnd
+	}
+	return nil
+}
+
+// earliest returns the start of the bucket holding the job's oldest source
+// row, or false if the source is empty
+func (s *Scheduler) earliest(ctx context.Context, job Job) (int64, bool, error) {
+	query := rawEarliest
+	if job.upstream != "" {
+		query = fmt.Sprintf(`SELECT min(bucket_start) FROM %s`, job.source)
+	}
+
+	var oldest sql.NullInt64
+	err := s.db.QueryRowContext(ctx, query).Scan(&oldest)
+	if err != nil {
+		return 0, false, fmt.Errorf("error finding oldest row of %s: %w", job.source, err)
+	}
+	if !oldest.Valid {
+		return 0, false, nil
+	}
+	width := job.Width.Milliseconds()
+	return oldest.Int64 / width * width, true, nil
+}
+
+// rollup aggregates [start, end) and advances the checkpoint in one
+// transaction, so an interrupted run resumes where it stopped
+func (s *Scheduler) rollup(ctx context.Context, job Job, query string, start, end int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, query, start, end); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO rollup_checkpoints (name, watermark, updated_at)
+        VALUES ($1, $2, now())
+        ON CONFLICT (name) DO UPDATE SET watermark = EXCLUDED.watermark, updated_at = EXCLUDED.updated_at`,
+		job.Name, end)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Run brings the rollups up to date every RunInterval until stop is closed,
+// which also cancels a run in progress
+func (s *Scheduler) Run(stop chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	ticker := time.NewTicker(s.cfg.RunInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			if err := s.RunOnce(ctx, now); err != nil {
+				log.Printf("Error rolling up ticker data: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package rollup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ms(hour, minute int) int64 {
+	return time.Date(2024, 1, 1, hour, minute, 0, 0, time.UTC).UnixMilli()
+}
+
+func expectWatermark(mock sqlmock.Sqlmock, name string, watermark int64, ok bool) {
+	rows := sqlmock.NewRows([]string{"watermark"})
+	if ok {
+		rows.AddRow(watermark)
+	}
+	mock.ExpectQuery(`SELECT watermark FROM rollup_checkpoints WHERE name = \$1`).WithArgs(name).WillReturnRows(rows)
+}
+
+func expectEarliest(mock sqlmock.Sqlmock, column, table string, oldest interface{}) {
+	query := `SELECT min\(` + column + `\) FROM ` + table
+	if table == "ticker_data" {
+		// Raw ticks are searched per symbol through the index
+		query = `WITH RECURSIVE symbols AS .* SELECT min\(\(SELECT min\(t.event_time\) FROM ticker_data t WHERE t.symbol = s.symbol\)\)`
+	}
+	mock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows([]string{"min"}).AddRow(oldest))
+}
+
+func expectRollup(mock sqlmock.Sqlmock, job Job, start, end int64) {
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO `+job.Table+` .* FROM `+job.source).
+		WithArgs(start, end).WillReturnResult(sqlmock.NewResult(0, 10))
+	mock.ExpectExec(`INSERT INTO rollup_checkpoints`).
+		WithArgs(job.Name, end).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+}
+
+func TestJob_Query(t *testing.T) {
+	raw := Jobs[0].query()
+	assert.Contains(t, raw, "INSERT INTO ticker_rollups_1m")
+	assert.Contains(t, raw, "event_time / 60000 * 60000 AS bucket")
+	assert.Contains(t, raw, "FROM ticker_data")
+
+	daily := Jobs[2].query()
+	assert.Contains(t, daily, "bucket_start / 86400000 * 86400000 AS bucket")
+	assert.Contains(t, daily, "FROM ticker_rollups_1h")
+	assert.Contains(t, daily, "ON CONFLICT (symbol, bucket_start) DO UPDATE")
+}
+
+func TestScheduler_RunOnceFromScratch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	scheduler := NewScheduler(db, Config{Lag: time.Minute})
+	now := time.Date(2024, 1, 1, 2, 30, 30, 0, time.UTC)
+
+	// Minutes from the oldest tick up to the last minute ending before now - lag
+	expectWatermark(mock, "1m", 0, false)
+	expectEarliest(mock, "event_time", "ticker_data", ms(0, 0)+10000)
+	expectRollup(mock, Jobs[0], ms(0, 0), ms(2, 29))
+
+	// Hours only up to the last hour fully covered by minutes
+	expectWatermark(mock, "1m", ms(2, 29), true)
+	expectWatermark(mock, "1h", 0, false)
+	expectEarliest(mock, "bucket_start", "ticker_rollups_1m", ms(0, 0))
+	expectRollup(mock, Jobs[1], ms(0, 0), ms(2, 0))
+
+	// No complete day yet
+	expectWatermark(mock, "1h", ms(2, 0), true)
+	expectWatermark(mock, "1d", 0, false)
+	expectEarliest(mock, "bucket_start", "ticker_rollups_1h", ms(0, 0))
+
+	require.NoError(t, scheduler.RunOnce(context.Background(), now))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestScheduler_RunOnceResumesInChunks(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	scheduler := NewScheduler(db, Config{Lag: time.Minute})
+	now := time.Date(2024, 1, 3, 0, 1, 0, 0, time.UTC)
+	end := now.Add(-time.Minute).UnixMilli()
+	checkpoint := end - 2000*time.Minute.Milliseconds()
+	middle := checkpoint + chunkBuckets*time.Minute.Milliseconds()
+
+	expectWatermark(mock, "1m", checkpoint, true)
+	expectRollup(mock, Jobs[0], checkpoint, middle)
+	expectRollup(mock, Jobs[0], middle, end)
+
+	// Later rollups are already up to date
+	expectWatermark(mock, "1m", end, true)
+	expectWatermark(mock, "1h", end, true)
+	expectWatermark(mock, "1h", end, true)
+	expectWatermark(mock, "1d", end, true)
+
+	require.NoError(t, scheduler.RunOnce(context.Background(), now))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestScheduler_RunOnceEmptySource(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	scheduler := NewScheduler(db, Config{})
+
+	expectWatermark(mock, "1m", 0, false)
+	expectEarliest(mock, "event_time", "ticker_data", nil)
+	expectWatermark(mock, "1m", 0, false)
+	expectWatermark(mock, "1h", 0, false)
+
+	require.NoError(t, scheduler.RunOnce(context.Background(), time.Now()))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestScheduler_RunStopCancelsRun(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	scheduler := NewScheduler(db, Config{RunInterval: 10 * time.Millisecond})
+
+	// The first run blocks on the database until stop cancels it
+	mock.ExpectQuery(`SELECT watermark FROM rollup_checkpoints`).WillDelayFor(time.Minute).
+		WillReturnRows(sqlmock.NewRows([]string{"watermark"}))
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		scheduler.Run(stop)
+		close(done)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	close(stop)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after stop was closed")
+	}
+}
+
+func TestScheduler_RawWatermark(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	scheduler := NewScheduler(db, Config{})
+
+	expectWatermark(mock, "1m", 0, false)
+	watermark, err := scheduler.RawWatermark(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, watermark)
+
+	expectWatermark(mock, "1m", ms(2, 29), true)
+	watermark, err = scheduler.RawWatermark(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, ms(2, 29), watermark)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
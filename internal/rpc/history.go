@@ -0,0 +1,110 @@
+package rpc
+
+import (
+	"context"
+	"encoding/base64"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/api"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/rpc/tickerpb"
+)
+
+// QueryHistory returns a page of the stored ticks of a symbol. The query is
+// bounded by the earlier of the client's deadline and the history query
+// timeout.
+func (s *Server) QueryHistory(ctx context.Context, req *tickerpb.QueryHistoryRequest) (*tickerpb.QueryHistoryResponse, error) {
+	symbol := strings.ToUpper(req.GetSymbol())
+	if symbol == "" {
+		return nil, status.Error(codes.InvalidArgument, "symbol is required")
+	}
+	if s.db == nil {
+		return nil, status.Error(codes.Unavailable, "history is not available")
+	}
+
+	limit := s.history.DefaultLimit
+	if req.GetLimit() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid limit %d", req.GetLimit())
+	}
+	if req.GetLimit() > 0 {
+		if int(req.GetLimit()) > s.history.MaxLimit {
+			return nil, status.Errorf(codes.InvalidArgument, "limit %d exceeds the maximum of %d", req.GetLimit(), s.history.MaxLimit)
+		}
+		limit = int(req.GetLimit())
+	}
+
+	to := req.GetTo()
+	if to == 0 {
+		to = time.Now().UnixMilli()
+	}
+	from := req.GetFrom()
+	if from == 0 && s.history.MaxRange > 0 {
+		from = to - s.history.MaxRange.Milliseconds()
+	}
+	if from >= to {
+		return nil, status.Error(codes.InvalidArgument, "from must be before to")
+	}
+	if s.history.MaxRange > 0 && to-from > s.history.MaxRange.Milliseconds() {
+		return nil, status.Errorf(codes.InvalidArgument, "time range exceeds the maximum of %s", s.history.MaxRange)
+	}
+	if token := req.GetPageToken(); token != "" {
+		after, err := decodePageToken(token)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid page token")
+		}
+		from = max(from, after+1)
+	}
+
+	// Don't start a query the client has already given up on
+	if err := ctx.Err(); err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.history.QueryTimeout)
+	defer cancel()
+
+	stored, err := api.QueryTicks(ctx, s.db, symbol, from, to, limit+1)
+	if err != nil {
+		return nil, queryError(ctx, err)
+	}
+
+	ticks := make([]*tickerpb.Tick, 0, len(stored))
+	for _, data := range stored {
+		ticks = append(ticks, toTick(data))
+	}
+
+	resp := &tickerpb.QueryHistoryResponse{Ticks: ticks}
+	if len(ticks) > limit {
+		resp.Ticks = ticks[:limit]
+		resp.NextPageToken = encodePageToken(resp.Ticks[limit-1].EventTime)
+	}
+	return resp, nil
+}
+
+// queryError converts a failed query to a status, distinguishing timeouts
+// and cancellations. Drivers report cancelled queries in their own words, so
+// the context decides.
+func queryError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	log.Printf("Error querying history: %v", err)
+	return status.Error(codes.Internal, "query failed")
+}
+
+// encodePageToken returns an opaque token resuming after the given time
+func encodePageToken(after int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(after, 10)))
+}
+
+func decodePageToken(token string) (int64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(string(raw), 10, 64)
+}
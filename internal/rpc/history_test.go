@@ -0,0 +1,103 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/api"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/rpc/tickerpb"
+)
+
+func tickRows(eventTimes ...int64) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{
+		"event_time", "last_price", "price_change", "high_price", "low_price",
+		"volume", "quote_volume", "open_time", "close_time", "trade_count", "latency",
+	})
+	for _, eventTime := range eventTimes {
+		rows.AddRow(eventTime, 34000.5, 100, 34500, 33500, 10, 340005, eventTime-86400000, eventTime, 1000, 50)
+	}
+	return rows
+}
+
+func newHistoryClient(t *testing.T, cfg api.HistoryConfig) (tickerpb.TickerServiceClient, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	return tickerpb.NewTickerServiceClient(startServer(t, NewServer(Config{}, nil, db, cfg))), mock
+}
+
+func TestServer_QueryHistoryPaginates(t *testing.T) {
+	client, mock := newHistoryClient(t, api.HistoryConfig{})
+
+	mock.ExpectQuery(`SELECT event_time, .* FROM ticker_data`).
+		WithArgs("BTCUSDT", int64(1000), int64(9000), 3).
+		WillReturnRows(tickRows(1000, 2000, 3000))
+	resp, err := client.QueryHistory(context.Background(), &tickerpb.QueryHistoryRequest{Symbol: "btcusdt", From: 1000, To: 9000, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, resp.Ticks, 2)
+	assert.Equal(t, "BTCUSDT", resp.Ticks[1].Symbol)
+	assert.Equal(t, int64(2000), resp.Ticks[1].EventTime)
+	assert.Equal(t, int64(1000), resp.Ticks[1].TradeCount)
+	require.NotEmpty(t, resp.NextPageToken)
+
+	// The next page starts after the last returned tick
+	mock.ExpectQuery(`FROM ticker_data`).
+		WithArgs("BTCUSDT", int64(2001), int64(9000), 3).
+		WillReturnRows(tickRows(3000))
+	resp, err = client.QueryHistory(context.Background(), &tickerpb.QueryHistoryRequest{
+		Symbol: "BTCUSDT", From: 1000, To: 9000, Limit: 2, PageToken: resp.NextPageToken,
+	})
+	require.NoError(t, err)
+	assert.Len(t, resp.Ticks, 1)
+	assert.Empty(t, resp.NextPageToken)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestServer_QueryHistoryInvalid(t *testing.T) {
+	client, _ := newHistoryClient(t, api.HistoryConfig{MaxLimit: 100, MaxRange: time.Hour})
+
+	for _, req := range []*tickerpb.QueryHistoryRequest{
+		{},
+		{Symbol: "BTCUSDT", Limit: 101},
+		{Symbol: "BTCUSDT", Limit: -1},
+		{Symbol: "BTCUSDT", From: 1, To: 7200001},
+		{Symbol: "BTCUSDT", From: 2000, To: 1000},
+		{Symbol: "BTCUSDT", PageToken: "!!"},
+	} {
+		_, err := client.QueryHistory(context.Background(), req)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), req.String())
+	}
+}
+
+func TestServer_QueryHistoryDeadline(t *testing.T) {
+	client, mock := newHistoryClient(t, api.HistoryConfig{})
+
+	// The client's deadline cancels a slow query
+	mock.ExpectQuery(`FROM ticker_data`).WillDelayFor(time.Second).WillReturnRows(tickRows())
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := client.QueryHistory(ctx, &tickerpb.QueryHistoryRequest{Symbol: "BTCUSDT", From: 1000, To: 9000})
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+}
+
+func TestServer_QueryHistoryTimeout(t *testing.T) {
+	client, mock := newHistoryClient(t, api.HistoryConfig{QueryTimeout: 20 * time.Millisecond})
+
+	// So does the configured query timeout
+	mock.ExpectQuery(`FROM ticker_data`).WillDelayFor(time.Second).WillReturnRows(tickRows())
+	_, err := client.QueryHistory(context.Background(), &tickerpb.QueryHistoryRequest{Symbol: "BTCUSDT", From: 1000, To: 9000})
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+
+	mock.ExpectQuery(`FROM ticker_data`).WillReturnError(assert.AnError)
+	_, err = client.QueryHistory(context.Background(), &tickerpb.QueryHistoryRequest{Symbol: "BTCUSDT", From: 1000, To: 9000})
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.NotContains(t, err.Error(), assert.AnError.Error())
+}
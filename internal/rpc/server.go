@@ -0,0 +1,280 @@
+package rpc
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/api"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/latest"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/rpc/tickerpb"
+)
+
+const (
+	defaultAddr         = ":9090"
+	defaultStreamBuffer = 256
+)
+
+// Config holds the settings for the gRPC API
+type Config struct {
+	Enabled bool
+	Addr    string
+	// StreamBuffer is how many ticks may queue for a StreamTicks call before
+	// it is ended as a slow consumer
+	StreamBuffer int `mapstructure:"stream_buffer"`
+}
+
+// stream is an active StreamTicks call. A nil symbols set receives every
+// symbol.
+type stream struct {
+	symbols map[string]bool
+	send    chan *tickerpb.Tick
+	evicted chan struct{}
+	once    sync.Once
+}
+
+func (s *stream) evict() {
+	s.once.Do(func() { close(s.evicted) })
+}
+
+// Server implements DataProcessor interface and serves the TickerService
+// over gRPC alongside the HTTP API. Live ticks come from the same processor
+// chain as every other consumer, the latest ticks from the in-memory store
+// and the history from ticker_data.
+type Server struct {
+	tickerpb.UnimplementedTickerServiceServer
+
+	cfg            Config
+	history        api.HistoryConfig
+	store          *latest.Store
+	db             *sql.DB
+	grpcServer     *grpc.Server
+	streams        map[*stream]struct{}
+	stopping       chan struct{}
+	stopOnce       sync.Once
+	mutex          sync.RWMutex
+	processedCount int
+	evictedCount   int
+}
+
+// NewServer creates a new Server. History queries share the limits of the
+// HTTP history endpoints.
+func NewServer(cfg Config, store *latest.Store, db *sql.DB, history api.HistoryConfig) *Server {
+	if cfg.Addr == "" {
+		cfg.Addr = defaultAddr
+	}
+	if cfg.StreamBuffer <= 0 {
+		cfg.StreamBuffer = defaultStreamBuffer
+	}
+
+	s := &Server{
+		cfg:      cfg,
+		history:  history.WithDefaults(),
+		store:    store,
+		db:       db,
+		streams:  make(map[*stream]struct{}),
+		stopping: make(chan struct{}),
+	}
+	s.grpcServer = grpc.NewServer()
+	tickerpb.RegisterTickerServiceServer(s.grpcServer, s)
+	reflection.Register(s.grpcServer)
+	return s
+}
+
+// Start begins serving in the background. It returns once the listener is
+// open so that address errors are reported to the caller.
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.cfg.Addr)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("gRPC API listening on %s", listener.Addr())
+	go s.Serve(listener)
+	return nil
+}
+
+// Serve serves on listener until the server is stopped
+func (s *Server) Serve(listener net.Listener) {
+	if err := s.grpcServer.Serve(listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		log.Printf("gRPC API error: %v", err)
+	}
+}
+
+// Shutdown ends the open streams and waits for the other calls to finish
+// until ctx is done, after which they are cancelled
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.stopOnce.Do(func() { close(s.stopping) })
+
+	stopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+		return ctx.Err()
+	}
+}
+
+// Process implements the DataProcessor interface
+func (s *Server) Process(data models.FormattedData) {
+	tick := toTick(data)
+	symbol := strings.ToUpper(data.Symbol)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.processedCount++
+	for st := range s.streams {
+		if st.symbols != nil && !st.symbols[symbol] {
+			continue
+		}
+		select {
+		case st.send <- tick:
+		default:
+			delete(s.streams, st)
+			s.evictedCount++
+			st.evict()
+			log.Printf("gRPC tick stream too slow, ending it")
+		}
+	}
+}
+
+// StreamTicks sends the ticks of the requested symbols until the client
+// cancels, its deadline passes or the server shuts down
+func (s *Server) StreamTicks(req *tickerpb.StreamTicksRequest, srv tickerpb.TickerService_StreamTicksServer) error {
+	st := &stream{
+		send:    make(chan *tickerpb.Tick, s.cfg.StreamBuffer),
+		evicted: make(chan struct{}),
+	}
+	for _, symbol := range req.GetSymbols() {
+		if symbol = strings.ToUpper(strings.TrimSpace(symbol)); symbol != "" {
+			if st.symbols == nil {
+				st.symbols = make(map[string]bool)
+			}
+			st.symbols[symbol] = true
+		}
+	}
+
+	s.mutex.Lock()
+	s.streams[st] = struct{}{}
+	s.mutex.Unlock()
+	defer func() {
+		s.mutex.Lock()
+		delete(s.streams, st)
+		s.mutex.Unlock()
+	}()
+
+	if req.GetSnapshot() && s.store != nil {
+		for _, latestTick := range s.store.All(time.Now()) {
+			if st.symbols != nil && !st.symbols[strings.ToUpper(latestTick.Symbol)] {
+				continue
+			}
+			if err := srv.Send(toTick(latestTick.FormattedData)); err != nil {
+				return err
+			}
+		}
+	}
+
+	ctx := srv.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-s.stopping:
+			return status.Error(codes.Unavailable, "server shutting down")
+		case <-st.evicted:
+			return status.Error(codes.ResourceExhausted, "slow consumer")
+		case tick := <-st.send:
+			if err := srv.Send(tick); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// GetLatest returns the latest tick of a symbol from the in-memory store
+func (s *Server) GetLatest(ctx context.Context, req *tickerpb.GetLatestRequest) (*tickerpb.LatestTick, error) {
+	if req.GetSymbol() == "" {
+		return nil, status.Error(codes.InvalidArgument, "symbol is required")
+	}
+	if s.store == nil {
+		return nil, status.Error(codes.Unavailable, "latest ticks are not available")
+	}
+
+	latestTick, ok := s.store.Latest(req.GetSymbol(), time.Now())
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "no ticks received for %s", strings.ToUpper(req.GetSymbol()))
+	}
+	return &tickerpb.LatestTick{
+		Tick:                      toTick(latestTick.FormattedData),
+		ConnectedAt:               latestTick.ConnectedAt,
+		ConnectPrice:              latestTick.ConnectPrice,
+		ChangeSinceConnect:        latestTick.ChangeSinceConnect,
+		ChangeSinceConnectPercent: latestTick.ChangeSinceConnectPercent,
+		UpdatedAt:                 latestTick.UpdatedAt,
+		LastUpdateAgeMs:           latestTick.LastUpdateAge,
+		UpdateCount:               int64(latestTick.UpdateCount),
+	}, nil
+}
+
+// GetEvictedCount returns the number of streams ended as slow consumers
+func (s *Server) GetEvictedCount() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.evictedCount
+}
+
+// GetProcessedCount returns the number of processed messages
+func (s *Server) GetProcessedCount() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.processedCount
+}
+
+// GetBufferSize returns the number of ticks queued for all streams
+func (s *Server) GetBufferSize() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	size := 0
+	for st := range s.streams {
+		size += len(st.send)
+	}
+	return size
+}
+
+// toTick converts a tick to its protobuf message
+func toTick(data models.FormattedData) *tickerpb.Tick {
+	return &tickerpb.Tick{
+		EventTime:   data.EventTime,
+		Symbol:      data.Symbol,
+		LastPrice:   data.LastPrice,
+		BidPrice:    data.BidPrice,
+		AskPrice:    data.AskPrice,
+		PriceChange: data.PriceChange,
+		HighPrice:   data.HighPrice,
+		LowPrice:    data.LowPrice,
+		Volume:      data.Volume,
+		QuoteVolume: data.QuoteVolume,
+		OpenTime:    data.OpenTime,
+		CloseTime:   data.CloseTime,
+		TradeCount:  int64(data.TradeCount),
+		Latency:     data.Latency,
+	}
+}
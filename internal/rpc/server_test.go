@@ -0,0 +1,162 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/api"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/latest"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/rpc/tickerpb"
+)
+
+// startServer serves s in memory and returns a connected client
+func startServer(t *testing.T, s *Server) *grpc.ClientConn {
+	listener := bufconn.Listen(1 << 20)
+	go s.Serve(listener)
+	t.Cleanup(func() { s.Shutdown(context.Background()) })
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// waitForStreams waits until n StreamTicks calls are registered
+func waitForStreams(t *testing.T, s *Server, n int) {
+	require.Eventually(t, func() bool {
+		s.mutex.RLock()
+		defer s.mutex.RUnlock()
+		return len(s.streams) == n
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestServer_StreamTicks(t *testing.T) {
+	store := latest.NewStore()
+	store.Process(models.FormattedData{Symbol: "BTCUSDT", EventTime: 1000, LastPrice: 60000})
+	store.Process(models.FormattedData{Symbol: "ETHUSDT", EventTime: 1000, LastPrice: 3000})
+	server := NewServer(Config{}, store, nil, api.HistoryConfig{})
+	client := tickerpb.NewTickerServiceClient(startServer(t, server))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := client.StreamTicks(ctx, &tickerpb.StreamTicksRequest{Symbols: []string{"btcusdt"}, Snapshot: true})
+	require.NoError(t, err)
+
+	// The snapshot comes first and only has the requested symbols
+	tick, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "BTCUSDT", tick.Symbol)
+	assert.Equal(t, 60000.0, tick.LastPrice)
+
+	waitForStreams(t, server, 1)
+	server.Process(models.FormattedData{Symbol: "ETHUSDT", EventTime: 2000, LastPrice: 3001})
+	server.Process(models.FormattedData{Symbol: "BTCUSDT", EventTime: 2000, LastPrice: 60001, BidPrice: 60000.5, TradeCount: 7})
+	tick, err = stream.Recv()
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(&tickerpb.Tick{Symbol: "BTCUSDT", EventTime: 2000, LastPrice: 60001, BidPrice: 60000.5, TradeCount: 7}, tick), tick.String())
+	assert.Equal(t, 2, server.GetProcessedCount())
+
+	cancel()
+	waitForStreams(t, server, 0)
+}
+
+func TestServer_StreamTicksDeadline(t *testing.T) {
+	server := NewServer(Config{}, nil, nil, api.HistoryConfig{})
+	client := tickerpb.NewTickerServiceClient(startServer(t, server))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	stream, err := client.StreamTicks(ctx, &tickerpb.StreamTicksRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	waitForStreams(t, server, 0)
+}
+
+func TestServer_StreamTicksSlowConsumer(t *testing.T) {
+	server := NewServer(Config{StreamBuffer: 1}, nil, nil, api.HistoryConfig{})
+
+	// Fill the buffer of a stream that is not being read
+	st := &stream{send: make(chan *tickerpb.Tick, 1), evicted: make(chan struct{})}
+	server.streams[st] = struct{}{}
+	server.Process(models.FormattedData{Symbol: "BTCUSDT"})
+	assert.Equal(t, 1, server.GetBufferSize())
+	server.Process(models.FormattedData{Symbol: "BTCUSDT"})
+
+	assert.Equal(t, 1, server.GetEvictedCount())
+	assert.Equal(t, 0, server.GetBufferSize())
+	select {
+	case <-st.evicted:
+	default:
+		t.Fatal("stream was not evicted")
+	}
+}
+
+func TestServer_ShutdownEndsStreams(t *testing.T) {
+	server := NewServer(Config{}, nil, nil, api.HistoryConfig{})
+	client := tickerpb.NewTickerServiceClient(startServer(t, server))
+
+	stream, err := client.StreamTicks(context.Background(), &tickerpb.StreamTicksRequest{})
+	require.NoError(t, err)
+	waitForStreams(t, server, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, server.Shutdown(ctx))
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestServer_GetLatest(t *testing.T) {
+	store := latest.NewStore()
+	store.Process(models.FormattedData{Symbol: "BTCUSDT", EventTime: 1000, LastPrice: 60000})
+	store.Process(models.FormattedData{Symbol: "BTCUSDT", EventTime: 2000, LastPrice: 60600})
+	client := tickerpb.NewTickerServiceClient(startServer(t, NewServer(Config{}, store, nil, api.HistoryConfig{})))
+
+	latestTick, err := client.GetLatest(context.Background(), &tickerpb.GetLatestRequest{Symbol: "btcusdt"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2000), latestTick.Tick.EventTime)
+	assert.Equal(t, 60000.0, latestTick.ConnectPrice)
+	assert.InDelta(t, 1.0, latestTick.ChangeSinceConnectPercent, 1e-9)
+	assert.Equal(t, int64(2), latestTick.UpdateCount)
+
+	_, err = client.GetLatest(context.Background(), &tickerpb.GetLatestRequest{Symbol: "DOGEUSDT"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = client.GetLatest(context.Background(), &tickerpb.GetLatestRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestServer_Reflection(t *testing.T) {
+	conn := startServer(t, NewServer(Config{}, nil, nil, api.HistoryConfig{}))
+
+	stream, err := grpc_reflection_v1.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&grpc_reflection_v1.ServerReflectionRequest{
+		MessageRequest: &grpc_reflection_v1.ServerReflectionRequest_ListServices{},
+	}))
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+
+	var services []string
+	for _, service := range resp.GetListServicesResponse().GetService() {
+		services = append(services, service.GetName())
+	}
+	assert.Contains(t, services, "binancemonitor.v1.TickerService")
+}
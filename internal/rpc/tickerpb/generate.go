@@ -0,0 +1,5 @@
+// Package tickerpb holds the protobuf messages and gRPC service generated
+// from ticker.proto
+package tickerpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative ticker.proto
@@ -0,0 +1,230 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.4.0
+// - protoc             (unknown)
+// source: ticker.proto
+
+package tickerpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	TickerService_StreamTicks_FullMethodName  = "/binancemonitor.v1.TickerService/StreamTicks"
+	TickerService_GetLatest_FullMethodName    = "/binancemonitor.v1.TickerService/GetLatest"
+	TickerService_QueryHistory_FullMethodName = "/binancemonitor.v1.TickerService/QueryHistory"
+)
+
+// TickerServiceClient is the client API for TickerService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// TickerService streams and queries the ticks received by the monitor. Times
+// are epoch milliseconds.
+type TickerServiceClient interface {
+	// StreamTicks sends every tick of the requested symbols (all symbols when
+	// empty) as it is received
+	StreamTicks(ctx context.Context, in *StreamTicksRequest, opts ...grpc.CallOption) (TickerService_StreamTicksClient, error)
+	// GetLatest returns the latest tick of a symbol without querying the
+	// database
+	GetLatest(ctx context.Context, in *GetLatestRequest, opts ...grpc.CallOption) (*LatestTick, error)
+	// QueryHistory returns a page of the stored ticks of a symbol
+	QueryHistory(ctx context.Context, in *QueryHistoryRequest, opts ...grpc.CallOption) (*QueryHistoryResponse, error)
+}
+
+type tickerServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTickerServiceClient(cc grpc.ClientConnInterface) TickerServiceClient {
+	return &tickerServiceClient{cc}
+}
+
+func (c *tickerServiceClient) StreamTicks(ctx context.Context, in *StreamTicksRequest, opts ...grpc.CallOption) (TickerService_StreamTicksClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TickerService_ServiceDesc.Streams[0], TickerService_StreamTicks_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &tickerServiceStreamTicksClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TickerService_StreamTicksClient interface {
+	Recv() (*Tick, error)
+	grpc.ClientStream
+}
+
+type tickerServiceStreamTicksClient struct {
+	grpc.ClientStream
+}
+
+func (x *tickerServiceStreamTicksClient) Recv() (*Tick, error) {
+	m := new(Tick)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *tickerServiceClient) GetLatest(ctx context.Context, in *GetLatestRequest, opts ...grpc.CallOption) (*LatestTick, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LatestTick)
+	err := c.cc.Invoke(ctx, TickerService_GetLatest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tickerServiceClient) QueryHistory(ctx context.Context, in *QueryHistoryRequest, opts ...grpc.CallOption) (*QueryHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QueryHistoryResponse)
+	err := c.cc.Invoke(ctx, TickerService_QueryHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TickerServiceServer is the server API for TickerService service.
+// All implementations must embed UnimplementedTickerServiceServer
+// for forward compatibility
+//
+// TickerService streams and queries the ticks received by the monitor. Times
+// are epoch milliseconds.
+type TickerServiceServer interface {
+	// StreamTicks sends every tick of the requested symbols (all symbols when
+	// empty) as it is received
+	StreamTicks(*StreamTicksRequest, TickerService_StreamTicksServer) error
+	// GetLatest returns the latest tick of a symbol without querying the
+	// database
+	GetLatest(context.Context, *GetLatestRequest) (*LatestTick, error)
+	// QueryHistory returns a page of the stored ticks of a symbol
+	QueryHistory(context.Context, *QueryHistoryRequest) (*QueryHistoryResponse, error)
+	mustEmbedUnimplementedTickerServiceServer()
+}
+
+// UnimplementedTickerServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedTickerServiceServer struct {
+}
+
+func (UnimplementedTickerServiceServer) StreamTicks(*StreamTicksRequest, TickerService_StreamTicksServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamTicks not implemented")
+}
+func (UnimplementedTickerServiceServer) GetLatest(context.Context, *GetLatestRequest) (*LatestTick, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLatest not implemented")
+}
+func (UnimplementedTickerServiceServer) QueryHistory(context.Context, *QueryHistoryRequest) (*QueryHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryHistory not implemented")
+}
+func (UnimplementedTickerServiceServer) mustEmbedUnimplementedTickerServiceServer() {}
+
+// UnsafeTickerServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TickerServiceServer will
+// result in compilation errors.
+type UnsafeTickerServiceServer interface {
+	mustEmbedUnimplementedTickerServiceServer()
+}
+
+func RegisterTickerServiceServer(s grpc.ServiceRegistrar, srv TickerServiceServer) {
+	s.RegisterService(&TickerService_ServiceDesc, srv)
+}
+
+func _TickerService_StreamTicks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamTicksRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TickerServiceServer).StreamTicks(m, &tickerServiceStreamTicksServer{ServerStream: stream})
+}
+
+type TickerService_StreamTicksServer interface {
+	Send(*Tick) error
+	grpc.ServerStream
+}
+
+type tickerServiceStreamTicksServer struct {
+	grpc.ServerStream
+}
+
+func (x *tickerServiceStreamTicksServer) Send(m *Tick) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _TickerService_GetLatest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLatestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TickerServiceServer).GetLatest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TickerService_GetLatest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TickerServiceServer).GetLatest(ctx, req.(*GetLatestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TickerService_QueryHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TickerServiceServer).QueryHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TickerService_QueryHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TickerServiceServer).QueryHistory(ctx, req.(*QueryHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// TickerService_ServiceDesc is the grpc.ServiceDesc for TickerService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TickerService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "binancemonitor.v1.TickerService",
+	HandlerType: (*TickerServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetLatest",
+			Handler:    _TickerService_GetLatest_Handler,
+		},
+		{
+			MethodName: "QueryHistory",
+			Handler:    _TickerService_QueryHistory_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamTicks",
+			Handler:       _TickerService_StreamTicks_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "ticker.proto",
+}
@@ -0,0 +1,235 @@
+package simulator
+
+import (
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var klineIntervals = map[string]time.Duration{
+	"1s": time.Second, "1m": time.Minute, "3m": 3 * time.Minute, "5m": 5 * time.Minute,
+	"15m": 15 * time.Minute, "30m": 30 * time.Minute, "1h": time.Hour, "2h": 2 * time.Hour,
+	"4h": 4 * time.Hour, "6h": 6 * time.Hour, "8h": 8 * time.Hour, "12h": 12 * time.Hour,
+	"1d": 24 * time.Hour, "3d": 72 * time.Hour, "1w": 7 * 24 * time.Hour,
+}
+
+type apiError struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Error encoding simulator response: %v", err)
+	}
+}
+
+func writeAPIError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, http.StatusBadRequest, apiError{Code: code, Msg: msg})
+}
+
+// marketParam looks up the symbol query parameter. The caller must hold the mutex.
+func (s *Simulator) marketParam(w http.ResponseWriter, r *http.Request) (*market, bool) {
+	symbol := r.URL.Query().Get("symbol")
+	if symbol == "" {
+		writeAPIError(w, -1102, "Mandatory parameter 'symbol' was not sent, was empty/null, or malformed.")
+		return nil, false
+	}
+	m, ok := s.markets[strings.ToUpper(symbol)]
+	if !ok {
+		writeAPIError(w, -1121, "Invalid symbol.")
+		return nil, false
+	}
+	return m, true
+}
+
+func intParam(r *http.Request, name string, def, max int) int {
+	v, err := strconv.Atoi(r.URL.Query().Get(name))
+	if err != nil || v <= 0 {
+		return def
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+func (s *Simulator) handleExchangeInfo(w http.ResponseWriter, r *http.Request) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	symbols := s.symbols
+	if symbol := r.URL.Query().Get("symbol"); symbol != "" {
+		m, ok := s.marketParam(w, r)
+		if !ok {
+			return
+		}
+		symbols = []string{m.symbol}
+	}
+
+	infos := make([]map[string]interface{}, 0, len(symbols))
+	for _, symbol := range symbols {
+		m := s.markets[symbol]
+		infos = append(infos, map[string]interface{}{
+			"symbol":     m.symbol,
+			"status":     "TRADING",
+			"baseAsset":  m.base,
+			"quoteAsset": m.quote,
+			"orderTypes": []string{"LIMIT", "MARKET", "STOP_LOSS", "STOP_LOSS_LIMIT", "TAKE_PROFIT", "TAKE_PROFIT_LIMIT"},
+			"filters": []map[string]interface{}{
+				{"filterType": "PRICE_FILTER", "minPrice": formatFloat(m.tickSize), "maxPrice": formatFloat(1000000), "tickSize": formatFloat(m.tickSize)},
+				{"filterType": "LOT_SIZE", "minQty": formatFloat(m.stepSize), "maxQty": formatFloat(9000), "stepSize": formatFloat(m.stepSize)},
+				{"filterType": "NOTIONAL", "minNotional": formatFloat(m.minNotional())},
+			},
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"timezone":   "UTC",
+		"serverTime": time.Now().UnixMilli(),
+		"symbols":    infos,
+	})
+}
+
+// handleDepth returns a synthetic order book around the current bid and ask
+func (s *Simulator) handleDepth(w http.ResponseWriter, r *http.Request) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	m, ok := s.marketParam(w, r)
+	if !ok {
+		return
+	}
+	limit := intParam(r, "limit", 100, 5000)
+
+	bid, ask := m.bidAsk(s.cfg.Spread)
+	bids := make([][2]string, 0, limit)
+	asks := make([][2]string, 0, limit)
+	for i := 0; i < limit; i++ {
+		qty := formatFloat(m.roundQty(float64(i+1) * 10 * m.stepSize))
+		if price := bid - float64(i)*m.tickSize; price > 0 {
+			bids = append(bids, [2]string{formatFloat(m.roundPrice(price)), qty})
+		}
+		asks = append(asks, [2]string{formatFloat(m.roundPrice(ask + float64(i)*m.tickSize)), qty})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"lastUpdateId": m.updateID,
+		"bids":         bids,
+		"asks":         asks,
+	})
+}
+
+// handleKlines aggregates the simulated trades into candles in the Binance
+// array format
+func (s *Simulator) handleKlines(w http.ResponseWriter, r *http.Request) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	m, ok := s.marketParam(w, r)
+	if !ok {
+		return
+	}
+	interval, ok := klineIntervals[r.URL.Query().Get("interval")]
+	if !ok {
+		writeAPIError(w, -1120, "Invalid interval.")
+		return
+	}
+	limit := intParam(r, "limit", 500, 1000)
+	size := interval.Milliseconds()
+
+	var klines [][]interface{}
+	var current []interface{}
+	var open, high, low, close, volume, quoteVolume float64
+	var count int
+	flush := func() {
+		if current != nil {
+			current[1], current[2], current[3], current[4] = formatFloat(open), formatFloat(high), formatFloat(low), formatFloat(close)
+			current[5], current[7], current[8] = formatFloat(volume), formatFloat(quoteVolume), count
+			klines = append(klines, current)
+		}
+	}
+
+	for _, p := range m.history {
+		openTime := p.time - p.time%size
+		if current == nil || current[0].(int64) != openTime {
+			flush()
+			current = []interface{}{openTime, "", "", "", "", "", openTime + size - 1, "", 0, "0", "0", "0"}
+			open, high, low, volume, quoteVolume, count = p.price, p.price, p.price, 0, 0, 0
+		}
+		high = math.Max(high, p.price)
+		low = math.Min(low, p.price)
+		close = p.price
+		volume += p.quantity
+		quoteVolume += p.quantity * p.price
+		count++
+	}
+	flush()
+
+	if len(klines) > limit {
+		klines = klines[len(klines)-limit:]
+	}
+	if klines == nil {
+		klines = [][]interface{}{}
+	}
+	writeJSON(w, http.StatusOK, klines)
+}
+
+// handleTicker24h returns 24 hour statistics for one symbol or all symbols
+func (s *Simulator) handleTicker24h(w http.ResponseWriter, r *http.Request) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	now := time.Now().UnixMilli()
+	if r.URL.Query().Get("symbol") != "" {
+		m, ok := s.marketParam(w, r)
+		if !ok {
+			return
+		}
+		writeJSON(w, http.StatusOK, s.ticker24h(m, now))
+		return
+	}
+
+	tickers := make([]map[string]interface{}, 0, len(s.symbols))
+	for _, symbol := range s.symbols {
+		tickers = append(tickers, s.ticker24h(s.markets[symbol], now))
+	}
+	writeJSON(w, http.StatusOK, tickers)
+}
+
+// ticker24h builds a REST 24hr ticker. The caller must hold the mutex.
+func (s *Simulator) ticker24h(m *market, now int64) map[string]interface{} {
+	if m.lastUpdated > 0 {
+		now = m.lastUpdated
+	}
+	e := s.tickerEvent(m, now)
+	return map[string]interface{}{
+		"symbol":             m.symbol,
+		"priceChange":        e["p"],
+		"priceChangePercent": e["P"],
+		"weightedAvgPrice":   e["w"],
+		"prevClosePrice":     e["x"],
+		"lastPrice":          e["c"],
+		"lastQty":            e["Q"],
+		"bidPrice":           e["b"],
+		"bidQty":             e["B"],
+		"askPrice":           e["a"],
+		"askQty":             e["A"],
+		"openPrice":          e["o"],
+		"highPrice":          e["h"],
+		"lowPrice":           e["l"],
+		"volume":             e["v"],
+		"quoteVolume":        e["q"],
+		"openTime":           e["O"],
+		"closeTime":          e["C"],
+		"firstId":            e["F"],
+		"lastId":             e["L"],
+		"count":              e["n"],
+	}
+}
@@ -0,0 +1,112 @@
+package simulator
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func get(t *testing.T, sim *Simulator, path string, v interface{}) int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	sim.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), v))
+	return rec.Code
+}
+
+func TestExchangeInfo(t *testing.T) {
+	sim, _ := newTestSimulator(t, Config{})
+
+	var info struct {
+		Symbols []struct {
+			Symbol     string              `json:"symbol"`
+			BaseAsset  string              `json:"baseAsset"`
+			QuoteAsset string              `json:"quoteAsset"`
+			Filters    []map[string]string `json:"filters"`
+		} `json:"symbols"`
+	}
+	require.Equal(t, http.StatusOK, get(t, sim, "/api/v3/exchangeInfo", &info))
+	require.Len(t, info.Symbols, 2)
+	btc := info.Symbols[0]
+	assert.Equal(t, "BTCUSDT", btc.Symbol)
+	assert.Equal(t, "BTC", btc.BaseAsset)
+	assert.Equal(t, "USDT", btc.QuoteAsset)
+	assert.Equal(t, "PRICE_FILTER", btc.Filters[0]["filterType"])
+	assert.Equal(t, "0.01000000", btc.Filters[0]["tickSize"])
+	assert.Equal(t, "0.00010000", btc.Filters[1]["stepSize"])
+
+	require.Equal(t, http.StatusOK, get(t, sim, "/api/v3/exchangeInfo?symbol=ethusdt", &info))
+	assert.Len(t, info.Symbols, 1)
+
+	var apiErr apiError
+	assert.Equal(t, http.StatusBadRequest, get(t, sim, "/api/v3/exchangeInfo?symbol=XYZ", &apiErr))
+	assert.Equal(t, -1121, apiErr.Code)
+}
+
+func TestDepth(t *testing.T) {
+	sim, _ := newTestSimulator(t, Config{Volatility: -1})
+	sim.Tick(start)
+
+	var depth struct {
+		LastUpdateID int64       `json:"lastUpdateId"`
+		Bids         [][2]string `json:"bids"`
+		Asks         [][2]string `json:"asks"`
+	}
+	require.Equal(t, http.StatusOK, get(t, sim, "/api/v3/depth?symbol=BTCUSDT&limit=5", &depth))
+	assert.Equal(t, int64(1), depth.LastUpdateID)
+	require.Len(t, depth.Bids, 5)
+	require.Len(t, depth.Asks, 5)
+	assert.Equal(t, "59997.00000000", depth.Bids[0][0])
+	assert.Equal(t, "60003.00000000", depth.Asks[0][0])
+	assert.Equal(t, "59996.99000000", depth.Bids[1][0])
+
+	var apiErr apiError
+	assert.Equal(t, http.StatusBadRequest, get(t, sim, "/api/v3/depth", &apiErr))
+	assert.Equal(t, -1102, apiErr.Code)
+}
+
+func TestKlines(t *testing.T) {
+	sim, _ := newTestSimulator(t, Config{Volatility: -1, Scenario: []Step{{After: time.Minute, Symbol: "BTCUSDT", Price: 61000}}})
+	for i := 0; i < 90; i++ {
+		sim.Tick(start.Add(time.Duration(i) * time.Second))
+	}
+
+	var klines [][]interface{}
+	require.Equal(t, http.StatusOK, get(t, sim, "/api/v3/klines?symbol=BTCUSDT&interval=1m", &klines))
+	require.Len(t, klines, 2)
+	assert.Equal(t, float64(start.UnixMilli()), klines[0][0])
+	assert.Equal(t, "60000.00000000", klines[0][1])
+	assert.Equal(t, "60000.00000000", klines[0][4])
+	assert.Equal(t, "61000.00000000", klines[1][1])
+	assert.Equal(t, 60.0, klines[0][8])
+	assert.Equal(t, 30.0, klines[1][8])
+
+	require.Equal(t, http.StatusOK, get(t, sim, "/api/v3/klines?symbol=BTCUSDT&interval=1m&limit=1", &klines))
+	assert.Len(t, klines, 1)
+
+	var apiErr apiError
+	assert.Equal(t, http.StatusBadRequest, get(t, sim, "/api/v3/klines?symbol=BTCUSDT&interval=7m", &apiErr))
+}
+
+func TestTicker24h(t *testing.T) {
+	sim, _ := newTestSimulator(t, Config{Volatility: -1, Scenario: []Step{{After: time.Second, Symbol: "BTCUSDT", Price: 63000}}})
+	sim.Tick(start)
+	sim.Tick(start.Add(time.Second))
+
+	var ticker map[string]interface{}
+	require.Equal(t, http.StatusOK, get(t, sim, "/api/v3/ticker/24hr?symbol=BTCUSDT", &ticker))
+	assert.Equal(t, "63000.00000000", ticker["lastPrice"])
+	assert.Equal(t, "60000.00000000", ticker["openPrice"])
+	assert.Equal(t, "3000.00000000", ticker["priceChange"])
+	assert.Equal(t, "5.000", ticker["priceChangePercent"])
+	assert.Equal(t, 2.0, ticker["count"])
+
+	var all []map[string]interface{}
+	require.Equal(t, http.StatusOK, get(t, sim, "/api/v3/ticker/24hr", &all))
+	assert.Len(t, all, 2)
+}
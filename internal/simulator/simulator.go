@@ -0,0 +1,316 @@
+package simulator
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+const (
+	defaultAddr             = ":8090"
+	defaultTickInterval     = time.Second
+	defaultVolatility       = 0.0005
+	defaultSpread           = 0.0001
+	defaultPingInterval     = 3 * time.Minute
+	defaultMaxConnectionAge = 24 * time.Hour
+	maxHistory              = 100000
+	dayMillis               = int64(24 * time.Hour / time.Millisecond)
+)
+
+// Step is a scripted scenario event. At After since the start of the
+// simulation the symbol's price jumps to Price, and all websocket connections
+// are dropped when Disconnect is set.
+type Step struct {
+	After      time.Duration
+	Symbol     string
+	Price      float64
+	Disconnect bool
+}
+
+// Config holds the simulated exchange settings
+type Config struct {
+	Addr string
+	// Symbols maps each simulated symbol to its starting price
+	Symbols map[string]float64
+	// TickInterval is how often every symbol trades and streams a ticker
+	TickInterval time.Duration `mapstructure:"tick_interval"`
+	// Volatility is the standard deviation of the random walk's log return
+	// per tick; set it negative for purely scripted prices
+	Volatility float64
+	// Spread is the bid/ask spread as a fraction of the price
+	Spread float64
+	// Seed makes the random walk reproducible; 0 uses the current time
+	Seed int64
+	// PingInterval is how often the server pings each websocket connection
+	PingInterval time.Duration `mapstructure:"ping_interval"`
+	// MaxConnectionAge disconnects websocket connections after this long,
+	// like Binance does after 24 hours
+	MaxConnectionAge time.Duration `mapstructure:"max_connection_age"`
+	Scenario         []Step
+}
+
+// point is a simulated trade kept for klines and 24h statistics
+type point struct {
+	time     int64
+	price    float64
+	quantity float64
+}
+
+type market struct {
+	symbol      string
+	base        string
+	quote       string
+	price       float64
+	tickSize    float64
+	stepSize    float64
+	tradeID     int64
+	lastQty     float64
+	history     []point
+	updateID    int64
+	lastUpdated int64
+}
+
+// Simulator is a local stand-in for the Binance websocket and REST APIs. Every
+// tick each symbol trades once at a new random-walk (or scripted) price and
+// the resulting ticker and trade events are pushed to subscribers.
+type Simulator struct {
+	cfg      Config
+	rand     *rand.Rand
+	markets  map[string]*market
+	symbols  []string
+	start    time.Time
+	scenario []Step
+	conns    map[*conn]struct{}
+	server   *http.Server
+	mutex    sync.RWMutex
+	connMux  sync.Mutex
+}
+
+// NewSimulator creates a new Simulator
+func NewSimulator(cfg Config) (*Simulator, error) {
+	if cfg.Addr == "" {
+		cfg.Addr = defaultAddr
+	}
+	if cfg.TickInterval <= 0 {
+		cfg.TickInterval = defaultTickInterval
+	}
+	if cfg.Volatility == 0 {
+		cfg.Volatility = defaultVolatility
+	}
+	if cfg.Spread <= 0 {
+		cfg.Spread = defaultSpread
+	}
+	if cfg.Seed == 0 {
+		cfg.Seed = time.Now().UnixNano()
+	}
+	if cfg.PingInterval <= 0 {
+		cfg.PingInterval = defaultPingInterval
+	}
+	if cfg.MaxConnectionAge <= 0 {
+		cfg.MaxConnectionAge = defaultMaxConnectionAge
+	}
+	if len(cfg.Symbols) == 0 {
+		return nil, fmt.Errorf("no symbols to simulate")
+	}
+
+	sim := &Simulator{
+		cfg:     cfg,
+		rand:    rand.New(rand.NewSource(cfg.Seed)),
+		markets: make(map[string]*market),
+		conns:   make(map[*conn]struct{}),
+	}
+
+	for symbol, price := range cfg.Symbols {
+		symbol = strings.ToUpper(symbol)
+		base, quote, ok := models.SplitSymbol(symbol, nil)
+		if !ok {
+			return nil, fmt.Errorf("cannot split symbol %q into base and quote assets", symbol)
+		}
+		if price <= 0 {
+			return nil, fmt.Errorf("starting price of %s must be positive", symbol)
+		}
+		m := &market{
+			symbol:   symbol,
+			base:     base,
+			quote:    quote,
+			tickSize: pow10Floor(price * 1e-6),
+			stepSize: math.Min(1, pow10Floor(10/price)),
+		}
+		m.price = m.roundPrice(price)
+		sim.markets[symbol] = m
+		sim.symbols = append(sim.symbols, symbol)
+	}
+	sort.Strings(sim.symbols)
+
+	sim.scenario = append(sim.scenario, cfg.Scenario...)
+	sort.SliceStable(sim.scenario, func(i, j int) bool { return sim.scenario[i].After < sim.scenario[j].After })
+
+	// Created up front so that Close never races ListenAndServe
+	sim.server = &http.Server{Addr: cfg.Addr, Handler: sim.Handler()}
+
+	return sim, nil
+}
+
+// pow10Floor returns the largest power of ten not above v, at least 1e-8
+func pow10Floor(v float64) float64 {
+	return math.Max(1e-8, math.Pow(10, math.Floor(math.Log10(v))))
+}
+
+func (m *market) roundPrice(price float64) float64 {
+	rounded := math.Round(price/m.tickSize) * m.tickSize
+	return math.Max(rounded, m.tickSize)
+}
+
+func (m *market) roundQty(qty float64) float64 {
+	return math.Max(math.Round(qty/m.stepSize)*m.stepSize, m.stepSize)
+}
+
+// minNotional mirrors Binance's minimum order value: 5 units of fiat-like
+// quote assets and 0.0001 of crypto quote assets
+func (m *market) minNotional() float64 {
+	switch m.quote {
+	case "BTC", "ETH", "BNB":
+		return 0.0001
+	default:
+		return 5
+	}
+}
+
+func (m *market) bidAsk(spread float64) (float64, float64) {
+	half := math.Max(m.price*spread/2, m.tickSize)
+	return m.roundPrice(m.price - half), m.roundPrice(m.price + half)
+}
+
+// Tick advances the simulation to now: scripted steps that are due are
+// applied, every symbol trades once and the events are sent to subscribers.
+// Run calls it every TickInterval; tests can call it directly.
+func (s *Simulator) Tick(now time.Time) {
+	s.mutex.Lock()
+	if s.start.IsZero() {
+		s.start = now
+	}
+
+	disconnect := false
+	for len(s.scenario) > 0 && now.Sub(s.start) >= s.scenario[0].After {
+		step := s.scenario[0]
+		s.scenario = s.scenario[1:]
+		if m, ok := s.markets[strings.ToUpper(step.Symbol)]; ok && step.Price > 0 {
+			m.price = m.roundPrice(step.Price)
+		}
+		disconnect = disconnect || step.Disconnect
+	}
+
+	eventTime := now.UnixMilli()
+	var events []event
+	for _, symbol := range s.symbols {
+		m := s.markets[symbol]
+		if s.cfg.Volatility > 0 {
+			m.price = m.roundPrice(m.price * math.Exp(s.rand.NormFloat64()*s.cfg.Volatility))
+		}
+		m.tradeID++
+		m.updateID++
+		m.lastUpdated = eventTime
+		m.lastQty = m.roundQty(s.rand.ExpFloat64() * 100 * m.stepSize)
+		m.history = append(m.history, point{time: eventTime, price: m.price, quantity: m.lastQty})
+		if len(m.history) > maxHistory {
+			m.history = m.history[len(m.history)-maxHistory:]
+		}
+
+		events = append(events,
+			event{stream: strings.ToLower(symbol) + "@ticker", data: s.tickerEvent(m, eventTime)},
+			event{stream: strings.ToLower(symbol) + "@trade", data: s.tradeEvent(m, eventTime)},
+		)
+	}
+	s.mutex.Unlock()
+
+	if disconnect {
+		s.disconnectAll()
+	}
+	s.broadcast(events)
+}
+
+// stats24h summarises the trades of the last 24 hours. The caller must hold
+// the mutex.
+func (m *market) stats24h(now int64) (open, high, low, volume, quoteVolume float64, count int, openTime int64) {
+	first := sort.Search(len(m.history), func(i int) bool { return m.history[i].time > now-dayMillis })
+	window := m.history[first:]
+	if len(window) == 0 {
+		return m.price, m.price, m.price, 0, 0, 0, now
+	}
+
+	open, high, low, openTime = window[0].price, window[0].price, window[0].price, window[0].time
+	for _, p := range window {
+		high = math.Max(high, p.price)
+		low = math.Min(low, p.price)
+		volume += p.quantity
+		quoteVolume += p.quantity * p.price
+	}
+	return open, high, low, volume, quoteVolume, len(window), openTime
+}
+
+// Run ticks the simulation every TickInterval until stop is closed
+func (s *Simulator) Run(stop chan struct{}) {
+	ticker := time.NewTicker(s.cfg.TickInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			s.disconnectAll()
+			return
+		case now := <-ticker.C:
+			s.Tick(now)
+		}
+	}
+}
+
+// Handler returns the HTTP handler serving the websocket and REST endpoints
+func (s *Simulator) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /ws", s.handleStream)
+	mux.HandleFunc("GET /ws/{streams...}", s.handleStream)
+	mux.HandleFunc("GET /stream", s.handleStream)
+	mux.HandleFunc("GET /api/v3/exchangeInfo", s.handleExchangeInfo)
+	mux.HandleFunc("GET /api/v3/depth", s.handleDepth)
+	mux.HandleFunc("GET /api/v3/klines", s.handleKlines)
+	mux.HandleFunc("GET /api/v3/ticker/24hr", s.handleTicker24h)
+	mux.HandleFunc("GET /api/v3/ping", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, struct{}{})
+	})
+	mux.HandleFunc("GET /api/v3/time", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]int64{"serverTime": time.Now().UnixMilli()})
+	})
+	return mux
+}
+
+// ListenAndServe serves the simulator on the configured address until the
+// server is shut down
+func (s *Simulator) ListenAndServe() error {
+	log.Printf("Binance simulator listening on %s", s.cfg.Addr)
+	return s.server.ListenAndServe()
+}
+
+// Close stops the HTTP server and drops all websocket connections
+func (s *Simulator) Close() error {
+	s.disconnectAll()
+	return s.server.Close()
+}
+
+// Price returns the current price of a symbol
+func (s *Simulator) Price(symbol string) (float64, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	m, ok := s.markets[strings.ToUpper(symbol)]
+	if !ok {
+		return 0, false
+	}
+	return m.price, true
+}
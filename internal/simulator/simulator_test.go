@@ -0,0 +1,204 @@
+package simulator
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+var start = time.Unix(1700000040, 0)
+
+func newTestSimulator(t *testing.T, cfg Config) (*Simulator, *httptest.Server) {
+	t.Helper()
+	if cfg.Symbols == nil {
+		cfg.Symbols = map[string]float64{"btcusdt": 60000, "ethusdt": 3000}
+	}
+	if cfg.Seed == 0 {
+		cfg.Seed = 42
+	}
+	sim, err := NewSimulator(cfg)
+	require.NoError(t, err)
+	server := httptest.NewServer(sim.Handler())
+	t.Cleanup(func() {
+		sim.Close()
+		server.Close()
+	})
+	return sim, server
+}
+
+func dial(t *testing.T, server *httptest.Server, path string) *websocket.Conn {
+	t.Helper()
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+path, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func waitForConnections(t *testing.T, sim *Simulator, n int) {
+	t.Helper()
+	require.Eventually(t, func() bool { return sim.Connections() == n }, time.Second, 5*time.Millisecond)
+}
+
+func read(t *testing.T, conn *websocket.Conn, v interface{}) {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, message, err := conn.ReadMessage()
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(message, v))
+}
+
+func TestNewSimulator_Errors(t *testing.T) {
+	_, err := NewSimulator(Config{})
+	assert.Error(t, err)
+
+	_, err = NewSimulator(Config{Symbols: map[string]float64{"btcxyz": 1}})
+	assert.Error(t, err)
+
+	_, err = NewSimulator(Config{Symbols: map[string]float64{"btcusdt": 0}})
+	assert.Error(t, err)
+}
+
+func TestSimulator_CloseStopsListenAndServe(t *testing.T) {
+	sim, err := NewSimulator(Config{Addr: "127.0.0.1:0", Symbols: map[string]float64{"btcusdt": 60000}})
+	require.NoError(t, err)
+
+	served := make(chan error, 1)
+	go func() { served <- sim.ListenAndServe() }()
+	require.NoError(t, sim.Close())
+
+	select {
+	case err := <-served:
+		assert.ErrorIs(t, err, http.ErrServerClosed)
+	case <-time.After(5 * time.Second):
+		t.Fatal("ListenAndServe did not return after Close")
+	}
+}
+
+func TestSimulator_RandomWalkIsReproducible(t *testing.T) {
+	a, err := NewSimulator(Config{Symbols: map[string]float64{"btcusdt": 60000}, Seed: 7})
+	require.NoError(t, err)
+	b, err := NewSimulator(Config{Symbols: map[string]float64{"btcusdt": 60000}, Seed: 7})
+	require.NoError(t, err)
+
+	for i := 0; i < 50; i++ {
+		a.Tick(start.Add(time.Duration(i) * time.Second))
+		b.Tick(start.Add(time.Duration(i) * time.Second))
+	}
+
+	priceA, _ := a.Price("BTCUSDT")
+	priceB, _ := b.Price("btcusdt")
+	assert.Equal(t, priceA, priceB)
+	assert.NotEqual(t, 60000.0, priceA)
+	assert.InDelta(t, 0, priceA/0.01-float64(int64(priceA/0.01+0.5)), 1e-6, "prices are rounded to the tick size")
+}
+
+func TestSimulator_Scenario(t *testing.T) {
+	sim, server := newTestSimulator(t, Config{
+		Symbols:    map[string]float64{"btcusdt": 60000},
+		Volatility: -1,
+		Scenario: []Step{
+			{After: 2 * time.Second, Symbol: "BTCUSDT", Price: 55000},
+			{After: 3 * time.Second, Disconnect: true},
+		},
+	})
+	conn := dial(t, server, "/ws/btcusdt@ticker")
+	waitForConnections(t, sim, 1)
+
+	sim.Tick(start)
+	sim.Tick(start.Add(time.Second))
+	price, _ := sim.Price("BTCUSDT")
+	assert.Equal(t, 60000.0, price)
+
+	sim.Tick(start.Add(2 * time.Second))
+	price, _ = sim.Price("BTCUSDT")
+	assert.Equal(t, 55000.0, price)
+
+	sim.Tick(start.Add(3 * time.Second))
+	waitForConnections(t, sim, 0)
+
+	for {
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		if _, _, err := conn.ReadMessage(); err != nil {
+			break
+		}
+	}
+}
+
+func TestSimulator_TickerStreamParses(t *testing.T) {
+	sim, server := newTestSimulator(t, Config{})
+	conn := dial(t, server, "/ws/btcusdt@ticker")
+	waitForConnections(t, sim, 1)
+
+	sim.Tick(start)
+
+	var ticker models.TickerData
+	read(t, conn, &ticker)
+	data := models.FormatTickerData(ticker)
+	assert.Equal(t, "24hrTicker", ticker.EventType)
+	assert.Equal(t, "BTCUSDT", data.Symbol)
+	assert.Equal(t, start.UnixMilli(), data.EventTime)
+	assert.Greater(t, data.LastPrice, 0.0)
+	assert.Less(t, data.BidPrice, data.AskPrice)
+	assert.Equal(t, 1, data.TradeCount)
+}
+
+func TestSimulator_SubscribeAndCombinedStream(t *testing.T) {
+	sim, server := newTestSimulator(t, Config{})
+	conn := dial(t, server, "/stream?streams=btcusdt@trade")
+	waitForConnections(t, sim, 1)
+
+	require.NoError(t, conn.WriteJSON(map[string]interface{}{"method": "SUBSCRIBE", "params": []string{"ethusdt@ticker"}, "id": 1}))
+	var resp map[string]interface{}
+	read(t, conn, &resp)
+	assert.Equal(t, map[string]interface{}{"result": nil, "id": 1.0}, resp)
+
+	require.NoError(t, conn.WriteJSON(map[string]interface{}{"method": "LIST_SUBSCRIPTIONS", "id": 2}))
+	read(t, conn, &resp)
+	assert.Equal(t, []interface{}{"btcusdt@trade", "ethusdt@ticker"}, resp["result"])
+
+	require.NoError(t, conn.WriteJSON(map[string]interface{}{"method": "UNSUBSCRIBE", "params": []string{"btcusdt@trade"}, "id": 3}))
+	read(t, conn, &resp)
+
+	require.NoError(t, conn.WriteJSON(map[string]interface{}{"method": "BOGUS", "id": 4}))
+	read(t, conn, &resp)
+	assert.NotNil(t, resp["error"])
+
+	sim.Tick(start)
+
+	var wrapped struct {
+		Stream string            `json:"stream"`
+		Data   models.TickerData `json:"data"`
+	}
+	read(t, conn, &wrapped)
+	assert.Equal(t, "ethusdt@ticker", wrapped.Stream)
+	assert.Equal(t, "ETHUSDT", wrapped.Data.Symbol)
+}
+
+func TestSimulator_PingsAndMaxConnectionAge(t *testing.T) {
+	sim, server := newTestSimulator(t, Config{PingInterval: 10 * time.Millisecond, MaxConnectionAge: 100 * time.Millisecond})
+	conn := dial(t, server, "/ws")
+	waitForConnections(t, sim, 1)
+
+	var pings atomic.Int32
+	conn.SetPingHandler(func(payload string) error {
+		pings.Add(1)
+		return conn.WriteControl(websocket.PongMessage, []byte(payload), time.Now().Add(time.Second))
+	})
+
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, _, err := conn.ReadMessage()
+	require.Error(t, err)
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseNormalClosure))
+	assert.Greater(t, pings.Load(), int32(2))
+	waitForConnections(t, sim, 0)
+}
@@ -0,0 +1,330 @@
+package simulator
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	sendBuffer   = 256
+	writeTimeout = 10 * time.Second
+)
+
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// event is a stream payload waiting to be sent to subscribers
+type event struct {
+	stream string
+	data   interface{}
+}
+
+// request is a websocket control message such as SUBSCRIBE
+type request struct {
+	Method string          `json:"method"`
+	Params []string        `json:"params"`
+	ID     json.RawMessage `json:"id"`
+}
+
+type requestError struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+}
+
+type response struct {
+	Result interface{}     `json:"result"`
+	Error  *requestError   `json:"error,omitempty"`
+	ID     json.RawMessage `json:"id"`
+}
+
+// conn is a websocket client connection. Combined connections wrap every
+// event as {"stream": ..., "data": ...} like the /stream endpoint.
+type conn struct {
+	ws       *websocket.Conn
+	combined bool
+	streams  map[string]bool
+	send     chan []byte
+	done     chan struct{}
+	once     sync.Once
+	mutex    sync.Mutex
+}
+
+func (c *conn) close() {
+	c.once.Do(func() {
+		close(c.done)
+		c.ws.Close()
+	})
+}
+
+func (c *conn) subscribed(stream string) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.streams[stream]
+}
+
+// enqueue queues a message, dropping connections that cannot keep up
+func (c *conn) enqueue(message []byte) {
+	select {
+	case c.send <- message:
+	case <-c.done:
+	default:
+		log.Printf("Simulator client too slow, disconnecting")
+		c.close()
+	}
+}
+
+// handleStream upgrades /ws/<stream>, /ws and /stream?streams=a/b requests
+func (s *Simulator) handleStream(w http.ResponseWriter, r *http.Request) {
+	var streams []string
+	combined := r.URL.Path == "/stream"
+	if combined {
+		streams = strings.Split(r.URL.Query().Get("streams"), "/")
+	} else if path := r.PathValue("streams"); path != "" {
+		streams = strings.Split(path, "/")
+	}
+
+	ws, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("Simulator websocket upgrade error: %v", err)
+		return
+	}
+
+	c := &conn{
+		ws:       ws,
+		combined: combined,
+		streams:  make(map[string]bool),
+		send:     make(chan []byte, sendBuffer),
+		done:     make(chan struct{}),
+	}
+	for _, stream := range streams {
+		if stream != "" {
+			c.streams[strings.ToLower(stream)] = true
+		}
+	}
+
+	s.connMux.Lock()
+	s.conns[c] = struct{}{}
+	s.connMux.Unlock()
+
+	go s.writeLoop(c)
+	s.readLoop(c)
+}
+
+// writeLoop sends queued messages and pings, and disconnects the client once
+// the connection reaches its maximum age
+func (s *Simulator) writeLoop(c *conn) {
+	ping := time.NewTicker(s.cfg.PingInterval)
+	defer ping.Stop()
+	expire := time.NewTimer(s.cfg.MaxConnectionAge)
+	defer expire.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case message := <-c.send:
+			c.ws.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if err := c.ws.WriteMessage(websocket.TextMessage, message); err != nil {
+				c.close()
+				return
+			}
+		case <-ping.C:
+			payload := []byte(strconv.FormatInt(time.Now().UnixMilli(), 10))
+			if err := c.ws.WriteControl(websocket.PingMessage, payload, time.Now().Add(writeTimeout)); err != nil {
+				c.close()
+				return
+			}
+		case <-expire.C:
+			closing := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "connection reached maximum age")
+			c.ws.WriteControl(websocket.CloseMessage, closing, time.Now().Add(writeTimeout))
+			c.close()
+			return
+		}
+	}
+}
+
+// readLoop handles SUBSCRIBE, UNSUBSCRIBE and LIST_SUBSCRIPTIONS requests.
+// Clients that stop answering pings are disconnected.
+func (s *Simulator) readLoop(c *conn) {
+	defer func() {
+		c.close()
+		s.connMux.Lock()
+		delete(s.conns, c)
+		s.connMux.Unlock()
+	}()
+
+	pongWait := 3 * s.cfg.PingInterval
+	c.ws.SetReadDeadline(time.Now().Add(pongWait))
+	c.ws.SetPongHandler(func(string) error {
+		return c.ws.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, message, err := c.ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		c.ws.SetReadDeadline(time.Now().Add(pongWait))
+
+		var req request
+		if err := json.Unmarshal(message, &req); err != nil {
+			s.reply(c, response{Error: &requestError{Code: 3, Msg: "Invalid JSON: " + err.Error()}})
+			continue
+		}
+
+		c.mutex.Lock()
+		switch req.Method {
+		case "SUBSCRIBE":
+			for _, stream := range req.Params {
+				c.streams[strings.ToLower(stream)] = true
+			}
+			c.mutex.Unlock()
+			s.reply(c, response{ID: req.ID})
+		case "UNSUBSCRIBE":
+			for _, stream := range req.Params {
+				delete(c.streams, strings.ToLower(stream))
+			}
+			c.mutex.Unlock()
+			s.reply(c, response{ID: req.ID})
+		case "LIST_SUBSCRIPTIONS":
+			list := make([]string, 0, len(c.streams))
+			for stream := range c.streams {
+				list = append(list, stream)
+			}
+			c.mutex.Unlock()
+			sort.Strings(list)
+			s.reply(c, response{Result: list, ID: req.ID})
+		default:
+			c.mutex.Unlock()
+			s.reply(c, response{Error: &requestError{Code: 2, Msg: "Invalid request: unknown method " + req.Method}, ID: req.ID})
+		}
+	}
+}
+
+func (s *Simulator) reply(c *conn, resp response) {
+	message, err := json.Marshal(resp)
+	if err != nil {
+		log.Printf("Error encoding simulator response: %v", err)
+		return
+	}
+	c.enqueue(message)
+}
+
+// broadcast sends events to every connection subscribed to their stream
+func (s *Simulator) broadcast(events []event) {
+	s.connMux.Lock()
+	conns := make([]*conn, 0, len(s.conns))
+	for c := range s.conns {
+		conns = append(conns, c)
+	}
+	s.connMux.Unlock()
+
+	for _, e := range events {
+		var raw, wrapped []byte
+		for _, c := range conns {
+			if !c.subscribed(e.stream) {
+				continue
+			}
+			var err error
+			if c.combined {
+				if wrapped == nil {
+					wrapped, err = json.Marshal(map[string]interface{}{"stream": e.stream, "data": e.data})
+				}
+				if err == nil {
+					c.enqueue(wrapped)
+				}
+			} else {
+				if raw == nil {
+					raw, err = json.Marshal(e.data)
+				}
+				if err == nil {
+					c.enqueue(raw)
+				}
+			}
+			if err != nil {
+				log.Printf("Error encoding simulator event: %v", err)
+			}
+		}
+	}
+}
+
+// disconnectAll drops every websocket connection
+func (s *Simulator) disconnectAll() {
+	s.connMux.Lock()
+	defer s.connMux.Unlock()
+	for c := range s.conns {
+		c.close()
+	}
+}
+
+// Connections returns the number of open websocket connections
+func (s *Simulator) Connections() int {
+	s.connMux.Lock()
+	defer s.connMux.Unlock()
+	return len(s.conns)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', 8, 64)
+}
+
+// tickerEvent builds a 24hrTicker stream payload. The caller must hold the mutex.
+func (s *Simulator) tickerEvent(m *market, eventTime int64) map[string]interface{} {
+	open, high, low, volume, quoteVolume, count, openTime := m.stats24h(eventTime)
+	bid, ask := m.bidAsk(s.cfg.Spread)
+	change := m.price - open
+	weighted := m.price
+	if volume > 0 {
+		weighted = quoteVolume / volume
+	}
+
+	return map[string]interface{}{
+		"e": "24hrTicker",
+		"E": eventTime,
+		"s": m.symbol,
+		"p": formatFloat(change),
+		"P": strconv.FormatFloat(change/open*100, 'f', 3, 64),
+		"w": formatFloat(weighted),
+		"x": formatFloat(open),
+		"c": formatFloat(m.price),
+		"Q": formatFloat(m.lastQty),
+		"b": formatFloat(bid),
+		"B": formatFloat(m.roundQty(10 * m.stepSize)),
+		"a": formatFloat(ask),
+		"A": formatFloat(m.roundQty(10 * m.stepSize)),
+		"o": formatFloat(open),
+		"h": formatFloat(high),
+		"l": formatFloat(low),
+		"v": formatFloat(volume),
+		"q": formatFloat(quoteVolume),
+		"O": openTime,
+		"C": eventTime,
+		"F": m.tradeID - int64(count) + 1,
+		"L": m.tradeID,
+		"n": count,
+	}
+}
+
+// tradeEvent builds a trade stream payload. The caller must hold the mutex.
+func (s *Simulator) tradeEvent(m *market, eventTime int64) map[string]interface{} {
+	return map[string]interface{}{
+		"e": "trade",
+		"E": eventTime,
+		"s": m.symbol,
+		"t": m.tradeID,
+		"p": formatFloat(m.price),
+		"q": formatFloat(m.lastQty),
+		"T": eventTime,
+		"m": m.tradeID%2 == 0,
+		"M": true,
+	}
+}
@@ -0,0 +1,94 @@
+package strategy
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/indicators"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+func init() {
+	Register("sma_cross", NewSMACross)
+}
+
+// SMACross buys a fixed quantity when the fast SMA of the last price crosses
+// above the slow SMA and sells it when it crosses back below
+type SMACross struct {
+	broker   Broker
+	symbol   string
+	quantity float64
+	fast     *indicators.SMA
+	slow     *indicators.SMA
+	above    bool
+	ready    bool
+	long     bool
+}
+
+// NewSMACross creates an SMACross strategy. Params: fast (default 10), slow
+// (default 30) and quantity (required).
+func NewSMACross(broker Broker, symbol string, params map[string]float64) (Strategy, error) {
+	fast, slow, quantity := int(params["fast"]), int(params["slow"]), params["quantity"]
+	if fast <= 0 {
+		fast = 10
+	}
+	if slow <= 0 {
+		slow = 30
+	}
+	if fast >= slow {
+		return nil, fmt.Errorf("sma_cross fast period %d must be shorter than slow period %d", fast, slow)
+	}
+	if quantity <= 0 {
+		return nil, fmt.Errorf("sma_cross needs a positive quantity")
+	}
+	if symbol == "" {
+		return nil, fmt.Errorf("sma_cross needs a symbol")
+	}
+
+	return &SMACross{
+		broker:   broker,
+		symbol:   strings.ToUpper(symbol),
+		quantity: quantity,
+		fast:     indicators.NewSMA(fast),
+		slow:     indicators.NewSMA(slow),
+	}, nil
+}
+
+// Process implements the Strategy interface
+func (s *SMACross) Process(data models.FormattedData) {
+	if !strings.EqualFold(data.Symbol, s.symbol) || data.LastPrice <= 0 {
+		return
+	}
+
+	s.fast.Update(data.LastPrice)
+	s.slow.Update(data.LastPrice)
+	if !s.slow.Ready() {
+		return
+	}
+
+	above := s.fast.Value() > s.slow.Value()
+	if !s.ready {
+		s.ready, s.above = true, above
+		return
+	}
+	if above == s.above {
+		return
+	}
+	s.above = above
+
+	side := models.SideSell
+	if above {
+		side = models.SideBuy
+	}
+	if (side == models.SideBuy) == s.long {
+		return
+	}
+
+	_, err := s.broker.PlaceOrder(models.OrderRequest{Symbol: s.symbol, Side: side, Type: models.OrderMarket, Quantity: s.quantity})
+	if err != nil {
+		log.Printf("sma_cross: error placing %s order for %s: %v", side, s.symbol, err)
+		return
+	}
+	s.long = side == models.SideBuy
+}
@@ -0,0 +1,102 @@
+package strategy
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+// Broker is the order entry interface strategies trade through. It is
+// implemented by paper.Exchange, both when paper trading the live feed and
+// when backtesting, so a strategy runs unchanged in either mode.
+type Broker interface {
+	PlaceOrder(req models.OrderRequest) (models.Order, error)
+	CancelOrder(id int64) (models.Order, error)
+	Account() models.Account
+}
+
+// Strategy receives ticks through the same callback as live processors
+type Strategy interface {
+	Process(data models.FormattedData)
+}
+
+// Factory builds a strategy bound to a broker from numeric parameters
+type Factory func(broker Broker, symbol string, params map[string]float64) (Strategy, error)
+
+// Config selects a registered strategy and its parameters
+type Config struct {
+	Name   string
+	Symbol string
+	Params map[string]float64
+}
+
+var (
+	registry      = make(map[string]Factory)
+	registryMutex sync.RWMutex
+)
+
+// Register makes a strategy available by name
+func Register(name string, factory Factory) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	registry[name] = factory
+}
+
+// Names returns the registered strategy names
+func Names() []string {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New builds the configured strategy bound to a broker
+func New(cfg Config, broker Broker) (Strategy, error) {
+	registryMutex.RLock()
+	factory, ok := registry[cfg.Name]
+	registryMutex.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown strategy %q", cfg.Name)
+	}
+	return factory(broker, cfg.Symbol, cfg.Params)
+}
+
+// Runner implements DataProcessor interface so a strategy can be added to the
+// live processor chain after the paper exchange that fills its orders
+type Runner struct {
+	strategy       Strategy
+	mutex          sync.Mutex
+	processedCount int
+}
+
+// NewRunner creates a new Runner
+func NewRunner(strategy Strategy) *Runner {
+	return &Runner{strategy: strategy}
+}
+
+// Process implements the DataProcessor interface
+func (r *Runner) Process(data models.FormattedData) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.strategy.Process(data)
+	r.processedCount++
+}
+
+// GetProcessedCount returns the number of processed messages
+func (r *Runner) GetProcessedCount() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.processedCount
+}
+
+// GetBufferSize returns the current size of the buffer (always 0, ticks are handled synchronously)
+func (r *Runner) GetBufferSize() int {
+	return 0
+}
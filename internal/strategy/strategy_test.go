@@ -0,0 +1,66 @@
+package strategy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
+)
+
+type mockBroker struct {
+	requests []models.OrderRequest
+}
+
+func (m *mockBroker) PlaceOrder(req models.OrderRequest) (models.Order, error) {
+	m.requests = append(m.requests, req)
+	return models.Order{ID: int64(len(m.requests)), Status: models.StatusFilled}, nil
+}
+
+func (m *mockBroker) CancelOrder(id int64) (models.Order, error) {
+	return models.Order{ID: id, Status: models.StatusCanceled}, nil
+}
+
+func (m *mockBroker) Account() models.Account {
+	return models.Account{}
+}
+
+func TestNew(t *testing.T) {
+	assert.Contains(t, Names(), "sma_cross")
+
+	_, err := New(Config{Name: "missing"}, &mockBroker{})
+	assert.Error(t, err)
+
+	_, err = New(Config{Name: "sma_cross", Symbol: "BTCUSDT", Params: map[string]float64{"fast": 5, "slow": 3, "quantity": 1}}, &mockBroker{})
+	assert.Error(t, err)
+
+	_, err = New(Config{Name: "sma_cross", Symbol: "BTCUSDT"}, &mockBroker{})
+	assert.Error(t, err)
+
+	s, err := New(Config{Name: "sma_cross", Symbol: "BTCUSDT", Params: map[string]float64{"quantity": 1}}, &mockBroker{})
+	require.NoError(t, err)
+	assert.IsType(t, &SMACross{}, s)
+}
+
+func TestSMACross_TradesOnCrossovers(t *testing.T) {
+	broker := &mockBroker{}
+	s, err := NewSMACross(broker, "btcusdt", map[string]float64{"fast": 2, "slow": 3, "quantity": 0.5})
+	require.NoError(t, err)
+	runner := NewRunner(s)
+
+	prices := []float64{10, 10, 10, 9, 8, 9, 11, 12, 11, 9, 8}
+	for i, price := range prices {
+		runner.Process(models.FormattedData{EventTime: int64(i), Symbol: "BTCUSDT", LastPrice: price})
+		runner.Process(models.FormattedData{EventTime: int64(i), Symbol: "ETHUSDT", LastPrice: 1000 - price})
+	}
+
+	// The first cross down happens while flat and is ignored
+	require.Len(t, broker.requests, 2)
+	assert.Equal(t, models.SideBuy, broker.requests[0].Side)
+	assert.Equal(t, models.SideSell, broker.requests[1].Side)
+	assert.Equal(t, "BTCUSDT", broker.requests[0].Symbol)
+	assert.Equal(t, models.OrderMarket, broker.requests[0].Type)
+	assert.Equal(t, 0.5, broker.requests[0].Quantity)
+	assert.Equal(t, 2*len(prices), runner.GetProcessedCount())
+}
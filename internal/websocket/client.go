@@ -4,17 +4,36 @@ import (
 	"encoding/json"
 	"log"
 	"sync"
+	"time"
 
 	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
 	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/processor"
 	"github.com/gorilla/websocket"
 )
 
+// FrameRecorder receives every raw frame read from the connection
+type FrameRecorder interface {
+	RecordFrame(receivedAt time.Time, frame []byte)
+}
+
+// ReconnectConfig controls how Listen re-establishes a dropped connection.
+// The delay doubles after each failed attempt up to MaxBackoff.
+type ReconnectConfig struct {
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
 // Client manages the WebSocket connection and data processing
 type Client struct {
-	conn       *websocket.Conn
-	processors []processor.DataProcessor
-	mutex      sync.RWMutex
+	conn        *websocket.Conn
+	uri         string
+	processors  []processor.DataProcessor
+	recorder    FrameRecorder
+	reconnect   *ReconnectConfig
+	readTimeout time.Duration
+	reconnects  int
+	mutex       sync.RWMutex
+	connMutex   sync.Mutex
 }
 
 // NewClient creates a new Client
@@ -31,18 +50,104 @@ func (c *Client) AddProcessor(proc processor.DataProcessor) {
 	c.processors = append(c.processors, proc)
 }
 
+// SetRecorder records every raw frame before it is parsed
+func (c *Client) SetRecorder(recorder FrameRecorder) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.recorder = recorder
+}
+
+// SetReconnect makes Listen reconnect after read errors instead of returning
+func (c *Client) SetReconnect(cfg ReconnectConfig) {
+	if cfg.MinBackoff <= 0 {
+		cfg.MinBackoff = 100 * time.Millisecond
+	}
+	if cfg.MaxBackoff < cfg.MinBackoff {
+		cfg.MaxBackoff = cfg.MinBackoff
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.reconnect = &cfg
+}
+
+// SetReadTimeout treats a connection that delivers no frame within timeout as
+// half-open, failing the read so that Listen can reconnect
+func (c *Client) SetReadTimeout(timeout time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.readTimeout = timeout
+}
+
 // Connect establishes a WebSocket connection
 func (c *Client) Connect(uri string) error {
-	var err error
-	c.conn, _, err = websocket.DefaultDialer.Dial(uri, nil)
-	return err
+	conn, _, err := websocket.DefaultDialer.Dial(uri, nil)
+	if err != nil {
+		return err
+	}
+
+	c.connMutex.Lock()
+	defer c.connMutex.Unlock()
+	c.conn = conn
+	c.uri = uri
+	return nil
 }
 
 // Close closes the WebSocket connection
 func (c *Client) Close() error {
+	c.connMutex.Lock()
+	defer c.connMutex.Unlock()
 	return c.conn.Close()
 }
 
+// GetReconnectCount returns the number of successful reconnections
+func (c *Client) GetReconnectCount() int {
+	c.connMutex.Lock()
+	defer c.connMutex.Unlock()
+	return c.reconnects
+}
+
+// redial replaces a failed connection, backing off between attempts, until it
+// succeeds or stop is closed. It reports whether Listen should continue.
+func (c *Client) redial(stop chan struct{}) bool {
+	c.mutex.RLock()
+	cfg := c.reconnect
+	c.mutex.RUnlock()
+	if cfg == nil {
+		return false
+	}
+
+	c.connMutex.Lock()
+	c.conn.Close()
+	uri := c.uri
+	c.connMutex.Unlock()
+
+	backoff := cfg.MinBackoff
+	for {
+		select {
+		case <-stop:
+			return false
+		case <-time.After(backoff):
+		}
+
+		conn, _, err := websocket.DefaultDialer.Dial(uri, nil)
+		if err == nil {
+			c.connMutex.Lock()
+			c.conn = conn
+			c.reconnects++
+			c.connMutex.Unlock()
+			log.Printf("Reconnected to %s", uri)
+			return true
+		}
+
+		log.Printf("Reconnect to %s failed: %v", uri, err)
+		backoff *= 2
+		if backoff > cfg.MaxBackoff {
+			backoff = cfg.MaxBackoff
+		}
+	}
+}
+
 // Listen starts listening for WebSocket messages
 func (c *Client) Listen(stop chan struct{}) {
 	for {
@@ -50,25 +155,63 @@ func (c *Client) Listen(stop chan struct{}) {
 		case <-stop:
 			return
 		default:
-			_, message, err := c.conn.ReadMessage()
+			c.mutex.RLock()
+			readTimeout := c.readTimeout
+			c.mutex.RUnlock()
+
+			c.connMutex.Lock()
+			conn := c.conn
+			c.connMutex.Unlock()
+			if readTimeout > 0 {
+				conn.SetReadDeadline(time.Now().Add(readTimeout))
+			}
+
+			_, message, err := conn.ReadMessage()
 			if err != nil {
 				log.Println("read:", err)
+				if c.redial(stop) {
+					continue
+				}
 				return
 			}
-			c.processMessage(message)
+			receivedAt := time.Now()
+			c.mutex.RLock()
+			recorder := c.recorder
+			c.mutex.RUnlock()
+			if recorder != nil {
+				recorder.RecordFrame(receivedAt, message)
+			}
+			c.processFrame(receivedAt, message)
 		}
 	}
 }
 
+// HandleMessage runs a raw frame through parsing and the processors as if it
+// had just been read from the connection
+func (c *Client) HandleMessage(message []byte) {
+	c.processMessage(message)
+}
+
+// HandleFrame runs a raw frame received at the given time through parsing
+// and the processors, e.g. when replaying recorded frames, so that latency
+// is measured against the original receive time
+func (c *Client) HandleFrame(receivedAt time.Time, message []byte) {
+	c.processFrame(receivedAt, message)
+}
+
 // processMessage handles incoming WebSocket messages
 func (c *Client) processMessage(message []byte) {
+	c.processFrame(time.Now(), message)
+}
+
+func (c *Client) processFrame(receivedAt time.Time, message []byte) {
 	var tickerData models.TickerData
 	if err := json.Unmarshal(message, &tickerData); err != nil {
 		log.Printf("Error parsing JSON: %v", err)
 		return
 	}
 
-	formattedData := models.FormatTickerData(tickerData)
+	formattedData := models.FormatTickerDataAt(tickerData, receivedAt)
 
 	// Print some information to the console
 	log.Printf("Received data for %s - Price: %.2f, Change: %.2f, Volume: %.2f",
@@ -2,14 +2,18 @@ package websocket
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/models"
 	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/processor"
+	"github.com/arturogonzalezm/RealTimeBinanceMonitor/internal/recorder"
 	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -141,3 +145,190 @@ func TestListen(t *testing.T) {
 	assert.Equal(t, "ETHUSDT", processedData.Symbol, "Processed symbol should match")
 	assert.Equal(t, 3000.00, processedData.LastPrice, "Processed last price should match")
 }
+
+// MockRecorder implements the FrameRecorder interface for testing
+type MockRecorder struct {
+	mutex  sync.Mutex
+	frames [][]byte
+}
+
+func (m *MockRecorder) RecordFrame(receivedAt time.Time, frame []byte) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.frames = append(m.frames, frame)
+}
+
+func (m *MockRecorder) Frames() [][]byte {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.frames
+}
+
+func TestListen_RecordsRawFrames(t *testing.T) {
+	frames := []string{`{"s":"BTCUSDT","c":"50000.00"}`, `not json`}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upgrader := websocket.Upgrader{}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err, "Failed to upgrade connection")
+		defer conn.Close()
+
+		for _, frame := range frames {
+			require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(frame)), "Failed to write message")
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	mockProcessor := &MockProcessor{bufferSize: 100}
+	client.AddProcessor(mockProcessor)
+	mockRecorder := &MockRecorder{}
+	client.SetRecorder(mockRecorder)
+
+	require.NoError(t, client.Connect("ws"+strings.TrimPrefix(server.URL, "http")), "Failed to connect")
+	defer client.Close()
+
+	stop := make(chan struct{})
+	go client.Listen(stop)
+	time.Sleep(100 * time.Millisecond)
+	close(stop)
+
+	recorded := mockRecorder.Frames()
+	require.Len(t, recorded, 2, "Should have recorded every frame, including malformed ones")
+	assert.Equal(t, frames[1], string(recorded[1]))
+	assert.Len(t, mockProcessor.ProcessedData, 1, "Should have processed only the valid frame")
+}
+
+func TestHandleMessage(t *testing.T) {
+	client := NewClient()
+	mockProcessor := &MockProcessor{bufferSize: 100}
+	client.AddProcessor(mockProcessor)
+
+	client.HandleMessage([]byte(`{"s":"ETHUSDT","c":"3000.00"}`))
+
+	require.Len(t, mockProcessor.ProcessedData, 1, "Should have processed 1 message")
+	assert.Equal(t, 3000.00, mockProcessor.ProcessedData[0].LastPrice)
+}
+
+func TestHandleFrame_ReplayIsDeterministic(t *testing.T) {
+	dir := t.TempDir()
+	rec, err := recorder.NewRecorder(recorder.Config{Dir: dir})
+	require.NoError(t, err)
+	receivedAt := time.UnixMilli(1700000000250)
+	rec.RecordFrame(receivedAt, []byte(`{"E":1700000000000,"s":"BTCUSDT","c":"34000.00"}`))
+	rec.RecordFrame(receivedAt.Add(time.Second), []byte(`{"E":1700000000900,"s":"BTCUSDT","c":"34010.00"}`))
+	require.NoError(t, rec.Close())
+
+	replay := func() []models.FormattedData {
+		client := NewClient()
+		mockProcessor := &MockProcessor{}
+		client.AddProcessor(mockProcessor)
+		_, err := recorder.ReplayFrames(dir, 0, make(chan struct{}), func(frame recorder.Frame) {
+			client.HandleFrame(frame.ReceivedAt, frame.Data)
+		})
+		require.NoError(t, err)
+		return mockProcessor.ProcessedData
+	}
+
+	first := replay()
+	require.Len(t, first, 2)
+	assert.Equal(t, int64(250), first[0].Latency, "Latency should be measured against the recorded receive time")
+	assert.Equal(t, int64(350), first[1].Latency)
+	assert.Equal(t, first, replay())
+}
+
+func TestListen_ReconnectsAfterDisconnect(t *testing.T) {
+	var connections atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upgrader := websocket.Upgrader{}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err, "Failed to upgrade connection")
+		defer conn.Close()
+
+		// Every connection delivers one tick and is then dropped
+		n := connections.Add(1)
+		message := fmt.Sprintf(`{"s":"BTCUSDT","E":%d,"c":"50000.00"}`, n)
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(message)), "Failed to write message")
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	mockProcessor := &MockProcessor{bufferSize: 100}
+	client.AddProcessor(mockProcessor)
+	client.SetReconnect(ReconnectConfig{MinBackoff: 10 * time.Millisecond, MaxBackoff: 20 * time.Millisecond})
+
+	require.NoError(t, client.Connect("ws"+strings.TrimPrefix(server.URL, "http")), "Failed to connect")
+	defer client.Close()
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		client.Listen(stop)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool { return client.GetReconnectCount() >= 2 }, time.Second, 5*time.Millisecond)
+	close(stop)
+	<-done
+
+	assert.GreaterOrEqual(t, len(mockProcessor.ProcessedData), 2, "Should have processed a tick from each connection")
+}
+
+func TestListen_ReadTimeoutDetectsHalfOpenConnection(t *testing.T) {
+	var connections atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upgrader := websocket.Upgrader{}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err, "Failed to upgrade connection")
+		defer conn.Close()
+
+		// The first connection goes silent without closing
+		if connections.Add(1) == 1 {
+			<-release
+			return
+		}
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"s":"ETHUSDT","c":"3000.00"}`)), "Failed to write message")
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewClient()
+	received := &chanProcessor{data: make(chan models.FormattedData, 10)}
+	client.AddProcessor(received)
+	client.SetReconnect(ReconnectConfig{MinBackoff: 10 * time.Millisecond, MaxBackoff: 10 * time.Millisecond})
+	client.SetReadTimeout(50 * time.Millisecond)
+
+	require.NoError(t, client.Connect("ws"+strings.TrimPrefix(server.URL, "http")), "Failed to connect")
+	defer client.Close()
+
+	stop := make(chan struct{})
+	go client.Listen(stop)
+	defer close(stop)
+
+	select {
+	case data := <-received.data:
+		assert.Equal(t, "ETHUSDT", data.Symbol)
+	case <-time.After(time.Second):
+		t.Fatal("No tick received after the silent connection timed out")
+	}
+	assert.Equal(t, 1, client.GetReconnectCount())
+}
+
+// chanProcessor forwards processed data to a channel for tests that read it
+// while the client is still listening
+type chanProcessor struct {
+	data chan models.FormattedData
+}
+
+func (c *chanProcessor) Process(data models.FormattedData) {
+	c.data <- data
+}
+
+func (c *chanProcessor) GetProcessedCount() int {
+	return 0
+}
+
+func (c *chanProcessor) GetBufferSize() int {
+	return len(c.data)
+}